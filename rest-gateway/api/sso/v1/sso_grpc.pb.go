@@ -19,12 +19,21 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	SSO_Register_FullMethodName    = "/sso.v1.SSO/Register"
-	SSO_Login_FullMethodName       = "/sso.v1.SSO/Login"
-	SSO_Refresh_FullMethodName     = "/sso.v1.SSO/Refresh"
-	SSO_UpdateUser_FullMethodName  = "/sso.v1.SSO/UpdateUser"
-	SSO_GetUserByID_FullMethodName = "/sso.v1.SSO/GetUserByID"
-	SSO_GetSelf_FullMethodName     = "/sso.v1.SSO/GetSelf"
+	SSO_Register_FullMethodName             = "/sso.v1.SSO/Register"
+	SSO_Login_FullMethodName                = "/sso.v1.SSO/Login"
+	SSO_Refresh_FullMethodName              = "/sso.v1.SSO/Refresh"
+	SSO_Logout_FullMethodName               = "/sso.v1.SSO/Logout"
+	SSO_LogoutAll_FullMethodName            = "/sso.v1.SSO/LogoutAll"
+	SSO_ListSessions_FullMethodName         = "/sso.v1.SSO/ListSessions"
+	SSO_RevokeSession_FullMethodName        = "/sso.v1.SSO/RevokeSession"
+	SSO_SendVerification_FullMethodName     = "/sso.v1.SSO/SendVerification"
+	SSO_VerifyEmail_FullMethodName          = "/sso.v1.SSO/VerifyEmail"
+	SSO_RequestPasswordReset_FullMethodName = "/sso.v1.SSO/RequestPasswordReset"
+	SSO_ResetPassword_FullMethodName        = "/sso.v1.SSO/ResetPassword"
+	SSO_UpdateUser_FullMethodName           = "/sso.v1.SSO/UpdateUser"
+	SSO_GetUserByID_FullMethodName          = "/sso.v1.SSO/GetUserByID"
+	SSO_GetSelf_FullMethodName              = "/sso.v1.SSO/GetSelf"
+	SSO_GetJWKS_FullMethodName              = "/sso.v1.SSO/GetJWKS"
 )
 
 // SSOClient is the client API for SSO service.
@@ -37,6 +46,25 @@ type SSOClient interface {
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*Token, error)
 	// Refresh the pair of tokens
 	Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*Token, error)
+	// Revoke the session of the refresh token
+	Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Revoke every session of the user
+	// REQUIRES: jwt-token
+	LogoutAll(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// Active sessions of the user, the recently used first
+	// REQUIRES: jwt-token
+	ListSessions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SessionList, error)
+	// Revoke the session of the user by its id
+	// REQUIRES: jwt-token
+	RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Send a token to verify the email, if there is such unverified user
+	SendVerification(ctx context.Context, in *SendVerificationRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Verify the email by the token sent to it
+	VerifyEmail(ctx context.Context, in *VerifyEmailRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Send a token to reset the password, if there is such user
+	RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Set the new password by the token sent to the email, every session is revoked
+	ResetPassword(ctx context.Context, in *ResetPasswordRequest, opts ...grpc.CallOption) (*Empty, error)
 	// Update data of user
 	// REQUIRES: jwt-token
 	UpdateUser(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*Empty, error)
@@ -45,6 +73,8 @@ type SSOClient interface {
 	// Get self user
 	// REQUIRES: jwt-token
 	GetSelf(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*User, error)
+	// Public keys to validate the access tokens by their kid
+	GetJWKS(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*JWKS, error)
 }
 
 type sSOClient struct {
@@ -85,6 +115,86 @@ func (c *sSOClient) Refresh(ctx context.Context, in *RefreshRequest, opts ...grp
 	return out, nil
 }
 
+func (c *sSOClient) Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SSO_Logout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sSOClient) LogoutAll(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SSO_LogoutAll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sSOClient) ListSessions(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SessionList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionList)
+	err := c.cc.Invoke(ctx, SSO_ListSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sSOClient) RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SSO_RevokeSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sSOClient) SendVerification(ctx context.Context, in *SendVerificationRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SSO_SendVerification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sSOClient) VerifyEmail(ctx context.Context, in *VerifyEmailRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SSO_VerifyEmail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sSOClient) RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SSO_RequestPasswordReset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sSOClient) ResetPassword(ctx context.Context, in *ResetPasswordRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SSO_ResetPassword_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *sSOClient) UpdateUser(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Empty)
@@ -115,6 +225,16 @@ func (c *sSOClient) GetSelf(ctx context.Context, in *Empty, opts ...grpc.CallOpt
 	return out, nil
 }
 
+func (c *sSOClient) GetJWKS(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*JWKS, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JWKS)
+	err := c.cc.Invoke(ctx, SSO_GetJWKS_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SSOServer is the server API for SSO service.
 // All implementations must embed UnimplementedSSOServer
 // for forward compatibility.
@@ -125,6 +245,25 @@ type SSOServer interface {
 	Login(context.Context, *LoginRequest) (*Token, error)
 	// Refresh the pair of tokens
 	Refresh(context.Context, *RefreshRequest) (*Token, error)
+	// Revoke the session of the refresh token
+	Logout(context.Context, *LogoutRequest) (*Empty, error)
+	// Revoke every session of the user
+	// REQUIRES: jwt-token
+	LogoutAll(context.Context, *Empty) (*Empty, error)
+	// Active sessions of the user, the recently used first
+	// REQUIRES: jwt-token
+	ListSessions(context.Context, *Empty) (*SessionList, error)
+	// Revoke the session of the user by its id
+	// REQUIRES: jwt-token
+	RevokeSession(context.Context, *RevokeSessionRequest) (*Empty, error)
+	// Send a token to verify the email, if there is such unverified user
+	SendVerification(context.Context, *SendVerificationRequest) (*Empty, error)
+	// Verify the email by the token sent to it
+	VerifyEmail(context.Context, *VerifyEmailRequest) (*Empty, error)
+	// Send a token to reset the password, if there is such user
+	RequestPasswordReset(context.Context, *RequestPasswordResetRequest) (*Empty, error)
+	// Set the new password by the token sent to the email, every session is revoked
+	ResetPassword(context.Context, *ResetPasswordRequest) (*Empty, error)
 	// Update data of user
 	// REQUIRES: jwt-token
 	UpdateUser(context.Context, *UpdateRequest) (*Empty, error)
@@ -133,6 +272,8 @@ type SSOServer interface {
 	// Get self user
 	// REQUIRES: jwt-token
 	GetSelf(context.Context, *Empty) (*User, error)
+	// Public keys to validate the access tokens by their kid
+	GetJWKS(context.Context, *Empty) (*JWKS, error)
 	mustEmbedUnimplementedSSOServer()
 }
 
@@ -152,6 +293,30 @@ func (UnimplementedSSOServer) Login(context.Context, *LoginRequest) (*Token, err
 func (UnimplementedSSOServer) Refresh(context.Context, *RefreshRequest) (*Token, error) {
 	return nil, status.Error(codes.Unimplemented, "method Refresh not implemented")
 }
+func (UnimplementedSSOServer) Logout(context.Context, *LogoutRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method Logout not implemented")
+}
+func (UnimplementedSSOServer) LogoutAll(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method LogoutAll not implemented")
+}
+func (UnimplementedSSOServer) ListSessions(context.Context, *Empty) (*SessionList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedSSOServer) RevokeSession(context.Context, *RevokeSessionRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedSSOServer) SendVerification(context.Context, *SendVerificationRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method SendVerification not implemented")
+}
+func (UnimplementedSSOServer) VerifyEmail(context.Context, *VerifyEmailRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method VerifyEmail not implemented")
+}
+func (UnimplementedSSOServer) RequestPasswordReset(context.Context, *RequestPasswordResetRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method RequestPasswordReset not implemented")
+}
+func (UnimplementedSSOServer) ResetPassword(context.Context, *ResetPasswordRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResetPassword not implemented")
+}
 func (UnimplementedSSOServer) UpdateUser(context.Context, *UpdateRequest) (*Empty, error) {
 	return nil, status.Error(codes.Unimplemented, "method UpdateUser not implemented")
 }
@@ -161,6 +326,9 @@ func (UnimplementedSSOServer) GetUserByID(context.Context, *GetByIDRequest) (*Us
 func (UnimplementedSSOServer) GetSelf(context.Context, *Empty) (*User, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetSelf not implemented")
 }
+func (UnimplementedSSOServer) GetJWKS(context.Context, *Empty) (*JWKS, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetJWKS not implemented")
+}
 func (UnimplementedSSOServer) mustEmbedUnimplementedSSOServer() {}
 func (UnimplementedSSOServer) testEmbeddedByValue()             {}
 
@@ -236,6 +404,150 @@ func _SSO_Refresh_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SSO_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SSOServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SSO_Logout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SSOServer).Logout(ctx, req.(*LogoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SSO_LogoutAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SSOServer).LogoutAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SSO_LogoutAll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SSOServer).LogoutAll(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SSO_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SSOServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SSO_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SSOServer).ListSessions(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SSO_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SSOServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SSO_RevokeSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SSOServer).RevokeSession(ctx, req.(*RevokeSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SSO_SendVerification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendVerificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SSOServer).SendVerification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SSO_SendVerification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SSOServer).SendVerification(ctx, req.(*SendVerificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SSO_VerifyEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SSOServer).VerifyEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SSO_VerifyEmail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SSOServer).VerifyEmail(ctx, req.(*VerifyEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SSO_RequestPasswordReset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestPasswordResetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SSOServer).RequestPasswordReset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SSO_RequestPasswordReset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SSOServer).RequestPasswordReset(ctx, req.(*RequestPasswordResetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SSO_ResetPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SSOServer).ResetPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SSO_ResetPassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SSOServer).ResetPassword(ctx, req.(*ResetPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SSO_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateRequest)
 	if err := dec(in); err != nil {
@@ -290,6 +602,24 @@ func _SSO_GetSelf_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SSO_GetJWKS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SSOServer).GetJWKS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SSO_GetJWKS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SSOServer).GetJWKS(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SSO_ServiceDesc is the grpc.ServiceDesc for SSO service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -309,6 +639,38 @@ var SSO_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Refresh",
 			Handler:    _SSO_Refresh_Handler,
 		},
+		{
+			MethodName: "Logout",
+			Handler:    _SSO_Logout_Handler,
+		},
+		{
+			MethodName: "LogoutAll",
+			Handler:    _SSO_LogoutAll_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _SSO_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _SSO_RevokeSession_Handler,
+		},
+		{
+			MethodName: "SendVerification",
+			Handler:    _SSO_SendVerification_Handler,
+		},
+		{
+			MethodName: "VerifyEmail",
+			Handler:    _SSO_VerifyEmail_Handler,
+		},
+		{
+			MethodName: "RequestPasswordReset",
+			Handler:    _SSO_RequestPasswordReset_Handler,
+		},
+		{
+			MethodName: "ResetPassword",
+			Handler:    _SSO_ResetPassword_Handler,
+		},
 		{
 			MethodName: "UpdateUser",
 			Handler:    _SSO_UpdateUser_Handler,
@@ -321,6 +683,10 @@ var SSO_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetSelf",
 			Handler:    _SSO_GetSelf_Handler,
 		},
+		{
+			MethodName: "GetJWKS",
+			Handler:    _SSO_GetJWKS_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "sso/v1/sso.proto",
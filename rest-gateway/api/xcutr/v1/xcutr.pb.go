@@ -0,0 +1,1332 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.2
+// source: xcutr/v1/xcutr.proto
+
+package xcutrpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Log struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Msg   string                 `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
+	// Set only in the first message of the stream
+	ExecutionId string `protobuf:"bytes,2,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	// Number of the line in the output, starting from 1
+	Seq uint64 `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
+	// When the container wrote the line
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Set only in the first message of the stream,
+	// lets other users watch the execution
+	ShareToken    string `protobuf:"bytes,5,opt,name=share_token,json=shareToken,proto3" json:"share_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Log) Reset() {
+	*x = Log{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Log) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Log) ProtoMessage() {}
+
+func (x *Log) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Log.ProtoReflect.Descriptor instead.
+func (*Log) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Log) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *Log) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *Log) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Log) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Log) GetShareToken() string {
+	if x != nil {
+		return x.ShareToken
+	}
+	return ""
+}
+
+type File struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mime          string                 `protobuf:"bytes,1,opt,name=mime,proto3" json:"mime,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Body          []byte                 `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *File) Reset() {
+	*x = File{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *File) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*File) ProtoMessage() {}
+
+func (x *File) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use File.ProtoReflect.Descriptor instead.
+func (*File) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *File) GetMime() string {
+	if x != nil {
+		return x.Mime
+	}
+	return ""
+}
+
+func (x *File) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *File) GetBody() []byte {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+type ExecutionRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Language   string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`
+	Files      []*File                `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
+	MaxTimeout int64                  `protobuf:"varint,3,opt,name=max_timeout,json=maxTimeout,proto3" json:"max_timeout,omitempty"`
+	// Appended to the run command of the language
+	Args []string `protobuf:"bytes,4,rep,name=args,proto3" json:"args,omitempty"`
+	// Environment variables of the program, reserved names are rejected
+	Env           map[string]string `protobuf:"bytes,5,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecutionRequest) Reset() {
+	*x = ExecutionRequest{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecutionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutionRequest) ProtoMessage() {}
+
+func (x *ExecutionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutionRequest.ProtoReflect.Descriptor instead.
+func (*ExecutionRequest) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ExecutionRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ExecutionRequest) GetFiles() []*File {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ExecutionRequest) GetMaxTimeout() int64 {
+	if x != nil {
+		return x.MaxTimeout
+	}
+	return 0
+}
+
+func (x *ExecutionRequest) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ExecutionRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+type CancelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId   string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelRequest) Reset() {
+	*x = CancelRequest{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelRequest) ProtoMessage() {}
+
+func (x *CancelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelRequest.ProtoReflect.Descriptor instead.
+func (*CancelRequest) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CancelRequest) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+type AttachRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId   string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	FromSeq       uint64                 `protobuf:"varint,2,opt,name=from_seq,json=fromSeq,proto3" json:"from_seq,omitempty"`
+	ShareToken    string                 `protobuf:"bytes,3,opt,name=share_token,json=shareToken,proto3" json:"share_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachRequest) Reset() {
+	*x = AttachRequest{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachRequest) ProtoMessage() {}
+
+func (x *AttachRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachRequest.ProtoReflect.Descriptor instead.
+func (*AttachRequest) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AttachRequest) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *AttachRequest) GetFromSeq() uint64 {
+	if x != nil {
+		return x.FromSeq
+	}
+	return 0
+}
+
+func (x *AttachRequest) GetShareToken() string {
+	if x != nil {
+		return x.ShareToken
+	}
+	return ""
+}
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{5}
+}
+
+type SourceFile struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Mime  string                 `protobuf:"bytes,2,opt,name=mime,proto3" json:"mime,omitempty"`
+	// Hex of the sha256 of the body
+	Sha256 string `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	// Empty if the source was too large to keep
+	Body          []byte `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SourceFile) Reset() {
+	*x = SourceFile{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SourceFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SourceFile) ProtoMessage() {}
+
+func (x *SourceFile) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SourceFile.ProtoReflect.Descriptor instead.
+func (*SourceFile) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SourceFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SourceFile) GetMime() string {
+	if x != nil {
+		return x.Mime
+	}
+	return ""
+}
+
+func (x *SourceFile) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *SourceFile) GetBody() []byte {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+type ExecutionRecord struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	Language    string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	// succeeded, failed, timeout, oom, canceled, abandoned, killed or error
+	Status string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	// -1 if the program hasn't exited by itself
+	ExitCode   int32                  `protobuf:"varint,4,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	StartedAt  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	DurationMs int64                  `protobuf:"varint,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	// Set only by GetExecution
+	Files           []*SourceFile `protobuf:"bytes,7,rep,name=files,proto3" json:"files,omitempty"`
+	SourceTruncated bool          `protobuf:"varint,8,opt,name=source_truncated,json=sourceTruncated,proto3" json:"source_truncated,omitempty"`
+	// Set only by GetExecution
+	Output          string `protobuf:"bytes,9,opt,name=output,proto3" json:"output,omitempty"`
+	OutputTruncated bool   `protobuf:"varint,10,opt,name=output_truncated,json=outputTruncated,proto3" json:"output_truncated,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ExecutionRecord) Reset() {
+	*x = ExecutionRecord{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecutionRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutionRecord) ProtoMessage() {}
+
+func (x *ExecutionRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutionRecord.ProtoReflect.Descriptor instead.
+func (*ExecutionRecord) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExecutionRecord) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *ExecutionRecord) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ExecutionRecord) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ExecutionRecord) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ExecutionRecord) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *ExecutionRecord) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *ExecutionRecord) GetFiles() []*SourceFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ExecutionRecord) GetSourceTruncated() bool {
+	if x != nil {
+		return x.SourceTruncated
+	}
+	return false
+}
+
+func (x *ExecutionRecord) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *ExecutionRecord) GetOutputTruncated() bool {
+	if x != nil {
+		return x.OutputTruncated
+	}
+	return false
+}
+
+type ListExecutionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filters, empty ones match everything
+	Language string `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`
+	Status   string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// next_cursor of the previous page, empty for the first one
+	Cursor        string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExecutionsRequest) Reset() {
+	*x = ListExecutionsRequest{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExecutionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExecutionsRequest) ProtoMessage() {}
+
+func (x *ListExecutionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExecutionsRequest.ProtoReflect.Descriptor instead.
+func (*ListExecutionsRequest) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListExecutionsRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ListExecutionsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListExecutionsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListExecutionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListExecutionsResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Executions []*ExecutionRecord     `protobuf:"bytes,1,rep,name=executions,proto3" json:"executions,omitempty"`
+	// Empty on the last page
+	NextCursor    string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExecutionsResponse) Reset() {
+	*x = ListExecutionsResponse{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExecutionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExecutionsResponse) ProtoMessage() {}
+
+func (x *ListExecutionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExecutionsResponse.ProtoReflect.Descriptor instead.
+func (*ListExecutionsResponse) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListExecutionsResponse) GetExecutions() []*ExecutionRecord {
+	if x != nil {
+		return x.Executions
+	}
+	return nil
+}
+
+func (x *ListExecutionsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type GetExecutionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId   string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetExecutionRequest) Reset() {
+	*x = GetExecutionRequest{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetExecutionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetExecutionRequest) ProtoMessage() {}
+
+func (x *GetExecutionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetExecutionRequest.ProtoReflect.Descriptor instead.
+func (*GetExecutionRequest) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetExecutionRequest) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+type UsageStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Last 7 days by default
+	From *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// Empty matches every language
+	Language string `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	// 10 by default
+	TopUsers      int32 `protobuf:"varint,4,opt,name=top_users,json=topUsers,proto3" json:"top_users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageStatsRequest) Reset() {
+	*x = UsageStatsRequest{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageStatsRequest) ProtoMessage() {}
+
+func (x *UsageStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageStatsRequest.ProtoReflect.Descriptor instead.
+func (*UsageStatsRequest) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UsageStatsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *UsageStatsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *UsageStatsRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *UsageStatsRequest) GetTopUsers() int32 {
+	if x != nil {
+		return x.TopUsers
+	}
+	return 0
+}
+
+type DailyRuns struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// YYYY-MM-DD in UTC
+	Day           string `protobuf:"bytes,1,opt,name=day,proto3" json:"day,omitempty"`
+	Language      string `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	Runs          uint64 `protobuf:"varint,3,opt,name=runs,proto3" json:"runs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DailyRuns) Reset() {
+	*x = DailyRuns{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DailyRuns) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DailyRuns) ProtoMessage() {}
+
+func (x *DailyRuns) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DailyRuns.ProtoReflect.Descriptor instead.
+func (*DailyRuns) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DailyRuns) GetDay() string {
+	if x != nil {
+		return x.Day
+	}
+	return ""
+}
+
+func (x *DailyRuns) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *DailyRuns) GetRuns() uint64 {
+	if x != nil {
+		return x.Runs
+	}
+	return 0
+}
+
+type LanguageStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Language      string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`
+	Runs          uint64                 `protobuf:"varint,2,opt,name=runs,proto3" json:"runs,omitempty"`
+	Failures      uint64                 `protobuf:"varint,3,opt,name=failures,proto3" json:"failures,omitempty"`
+	FailureRate   float64                `protobuf:"fixed64,4,opt,name=failure_rate,json=failureRate,proto3" json:"failure_rate,omitempty"`
+	Timeouts      uint64                 `protobuf:"varint,5,opt,name=timeouts,proto3" json:"timeouts,omitempty"`
+	OomKills      uint64                 `protobuf:"varint,6,opt,name=oom_kills,json=oomKills,proto3" json:"oom_kills,omitempty"`
+	P50DurationMs int64                  `protobuf:"varint,7,opt,name=p50_duration_ms,json=p50DurationMs,proto3" json:"p50_duration_ms,omitempty"`
+	P95DurationMs int64                  `protobuf:"varint,8,opt,name=p95_duration_ms,json=p95DurationMs,proto3" json:"p95_duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LanguageStats) Reset() {
+	*x = LanguageStats{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LanguageStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LanguageStats) ProtoMessage() {}
+
+func (x *LanguageStats) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LanguageStats.ProtoReflect.Descriptor instead.
+func (*LanguageStats) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *LanguageStats) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *LanguageStats) GetRuns() uint64 {
+	if x != nil {
+		return x.Runs
+	}
+	return 0
+}
+
+func (x *LanguageStats) GetFailures() uint64 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+func (x *LanguageStats) GetFailureRate() float64 {
+	if x != nil {
+		return x.FailureRate
+	}
+	return 0
+}
+
+func (x *LanguageStats) GetTimeouts() uint64 {
+	if x != nil {
+		return x.Timeouts
+	}
+	return 0
+}
+
+func (x *LanguageStats) GetOomKills() uint64 {
+	if x != nil {
+		return x.OomKills
+	}
+	return 0
+}
+
+func (x *LanguageStats) GetP50DurationMs() int64 {
+	if x != nil {
+		return x.P50DurationMs
+	}
+	return 0
+}
+
+func (x *LanguageStats) GetP95DurationMs() int64 {
+	if x != nil {
+		return x.P95DurationMs
+	}
+	return 0
+}
+
+type UserUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Runs          uint64                 `protobuf:"varint,2,opt,name=runs,proto3" json:"runs,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserUsage) Reset() {
+	*x = UserUsage{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserUsage) ProtoMessage() {}
+
+func (x *UserUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserUsage.ProtoReflect.Descriptor instead.
+func (*UserUsage) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UserUsage) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UserUsage) GetRuns() uint64 {
+	if x != nil {
+		return x.Runs
+	}
+	return 0
+}
+
+func (x *UserUsage) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type UsageStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Daily         []*DailyRuns           `protobuf:"bytes,1,rep,name=daily,proto3" json:"daily,omitempty"`
+	Languages     []*LanguageStats       `protobuf:"bytes,2,rep,name=languages,proto3" json:"languages,omitempty"`
+	TopUsers      []*UserUsage           `protobuf:"bytes,3,rep,name=top_users,json=topUsers,proto3" json:"top_users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageStats) Reset() {
+	*x = UsageStats{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageStats) ProtoMessage() {}
+
+func (x *UsageStats) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageStats.ProtoReflect.Descriptor instead.
+func (*UsageStats) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UsageStats) GetDaily() []*DailyRuns {
+	if x != nil {
+		return x.Daily
+	}
+	return nil
+}
+
+func (x *UsageStats) GetLanguages() []*LanguageStats {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+func (x *UsageStats) GetTopUsers() []*UserUsage {
+	if x != nil {
+		return x.TopUsers
+	}
+	return nil
+}
+
+type EraseUserDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EraseUserDataRequest) Reset() {
+	*x = EraseUserDataRequest{}
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserDataRequest) ProtoMessage() {}
+
+func (x *EraseUserDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xcutr_v1_xcutr_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserDataRequest.ProtoReflect.Descriptor instead.
+func (*EraseUserDataRequest) Descriptor() ([]byte, []int) {
+	return file_xcutr_v1_xcutr_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *EraseUserDataRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+var File_xcutr_v1_xcutr_proto protoreflect.FileDescriptor
+
+const file_xcutr_v1_xcutr_proto_rawDesc = "" +
+	"\n" +
+	"\x14xcutr/v1/xcutr.proto\x12\bxcutr.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa7\x01\n" +
+	"\x03Log\x12\x10\n" +
+	"\x03msg\x18\x01 \x01(\tR\x03msg\x12!\n" +
+	"\fexecution_id\x18\x02 \x01(\tR\vexecutionId\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\x04R\x03seq\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1f\n" +
+	"\vshare_token\x18\x05 \x01(\tR\n" +
+	"shareToken\"B\n" +
+	"\x04File\x12\x12\n" +
+	"\x04mime\x18\x01 \x01(\tR\x04mime\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\fR\x04body\"\xf8\x01\n" +
+	"\x10ExecutionRequest\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12$\n" +
+	"\x05files\x18\x02 \x03(\v2\x0e.xcutr.v1.FileR\x05files\x12\x1f\n" +
+	"\vmax_timeout\x18\x03 \x01(\x03R\n" +
+	"maxTimeout\x12\x12\n" +
+	"\x04args\x18\x04 \x03(\tR\x04args\x125\n" +
+	"\x03env\x18\x05 \x03(\v2#.xcutr.v1.ExecutionRequest.EnvEntryR\x03env\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"2\n" +
+	"\rCancelRequest\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\"n\n" +
+	"\rAttachRequest\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\x12\x19\n" +
+	"\bfrom_seq\x18\x02 \x01(\x04R\afromSeq\x12\x1f\n" +
+	"\vshare_token\x18\x03 \x01(\tR\n" +
+	"shareToken\"\a\n" +
+	"\x05Empty\"`\n" +
+	"\n" +
+	"SourceFile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04mime\x18\x02 \x01(\tR\x04mime\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\fR\x04body\"\xfb\x02\n" +
+	"\x0fExecutionRecord\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1b\n" +
+	"\texit_code\x18\x04 \x01(\x05R\bexitCode\x129\n" +
+	"\n" +
+	"started_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x03R\n" +
+	"durationMs\x12*\n" +
+	"\x05files\x18\a \x03(\v2\x14.xcutr.v1.SourceFileR\x05files\x12)\n" +
+	"\x10source_truncated\x18\b \x01(\bR\x0fsourceTruncated\x12\x16\n" +
+	"\x06output\x18\t \x01(\tR\x06output\x12)\n" +
+	"\x10output_truncated\x18\n" +
+	" \x01(\bR\x0foutputTruncated\"y\n" +
+	"\x15ListExecutionsRequest\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"t\n" +
+	"\x16ListExecutionsResponse\x129\n" +
+	"\n" +
+	"executions\x18\x01 \x03(\v2\x19.xcutr.v1.ExecutionRecordR\n" +
+	"executions\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"8\n" +
+	"\x13GetExecutionRequest\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\"\xa8\x01\n" +
+	"\x11UsageStatsRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\x12\x1b\n" +
+	"\ttop_users\x18\x04 \x01(\x05R\btopUsers\"M\n" +
+	"\tDailyRuns\x12\x10\n" +
+	"\x03day\x18\x01 \x01(\tR\x03day\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x12\n" +
+	"\x04runs\x18\x03 \x01(\x04R\x04runs\"\x87\x02\n" +
+	"\rLanguageStats\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12\x12\n" +
+	"\x04runs\x18\x02 \x01(\x04R\x04runs\x12\x1a\n" +
+	"\bfailures\x18\x03 \x01(\x04R\bfailures\x12!\n" +
+	"\ffailure_rate\x18\x04 \x01(\x01R\vfailureRate\x12\x1a\n" +
+	"\btimeouts\x18\x05 \x01(\x04R\btimeouts\x12\x1b\n" +
+	"\toom_kills\x18\x06 \x01(\x04R\boomKills\x12&\n" +
+	"\x0fp50_duration_ms\x18\a \x01(\x03R\rp50DurationMs\x12&\n" +
+	"\x0fp95_duration_ms\x18\b \x01(\x03R\rp95DurationMs\"Y\n" +
+	"\tUserUsage\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04runs\x18\x02 \x01(\x04R\x04runs\x12\x1f\n" +
+	"\vduration_ms\x18\x03 \x01(\x03R\n" +
+	"durationMs\"\xa0\x01\n" +
+	"\n" +
+	"UsageStats\x12)\n" +
+	"\x05daily\x18\x01 \x03(\v2\x13.xcutr.v1.DailyRunsR\x05daily\x125\n" +
+	"\tlanguages\x18\x02 \x03(\v2\x17.xcutr.v1.LanguageStatsR\tlanguages\x120\n" +
+	"\ttop_users\x18\x03 \x03(\v2\x13.xcutr.v1.UserUsageR\btopUsers\"/\n" +
+	"\x14EraseUserDataRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId2\xde\x03\n" +
+	"\x05Xcutr\x126\n" +
+	"\aExecute\x12\x1a.xcutr.v1.ExecutionRequest\x1a\r.xcutr.v1.Log0\x01\x12;\n" +
+	"\x0fCancelExecution\x12\x17.xcutr.v1.CancelRequest\x1a\x0f.xcutr.v1.Empty\x12;\n" +
+	"\x0fAttachExecution\x12\x17.xcutr.v1.AttachRequest\x1a\r.xcutr.v1.Log0\x01\x12S\n" +
+	"\x0eListExecutions\x12\x1f.xcutr.v1.ListExecutionsRequest\x1a .xcutr.v1.ListExecutionsResponse\x12H\n" +
+	"\fGetExecution\x12\x1d.xcutr.v1.GetExecutionRequest\x1a\x19.xcutr.v1.ExecutionRecord\x12B\n" +
+	"\rGetUsageStats\x12\x1b.xcutr.v1.UsageStatsRequest\x1a\x14.xcutr.v1.UsageStats\x12@\n" +
+	"\rEraseUserData\x12\x1e.xcutr.v1.EraseUserDataRequest\x1a\x0f.xcutr.v1.EmptyB3Z1github.com/devathh/coderun/xcutr-service; xcutrpbb\x06proto3"
+
+var (
+	file_xcutr_v1_xcutr_proto_rawDescOnce sync.Once
+	file_xcutr_v1_xcutr_proto_rawDescData []byte
+)
+
+func file_xcutr_v1_xcutr_proto_rawDescGZIP() []byte {
+	file_xcutr_v1_xcutr_proto_rawDescOnce.Do(func() {
+		file_xcutr_v1_xcutr_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_xcutr_v1_xcutr_proto_rawDesc), len(file_xcutr_v1_xcutr_proto_rawDesc)))
+	})
+	return file_xcutr_v1_xcutr_proto_rawDescData
+}
+
+var file_xcutr_v1_xcutr_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_xcutr_v1_xcutr_proto_goTypes = []any{
+	(*Log)(nil),                    // 0: xcutr.v1.Log
+	(*File)(nil),                   // 1: xcutr.v1.File
+	(*ExecutionRequest)(nil),       // 2: xcutr.v1.ExecutionRequest
+	(*CancelRequest)(nil),          // 3: xcutr.v1.CancelRequest
+	(*AttachRequest)(nil),          // 4: xcutr.v1.AttachRequest
+	(*Empty)(nil),                  // 5: xcutr.v1.Empty
+	(*SourceFile)(nil),             // 6: xcutr.v1.SourceFile
+	(*ExecutionRecord)(nil),        // 7: xcutr.v1.ExecutionRecord
+	(*ListExecutionsRequest)(nil),  // 8: xcutr.v1.ListExecutionsRequest
+	(*ListExecutionsResponse)(nil), // 9: xcutr.v1.ListExecutionsResponse
+	(*GetExecutionRequest)(nil),    // 10: xcutr.v1.GetExecutionRequest
+	(*UsageStatsRequest)(nil),      // 11: xcutr.v1.UsageStatsRequest
+	(*DailyRuns)(nil),              // 12: xcutr.v1.DailyRuns
+	(*LanguageStats)(nil),          // 13: xcutr.v1.LanguageStats
+	(*UserUsage)(nil),              // 14: xcutr.v1.UserUsage
+	(*UsageStats)(nil),             // 15: xcutr.v1.UsageStats
+	(*EraseUserDataRequest)(nil),   // 16: xcutr.v1.EraseUserDataRequest
+	nil,                            // 17: xcutr.v1.ExecutionRequest.EnvEntry
+	(*timestamppb.Timestamp)(nil),  // 18: google.protobuf.Timestamp
+}
+var file_xcutr_v1_xcutr_proto_depIdxs = []int32{
+	18, // 0: xcutr.v1.Log.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 1: xcutr.v1.ExecutionRequest.files:type_name -> xcutr.v1.File
+	17, // 2: xcutr.v1.ExecutionRequest.env:type_name -> xcutr.v1.ExecutionRequest.EnvEntry
+	18, // 3: xcutr.v1.ExecutionRecord.started_at:type_name -> google.protobuf.Timestamp
+	6,  // 4: xcutr.v1.ExecutionRecord.files:type_name -> xcutr.v1.SourceFile
+	7,  // 5: xcutr.v1.ListExecutionsResponse.executions:type_name -> xcutr.v1.ExecutionRecord
+	18, // 6: xcutr.v1.UsageStatsRequest.from:type_name -> google.protobuf.Timestamp
+	18, // 7: xcutr.v1.UsageStatsRequest.to:type_name -> google.protobuf.Timestamp
+	12, // 8: xcutr.v1.UsageStats.daily:type_name -> xcutr.v1.DailyRuns
+	13, // 9: xcutr.v1.UsageStats.languages:type_name -> xcutr.v1.LanguageStats
+	14, // 10: xcutr.v1.UsageStats.top_users:type_name -> xcutr.v1.UserUsage
+	2,  // 11: xcutr.v1.Xcutr.Execute:input_type -> xcutr.v1.ExecutionRequest
+	3,  // 12: xcutr.v1.Xcutr.CancelExecution:input_type -> xcutr.v1.CancelRequest
+	4,  // 13: xcutr.v1.Xcutr.AttachExecution:input_type -> xcutr.v1.AttachRequest
+	8,  // 14: xcutr.v1.Xcutr.ListExecutions:input_type -> xcutr.v1.ListExecutionsRequest
+	10, // 15: xcutr.v1.Xcutr.GetExecution:input_type -> xcutr.v1.GetExecutionRequest
+	11, // 16: xcutr.v1.Xcutr.GetUsageStats:input_type -> xcutr.v1.UsageStatsRequest
+	16, // 17: xcutr.v1.Xcutr.EraseUserData:input_type -> xcutr.v1.EraseUserDataRequest
+	0,  // 18: xcutr.v1.Xcutr.Execute:output_type -> xcutr.v1.Log
+	5,  // 19: xcutr.v1.Xcutr.CancelExecution:output_type -> xcutr.v1.Empty
+	0,  // 20: xcutr.v1.Xcutr.AttachExecution:output_type -> xcutr.v1.Log
+	9,  // 21: xcutr.v1.Xcutr.ListExecutions:output_type -> xcutr.v1.ListExecutionsResponse
+	7,  // 22: xcutr.v1.Xcutr.GetExecution:output_type -> xcutr.v1.ExecutionRecord
+	15, // 23: xcutr.v1.Xcutr.GetUsageStats:output_type -> xcutr.v1.UsageStats
+	5,  // 24: xcutr.v1.Xcutr.EraseUserData:output_type -> xcutr.v1.Empty
+	18, // [18:25] is the sub-list for method output_type
+	11, // [11:18] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_xcutr_v1_xcutr_proto_init() }
+func file_xcutr_v1_xcutr_proto_init() {
+	if File_xcutr_v1_xcutr_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_xcutr_v1_xcutr_proto_rawDesc), len(file_xcutr_v1_xcutr_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_xcutr_v1_xcutr_proto_goTypes,
+		DependencyIndexes: file_xcutr_v1_xcutr_proto_depIdxs,
+		MessageInfos:      file_xcutr_v1_xcutr_proto_msgTypes,
+	}.Build()
+	File_xcutr_v1_xcutr_proto = out.File
+	file_xcutr_v1_xcutr_proto_goTypes = nil
+	file_xcutr_v1_xcutr_proto_depIdxs = nil
+}
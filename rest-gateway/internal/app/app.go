@@ -4,20 +4,37 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
+	"time"
 
 	"github.com/devathh/coderun/rest-gateway/internal/application/services"
+	"github.com/devathh/coderun/rest-gateway/internal/domain/auth"
+	jwt "github.com/devathh/coderun/rest-gateway/internal/infrastructure/auth"
+	rediscache "github.com/devathh/coderun/rest-gateway/internal/infrastructure/cache/redis"
 	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/config"
+	healthclient "github.com/devathh/coderun/rest-gateway/internal/infrastructure/grpc/health-client"
 	ssoclient "github.com/devathh/coderun/rest-gateway/internal/infrastructure/grpc/sso-client"
+	xcutrclient "github.com/devathh/coderun/rest-gateway/internal/infrastructure/grpc/xcutr-client"
 	httpserver "github.com/devathh/coderun/rest-gateway/internal/infrastructure/http"
 	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/http/handlers"
+	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/metrics"
 	"github.com/devathh/coderun/rest-gateway/pkg/log"
+	sharedmetrics "github.com/devathh/coderun/shared/metrics"
+	"github.com/devathh/coderun/shared/ratelimit"
+	"github.com/devathh/coderun/shared/tracing"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
 )
 
+const tracingFlushTimeout = 5 * time.Second
+
 type App struct {
 	log    *slog.Logger
 	server *httpserver.Server
+	// Nil if the metrics are disabled
+	metrics *sharedmetrics.Server
 }
 
 func New() (*App, func(), error) {
@@ -38,6 +55,20 @@ func New() (*App, func(), error) {
 
 	log.Info("config was loaded", slog.Any("server", cfg.Server), slog.Any("app", cfg.App))
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Enable:      cfg.Tracing.Enable,
+		Stdout:      cfg.Tracing.Exporter == config.ExporterStdout,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		Name:        cfg.App.Name,
+		Version:     cfg.App.Version,
+		Env:         cfg.App.Env,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to setup tracing: %w", err)
+	}
+
 	client, conn, err := ssoclient.Connect(cfg)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to connect to sso: %w", err)
@@ -48,30 +79,131 @@ func New() (*App, func(), error) {
 		return nil, nil, fmt.Errorf("failed to create sso-client: %w", err)
 	}
 
-	service := services.New(cfg, log, *ssoClient)
-	handler, err := handlers.New(cfg, service)
+	xcutrGRPC, xcutrConn, err := xcutrclient.Connect(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to xcutr: %w", err)
+	}
+
+	xcutrClient, err := xcutrclient.New(xcutrGRPC)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create xcutr-client: %w", err)
+	}
+
+	healthClient, err := healthclient.New(map[string]*grpc.ClientConn{
+		"sso":   conn,
+		"xcutr": xcutrConn,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create health-client: %w", err)
+	}
+
+	service := services.New(cfg, log, *ssoClient, *xcutrClient, *healthClient)
+
+	// Redis is needed for the rate limits only
+	var redisClient *redis.Client
+	var rateLimiter *handlers.RateLimiter
+	if cfg.RateLimit.Enable {
+		redisClient, err = rediscache.Connect(cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect redis: %w", err)
+		}
+
+		limiter, err := ratelimit.New(redisClient, rateLimitRules(cfg))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create rate limiter: %w", err)
+		}
+
+		// Without the user rules the tokens aren't checked
+		var jwtManager auth.JWTManager
+		if cfg.RateLimit.ByUser() {
+			jwtManager, err = jwt.New(cfg, ssoClient)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create jwt manager: %w", err)
+			}
+		}
+
+		rateLimiter = handlers.NewRateLimiter(log, limiter, jwtManager)
+	}
+
+	handler, err := handlers.New(cfg, service, rateLimiter)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create handler: %w", err)
 	}
 
 	server := httpserver.New(cfg, handler)
 
+	var metricsServer *sharedmetrics.Server
+	if cfg.Server.Metrics.Enable {
+		metricsServer = sharedmetrics.NewServer(
+			net.JoinHostPort(cfg.Server.Metrics.Host, cfg.Server.Metrics.Port),
+			metrics.Registry,
+		)
+	}
+
 	return &App{
-			log:    log,
-			server: server,
+			log:     log,
+			server:  server,
+			metrics: metricsServer,
 		}, func() {
 			if err := ssoclient.Close(conn); err != nil {
 				log.Error("failed to close sso client conn", slog.String("error", err.Error()))
 			}
+			if err := xcutrclient.Close(xcutrConn); err != nil {
+				log.Error("failed to close xcutr client conn", slog.String("error", err.Error()))
+			}
+			if redisClient != nil {
+				if err := rediscache.Close(redisClient); err != nil {
+					log.Error("failed to close redis connection", slog.String("error", err.Error()))
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+			defer cancel()
+
+			if err := shutdownTracing(ctx); err != nil {
+				log.Error("failed to flush spans", slog.String("error", err.Error()))
+			}
 		}, nil
 }
 
 func (a *App) Start() error {
+	if a.metrics != nil {
+		go func() {
+			a.log.Info("metrics server is running")
+			if err := a.metrics.Start(); err != nil {
+				a.log.Error("metrics server is stopped", slog.String("error", err.Error()))
+			}
+		}()
+	}
+
 	a.log.Info("server is running")
 	return a.server.Start()
 }
 
 func (a *App) Shutdown() error {
 	a.log.Info("server shutdown")
-	return a.server.Shutdown(context.Background())
+	if err := a.server.Shutdown(context.Background()); err != nil {
+		return err
+	}
+
+	if a.metrics != nil {
+		return a.metrics.Shutdown(context.Background())
+	}
+
+	return nil
+}
+
+func rateLimitRules(cfg *config.Config) []ratelimit.Rule {
+	rules := make([]ratelimit.Rule, 0, len(cfg.RateLimit.Rules))
+	for _, rule := range cfg.RateLimit.Rules {
+		rules = append(rules, ratelimit.Rule{
+			Name:   rule.Route,
+			Key:    rule.Key,
+			Rate:   rule.Rate,
+			Period: rule.Period,
+			Burst:  rule.Burst,
+		})
+	}
+
+	return rules
 }
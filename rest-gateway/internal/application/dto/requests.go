@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type RegisterRequest struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
@@ -15,6 +17,31 @@ type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type SendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type RequestPasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type RevokeSessionRequest struct {
+	SessionID string `json:"session_id"`
+}
+
 type UpdateRequest struct {
 	Username string `json:"username"`
 }
@@ -22,3 +49,25 @@ type UpdateRequest struct {
 type GetByIDRequest struct {
 	UserID string `json:"user_id"`
 }
+
+type ListExecutionsRequest struct {
+	Language string `form:"language"`
+	Status   string `form:"status"`
+	Cursor   string `form:"cursor"`
+	Limit    int32  `form:"limit"`
+}
+
+type GetExecutionRequest struct {
+	ExecutionID string `json:"execution_id"`
+}
+
+type UsageStatsRequest struct {
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Language string    `form:"language"`
+	TopUsers int32     `form:"top_users"`
+}
+
+type EraseUserDataRequest struct {
+	UserID string `json:"user_id"`
+}
@@ -1,12 +1,103 @@
 package dto
 
+import "time"
+
 type Token struct {
 	Access  string `json:"access"`
 	Refresh string `json:"refresh"`
 }
 
 type User struct {
-	ID       string `json:"id"`
-	Email    string `json:"email"`
-	Username string `json:"username"`
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	Username      string `json:"username"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+type Session struct {
+	ID         string    `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	Device     string    `json:"device"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+type Sessions struct {
+	Sessions []Session `json:"sessions"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type SourceFile struct {
+	Name   string `json:"name"`
+	Mime   string `json:"mime"`
+	SHA256 string `json:"sha256"`
+	Body   []byte `json:"body,omitempty"`
+}
+
+type Execution struct {
+	ID              string       `json:"id"`
+	Language        string       `json:"language"`
+	Status          string       `json:"status"`
+	ExitCode        int32        `json:"exit_code"`
+	StartedAt       time.Time    `json:"started_at"`
+	DurationMS      int64        `json:"duration_ms"`
+	Files           []SourceFile `json:"files,omitempty"`
+	SourceTruncated bool         `json:"source_truncated"`
+	Output          string       `json:"output,omitempty"`
+	OutputTruncated bool         `json:"output_truncated"`
+}
+
+type ExecutionsPage struct {
+	Executions []Execution `json:"executions"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+type DailyRuns struct {
+	Day      string `json:"day"`
+	Language string `json:"language"`
+	Runs     uint64 `json:"runs"`
+}
+
+type LanguageStats struct {
+	Language      string  `json:"language"`
+	Runs          uint64  `json:"runs"`
+	Failures      uint64  `json:"failures"`
+	FailureRate   float64 `json:"failure_rate"`
+	Timeouts      uint64  `json:"timeouts"`
+	OOMKills      uint64  `json:"oom_kills"`
+	P50DurationMS int64   `json:"p50_duration_ms"`
+	P95DurationMS int64   `json:"p95_duration_ms"`
+}
+
+type UserUsage struct {
+	UserID     string `json:"user_id"`
+	Runs       uint64 `json:"runs"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type UsageStats struct {
+	Daily     []DailyRuns     `json:"daily"`
+	Languages []LanguageStats `json:"languages"`
+	TopUsers  []UserUsage     `json:"top_users"`
+}
+
+type Readiness struct {
+	Status string `json:"status"`
+	// Statuses of the upstream services by their names
+	Services map[string]string `json:"services"`
 }
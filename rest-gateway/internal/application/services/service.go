@@ -5,36 +5,63 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	ssopb "github.com/devathh/coderun/rest-gateway/api/sso/v1"
+	xcutrpb "github.com/devathh/coderun/rest-gateway/api/xcutr/v1"
 	"github.com/devathh/coderun/rest-gateway/internal/application/dto"
 	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/config"
+	healthclient "github.com/devathh/coderun/rest-gateway/internal/infrastructure/grpc/health-client"
 	ssoclient "github.com/devathh/coderun/rest-gateway/internal/infrastructure/grpc/sso-client"
+	xcutrclient "github.com/devathh/coderun/rest-gateway/internal/infrastructure/grpc/xcutr-client"
 	customerrors "github.com/devathh/coderun/rest-gateway/pkg/errors"
 	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// Time the upstream services have to report their readiness
+const readinessTimeout = 2 * time.Second
+
 type restGatewayService struct {
-	cfg       *config.Config
-	log       *slog.Logger
-	ssoClient ssoclient.SSOClient
+	cfg          *config.Config
+	log          *slog.Logger
+	ssoClient    ssoclient.SSOClient
+	xcutrClient  xcutrclient.XcutrClient
+	healthClient healthclient.HealthClient
 }
 
 type RestGatewayService interface {
 	Register(context.Context, *dto.RegisterRequest) (*dto.Token, int, error)
 	Login(context.Context, *dto.LoginRequest) (*dto.Token, int, error)
 	Refresh(context.Context, *dto.RefreshRequest) (*dto.Token, int, error)
+	Logout(context.Context, *dto.LogoutRequest) (int, error)
+	LogoutAll(context.Context, string) (int, error)
+	SendVerification(context.Context, *dto.SendVerificationRequest) (int, error)
+	VerifyEmail(context.Context, *dto.VerifyEmailRequest) (int, error)
+	RequestPasswordReset(context.Context, *dto.RequestPasswordResetRequest) (int, error)
+	ResetPassword(context.Context, *dto.ResetPasswordRequest) (int, error)
+	ListSessions(context.Context, string) (*dto.Sessions, int, error)
+	RevokeSession(context.Context, *dto.RevokeSessionRequest, string) (int, error)
 	UpdateUser(context.Context, *dto.UpdateRequest, string) (int, error)
 	GetUserByID(context.Context, *dto.GetByIDRequest) (*dto.User, int, error)
 	GetSelf(context.Context, string) (*dto.User, int, error)
+	GetJWKS(context.Context) (*dto.JWKS, int, error)
+	ListExecutions(context.Context, *dto.ListExecutionsRequest, string) (*dto.ExecutionsPage, int, error)
+	GetExecution(context.Context, *dto.GetExecutionRequest, string) (*dto.Execution, int, error)
+	GetUsageStats(context.Context, *dto.UsageStatsRequest, string) (*dto.UsageStats, int, error)
+	EraseUserData(context.Context, *dto.EraseUserDataRequest, string) (int, error)
+	Readiness(context.Context) (*dto.Readiness, int, error)
 }
 
-func New(cfg *config.Config, log *slog.Logger, ssoClient ssoclient.SSOClient) RestGatewayService {
+func New(cfg *config.Config, log *slog.Logger, ssoClient ssoclient.SSOClient, xcutrClient xcutrclient.XcutrClient, healthClient healthclient.HealthClient) RestGatewayService {
 	return &restGatewayService{
-		cfg:       cfg,
-		log:       log,
-		ssoClient: ssoClient,
+		cfg:          cfg,
+		log:          log,
+		ssoClient:    ssoClient,
+		xcutrClient:  xcutrClient,
+		healthClient: healthClient,
 	}
 }
 
@@ -63,6 +90,10 @@ func (rgs *restGatewayService) Register(ctx context.Context, req *dto.RegisterRe
 			return nil, http.StatusConflict, errors.New(errStatus.Message())
 		}
 
+		if errStatus.Code() == codes.ResourceExhausted {
+			return nil, http.StatusTooManyRequests, errors.New(errStatus.Message())
+		}
+
 		rgs.log.Error("failed to do register request", slog.String("error", err.Error()))
 		return nil, http.StatusBadGateway, customerrors.ErrInternalServer
 	}
@@ -101,6 +132,14 @@ func (rgs *restGatewayService) Login(ctx context.Context, req *dto.LoginRequest)
 			return nil, http.StatusBadRequest, errors.New(errStatus.Message())
 		}
 
+		if errStatus.Code() == codes.ResourceExhausted {
+			return nil, http.StatusTooManyRequests, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.FailedPrecondition {
+			return nil, http.StatusForbidden, errors.New(errStatus.Message())
+		}
+
 		rgs.log.Error("failed to do login request", slog.String("error", err.Error()))
 		return nil, http.StatusBadGateway, customerrors.ErrInternalServer
 	}
@@ -140,6 +179,256 @@ func (rgs *restGatewayService) Refresh(ctx context.Context, req *dto.RefreshRequ
 	}, http.StatusOK, nil
 }
 
+func (rgs *restGatewayService) Logout(ctx context.Context, req *dto.LogoutRequest) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return http.StatusGatewayTimeout, err
+	}
+
+	if err := rgs.ssoClient.Logout(ctx, &ssopb.LogoutRequest{
+		RefreshToken: req.RefreshToken,
+	}); err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		if errStatus.Code() == codes.InvalidArgument {
+			return http.StatusBadRequest, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.Unauthenticated {
+			return http.StatusUnauthorized, errors.New(errStatus.Message())
+		}
+
+		rgs.log.Error("failed to do logout request", slog.String("error", err.Error()))
+		return http.StatusBadGateway, customerrors.ErrInternalServer
+	}
+
+	return http.StatusNoContent, nil
+}
+
+// SendVerification answers the same whether the user exists or not
+func (rgs *restGatewayService) SendVerification(ctx context.Context, req *dto.SendVerificationRequest) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return http.StatusGatewayTimeout, err
+	}
+
+	if err := rgs.ssoClient.SendVerification(ctx, &ssopb.SendVerificationRequest{
+		Email: req.Email,
+	}); err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		if errStatus.Code() == codes.InvalidArgument {
+			return http.StatusBadRequest, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.ResourceExhausted {
+			return http.StatusTooManyRequests, errors.New(errStatus.Message())
+		}
+
+		rgs.log.Error("failed to do send verification request", slog.String("error", err.Error()))
+		return http.StatusBadGateway, customerrors.ErrInternalServer
+	}
+
+	return http.StatusAccepted, nil
+}
+
+func (rgs *restGatewayService) VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return http.StatusGatewayTimeout, err
+	}
+
+	if err := rgs.ssoClient.VerifyEmail(ctx, &ssopb.VerifyEmailRequest{
+		Token: req.Token,
+	}); err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		if errStatus.Code() == codes.InvalidArgument {
+			return http.StatusBadRequest, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.ResourceExhausted {
+			return http.StatusTooManyRequests, errors.New(errStatus.Message())
+		}
+
+		rgs.log.Error("failed to do verify email request", slog.String("error", err.Error()))
+		return http.StatusBadGateway, customerrors.ErrInternalServer
+	}
+
+	return http.StatusNoContent, nil
+}
+
+// RequestPasswordReset answers the same whether the user exists or not
+func (rgs *restGatewayService) RequestPasswordReset(ctx context.Context, req *dto.RequestPasswordResetRequest) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return http.StatusGatewayTimeout, err
+	}
+
+	if err := rgs.ssoClient.RequestPasswordReset(ctx, &ssopb.RequestPasswordResetRequest{
+		Email: req.Email,
+	}); err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		if errStatus.Code() == codes.InvalidArgument {
+			return http.StatusBadRequest, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.ResourceExhausted {
+			return http.StatusTooManyRequests, errors.New(errStatus.Message())
+		}
+
+		rgs.log.Error("failed to do request password reset request", slog.String("error", err.Error()))
+		return http.StatusBadGateway, customerrors.ErrInternalServer
+	}
+
+	return http.StatusAccepted, nil
+}
+
+func (rgs *restGatewayService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return http.StatusGatewayTimeout, err
+	}
+
+	if err := rgs.ssoClient.ResetPassword(ctx, &ssopb.ResetPasswordRequest{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	}); err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		if errStatus.Code() == codes.InvalidArgument {
+			return http.StatusBadRequest, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.ResourceExhausted {
+			return http.StatusTooManyRequests, errors.New(errStatus.Message())
+		}
+
+		rgs.log.Error("failed to do reset password request", slog.String("error", err.Error()))
+		return http.StatusBadGateway, customerrors.ErrInternalServer
+	}
+
+	return http.StatusNoContent, nil
+}
+
+func (rgs *restGatewayService) LogoutAll(ctx context.Context, session string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return http.StatusGatewayTimeout, err
+	}
+
+	if err := rgs.ssoClient.LogoutAll(ctx, session); err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		if errStatus.Code() == codes.InvalidArgument {
+			return http.StatusBadRequest, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.Unauthenticated {
+			return http.StatusUnauthorized, errors.New(errStatus.Message())
+		}
+
+		rgs.log.Error("failed to do logout all request", slog.String("error", err.Error()))
+		return http.StatusBadGateway, customerrors.ErrInternalServer
+	}
+
+	return http.StatusNoContent, nil
+}
+
+func (rgs *restGatewayService) ListSessions(ctx context.Context, session string) (*dto.Sessions, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, http.StatusGatewayTimeout, err
+	}
+
+	resp, err := rgs.ssoClient.ListSessions(ctx, session)
+	if err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return nil, http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		if errStatus.Code() == codes.InvalidArgument {
+			return nil, http.StatusBadRequest, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.Unauthenticated {
+			return nil, http.StatusUnauthorized, errors.New(errStatus.Message())
+		}
+
+		rgs.log.Error("failed to do list sessions request", slog.String("error", err.Error()))
+		return nil, http.StatusBadGateway, customerrors.ErrInternalServer
+	}
+
+	sessions := &dto.Sessions{
+		Sessions: make([]dto.Session, 0, len(resp.Sessions)),
+	}
+	for _, s := range resp.Sessions {
+		sessions.Sessions = append(sessions.Sessions, dto.Session{
+			ID:         s.Id,
+			IP:         s.Ip,
+			UserAgent:  s.UserAgent,
+			Device:     s.Device,
+			CreatedAt:  s.CreatedAt.AsTime(),
+			LastUsedAt: s.LastUsedAt.AsTime(),
+		})
+	}
+
+	return sessions, http.StatusOK, nil
+}
+
+func (rgs *restGatewayService) RevokeSession(ctx context.Context, req *dto.RevokeSessionRequest, session string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return http.StatusGatewayTimeout, err
+	}
+
+	if err := rgs.ssoClient.RevokeSession(ctx, &ssopb.RevokeSessionRequest{
+		SessionId: req.SessionID,
+	}, session); err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		if errStatus.Code() == codes.InvalidArgument {
+			return http.StatusBadRequest, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.Unauthenticated {
+			return http.StatusUnauthorized, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.NotFound {
+			return http.StatusNotFound, errors.New(errStatus.Message())
+		}
+
+		rgs.log.Error("failed to do revoke session request", slog.String("error", err.Error()))
+		return http.StatusBadGateway, customerrors.ErrInternalServer
+	}
+
+	return http.StatusNoContent, nil
+}
+
 func (rgs *restGatewayService) UpdateUser(ctx context.Context, req *dto.UpdateRequest, session string) (int, error) {
 	if err := ctx.Err(); err != nil {
 		return http.StatusGatewayTimeout, err
@@ -189,9 +478,10 @@ func (rgs *restGatewayService) GetUserByID(ctx context.Context, req *dto.GetByID
 	}
 
 	return &dto.User{
-		ID:       user.Id,
-		Email:    user.Email,
-		Username: user.Username,
+		ID:            user.Id,
+		Email:         user.Email,
+		Username:      user.Username,
+		EmailVerified: user.EmailVerified,
 	}, http.StatusOK, nil
 }
 
@@ -217,8 +507,293 @@ func (rgs *restGatewayService) GetSelf(ctx context.Context, session string) (*dt
 	}
 
 	return &dto.User{
-		ID:       user.Id,
-		Email:    user.Email,
-		Username: user.Username,
+		ID:            user.Id,
+		Email:         user.Email,
+		Username:      user.Username,
+		EmailVerified: user.EmailVerified,
 	}, http.StatusOK, nil
 }
+
+func (rgs *restGatewayService) GetJWKS(ctx context.Context) (*dto.JWKS, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, http.StatusGatewayTimeout, err
+	}
+
+	jwks, err := rgs.ssoClient.GetJWKS(ctx)
+	if err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return nil, http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		rgs.log.Error("failed to do get jwks request", slog.String("error", err.Error()))
+		return nil, http.StatusBadGateway, errors.New(errStatus.Message())
+	}
+
+	resp := &dto.JWKS{
+		Keys: make([]dto.JWK, 0, len(jwks.Keys)),
+	}
+	for _, key := range jwks.Keys {
+		resp.Keys = append(resp.Keys, dto.JWK{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Use: key.Use,
+			Alg: key.Alg,
+			N:   key.N,
+			E:   key.E,
+			Crv: key.Crv,
+			X:   key.X,
+		})
+	}
+
+	return resp, http.StatusOK, nil
+}
+
+func (rgs *restGatewayService) ListExecutions(ctx context.Context, req *dto.ListExecutionsRequest, session string) (*dto.ExecutionsPage, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, http.StatusGatewayTimeout, err
+	}
+
+	resp, err := rgs.xcutrClient.ListExecutions(ctx, &xcutrpb.ListExecutionsRequest{
+		Language: req.Language,
+		Status:   req.Status,
+		Cursor:   req.Cursor,
+		Limit:    req.Limit,
+	}, session)
+	if err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return nil, http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		if errStatus.Code() == codes.InvalidArgument {
+			return nil, http.StatusBadRequest, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.Unauthenticated {
+			return nil, http.StatusUnauthorized, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.Unimplemented {
+			return nil, http.StatusNotImplemented, errors.New(errStatus.Message())
+		}
+
+		rgs.log.Error("failed to do list executions request", slog.String("error", err.Error()))
+		return nil, http.StatusBadGateway, customerrors.ErrInternalServer
+	}
+
+	page := &dto.ExecutionsPage{
+		Executions: make([]dto.Execution, 0, len(resp.Executions)),
+		NextCursor: resp.NextCursor,
+	}
+	for _, exec := range resp.Executions {
+		page.Executions = append(page.Executions, toExecution(exec))
+	}
+
+	return page, http.StatusOK, nil
+}
+
+func (rgs *restGatewayService) GetExecution(ctx context.Context, req *dto.GetExecutionRequest, session string) (*dto.Execution, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, http.StatusGatewayTimeout, err
+	}
+
+	resp, err := rgs.xcutrClient.GetExecution(ctx, &xcutrpb.GetExecutionRequest{
+		ExecutionId: req.ExecutionID,
+	}, session)
+	if err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return nil, http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		if errStatus.Code() == codes.InvalidArgument {
+			return nil, http.StatusBadRequest, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.Unauthenticated {
+			return nil, http.StatusUnauthorized, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.NotFound {
+			return nil, http.StatusNotFound, customerrors.ErrExecutionNotFound
+		}
+
+		if errStatus.Code() == codes.Unimplemented {
+			return nil, http.StatusNotImplemented, errors.New(errStatus.Message())
+		}
+
+		rgs.log.Error("failed to do get execution request", slog.String("error", err.Error()))
+		return nil, http.StatusBadGateway, customerrors.ErrInternalServer
+	}
+
+	exec := toExecution(resp)
+	exec.Output = resp.Output
+	exec.Files = make([]dto.SourceFile, 0, len(resp.Files))
+	for _, file := range resp.Files {
+		exec.Files = append(exec.Files, dto.SourceFile{
+			Name:   file.Name,
+			Mime:   file.Mime,
+			SHA256: file.Sha256,
+			Body:   file.Body,
+		})
+	}
+
+	return &exec, http.StatusOK, nil
+}
+
+func (rgs *restGatewayService) GetUsageStats(ctx context.Context, req *dto.UsageStatsRequest, session string) (*dto.UsageStats, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, http.StatusGatewayTimeout, err
+	}
+
+	statsReq := &xcutrpb.UsageStatsRequest{
+		Language: req.Language,
+		TopUsers: req.TopUsers,
+	}
+	if !req.From.IsZero() {
+		statsReq.From = timestamppb.New(req.From)
+	}
+	if !req.To.IsZero() {
+		statsReq.To = timestamppb.New(req.To)
+	}
+
+	resp, err := rgs.xcutrClient.GetUsageStats(ctx, statsReq, session)
+	if err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return nil, http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		if errStatus.Code() == codes.InvalidArgument {
+			return nil, http.StatusBadRequest, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.Unauthenticated {
+			return nil, http.StatusUnauthorized, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.PermissionDenied {
+			return nil, http.StatusForbidden, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.Unimplemented {
+			return nil, http.StatusNotImplemented, errors.New(errStatus.Message())
+		}
+
+		rgs.log.Error("failed to do usage stats request", slog.String("error", err.Error()))
+		return nil, http.StatusBadGateway, customerrors.ErrInternalServer
+	}
+
+	stats := &dto.UsageStats{
+		Daily:     make([]dto.DailyRuns, 0, len(resp.Daily)),
+		Languages: make([]dto.LanguageStats, 0, len(resp.Languages)),
+		TopUsers:  make([]dto.UserUsage, 0, len(resp.TopUsers)),
+	}
+	for _, daily := range resp.Daily {
+		stats.Daily = append(stats.Daily, dto.DailyRuns{
+			Day:      daily.Day,
+			Language: daily.Language,
+			Runs:     daily.Runs,
+		})
+	}
+	for _, lang := range resp.Languages {
+		stats.Languages = append(stats.Languages, dto.LanguageStats{
+			Language:      lang.Language,
+			Runs:          lang.Runs,
+			Failures:      lang.Failures,
+			FailureRate:   lang.FailureRate,
+			Timeouts:      lang.Timeouts,
+			OOMKills:      lang.OomKills,
+			P50DurationMS: lang.P50DurationMs,
+			P95DurationMS: lang.P95DurationMs,
+		})
+	}
+	for _, user := range resp.TopUsers {
+		stats.TopUsers = append(stats.TopUsers, dto.UserUsage{
+			UserID:     user.UserId,
+			Runs:       user.Runs,
+			DurationMS: user.DurationMs,
+		})
+	}
+
+	return stats, http.StatusOK, nil
+}
+
+func (rgs *restGatewayService) EraseUserData(ctx context.Context, req *dto.EraseUserDataRequest, session string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return http.StatusGatewayTimeout, err
+	}
+
+	if err := rgs.xcutrClient.EraseUserData(ctx, &xcutrpb.EraseUserDataRequest{
+		UserId: req.UserID,
+	}, session); err != nil {
+		errStatus, ok := status.FromError(err)
+		if !ok {
+			rgs.log.Error("failed to get status of error", slog.String("error", err.Error()))
+			return http.StatusBadGateway, customerrors.ErrInternalServer
+		}
+
+		if errStatus.Code() == codes.InvalidArgument {
+			return http.StatusBadRequest, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.Unauthenticated {
+			return http.StatusUnauthorized, errors.New(errStatus.Message())
+		}
+
+		if errStatus.Code() == codes.PermissionDenied {
+			return http.StatusForbidden, errors.New(errStatus.Message())
+		}
+
+		rgs.log.Error("failed to do erase user data request", slog.String("error", err.Error()))
+		return http.StatusBadGateway, customerrors.ErrInternalServer
+	}
+
+	return http.StatusNoContent, nil
+}
+
+func toExecution(record *xcutrpb.ExecutionRecord) dto.Execution {
+	return dto.Execution{
+		ID:              record.ExecutionId,
+		Language:        record.Language,
+		Status:          record.Status,
+		ExitCode:        record.ExitCode,
+		StartedAt:       record.StartedAt.AsTime(),
+		DurationMS:      record.DurationMs,
+		SourceTruncated: record.SourceTruncated,
+		OutputTruncated: record.OutputTruncated,
+	}
+}
+
+// Readiness asks the upstream services for their readiness,
+// the gateway is ready only if all of them are
+func (rgs *restGatewayService) Readiness(ctx context.Context) (*dto.Readiness, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, http.StatusGatewayTimeout, err
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	resp := &dto.Readiness{
+		Status:   "ready",
+		Services: rgs.healthClient.Check(ctxTimeout),
+	}
+	for name, serviceStatus := range resp.Services {
+		if serviceStatus != healthpb.HealthCheckResponse_SERVING.String() {
+			rgs.log.Debug("upstream service isn't ready", slog.String("service", name), slog.String("status", serviceStatus))
+			resp.Status = "not ready"
+		}
+	}
+
+	if resp.Status != "ready" {
+		return resp, http.StatusServiceUnavailable, nil
+	}
+
+	return resp, http.StatusOK, nil
+}
@@ -0,0 +1,5 @@
+package auth
+
+type JWTManager interface {
+	Validate(tokenString string) (*CoderunClaims, error)
+}
@@ -10,6 +10,15 @@ type SSOClient interface {
 	Register(context.Context, *ssopb.RegisterRequest) (*ssopb.Token, error)
 	Login(context.Context, *ssopb.LoginRequest) (*ssopb.Token, error)
 	Refresh(context.Context, *ssopb.RefreshRequest) (*ssopb.Token, error)
+	Logout(context.Context, *ssopb.LogoutRequest) error
+	SendVerification(context.Context, *ssopb.SendVerificationRequest) error
+	VerifyEmail(context.Context, *ssopb.VerifyEmailRequest) error
+	RequestPasswordReset(context.Context, *ssopb.RequestPasswordResetRequest) error
+	ResetPassword(context.Context, *ssopb.ResetPasswordRequest) error
+	LogoutAll(context.Context, string) error
+	ListSessions(context.Context, string) (*ssopb.SessionList, error)
+	RevokeSession(context.Context, *ssopb.RevokeSessionRequest, string) error
 	UpdateUser(context.Context, *ssopb.UpdateRequest, string) error
 	GetUserByID(context.Context, *ssopb.GetByIDRequest) (*ssopb.User, error)
+	GetJWKS(context.Context) (*ssopb.JWKS, error)
 }
@@ -0,0 +1,14 @@
+package xcutrservice
+
+import (
+	"context"
+
+	xcutrpb "github.com/devathh/coderun/rest-gateway/api/xcutr/v1"
+)
+
+type XcutrClient interface {
+	ListExecutions(context.Context, *xcutrpb.ListExecutionsRequest, string) (*xcutrpb.ListExecutionsResponse, error)
+	GetExecution(context.Context, *xcutrpb.GetExecutionRequest, string) (*xcutrpb.ExecutionRecord, error)
+	GetUsageStats(context.Context, *xcutrpb.UsageStatsRequest, string) (*xcutrpb.UsageStats, error)
+	EraseUserData(context.Context, *xcutrpb.EraseUserDataRequest, string) error
+}
@@ -0,0 +1,93 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	ssopb "github.com/devathh/coderun/rest-gateway/api/sso/v1"
+	"github.com/devathh/coderun/rest-gateway/internal/domain/auth"
+	ssoservice "github.com/devathh/coderun/rest-gateway/internal/domain/sso-service"
+	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/config"
+	customerrors "github.com/devathh/coderun/rest-gateway/pkg/errors"
+	"github.com/devathh/coderun/shared/jwks"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type JWTManager struct {
+	cfg  *config.Config
+	keys *jwks.KeySet
+}
+
+// New checks the tokens with the keys from GetJWKS of sso
+func New(cfg *config.Config, sso ssoservice.SSOClient) (*JWTManager, error) {
+	if cfg == nil || sso == nil {
+		return nil, customerrors.ErrNilArgs
+	}
+
+	fetch := func(ctx context.Context) ([]jwks.JWK, error) {
+		resp, err := sso.GetJWKS(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]jwks.JWK, 0, len(resp.Keys))
+		for _, key := range resp.Keys {
+			keys = append(keys, toJWK(key))
+		}
+
+		return keys, nil
+	}
+
+	return &JWTManager{
+		cfg:  cfg,
+		keys: jwks.NewKeySet(fetch, cfg.Secrets.JWT.KeysRefresh),
+	}, nil
+}
+
+func (jm *JWTManager) Validate(tokenString string) (*auth.CoderunClaims, error) {
+	if tokenString == "" {
+		return nil, customerrors.ErrInvalidToken
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &auth.CoderunClaims{}, func(t *jwt.Token) (any, error) {
+		// The tokens signed before the kids were set have none
+		kid, _ := t.Header["kid"].(string)
+		key, err := jm.keys.Get(kid)
+		if errors.Is(err, jwks.ErrUnknownKey) {
+			return nil, customerrors.ErrInvalidToken
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// The alg of the token must be the one of its key
+		if t.Method.Alg() != jwks.MethodOf(key).Alg() {
+			return nil, customerrors.ErrInvalidToken
+		}
+
+		return key, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	if claims, ok := token.Claims.(*auth.CoderunClaims); ok {
+		return claims, nil
+	}
+
+	return nil, customerrors.ErrInvalidToken
+}
+
+func toJWK(key *ssopb.JWK) jwks.JWK {
+	return jwks.JWK{
+		Kty: key.Kty,
+		Kid: key.Kid,
+		Use: key.Use,
+		Alg: key.Alg,
+		N:   key.N,
+		E:   key.E,
+		Crv: key.Crv,
+		X:   key.X,
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/goccy/go-yaml"
@@ -29,9 +30,18 @@ type server struct {
 		Host string `yaml:"host"`
 		Port string `yaml:"port"`
 	} `yaml:"http"`
+	// Prometheus metrics on /metrics, disabled by default
+	Metrics struct {
+		Enable bool   `yaml:"enable"`
+		Host   string `yaml:"host"`
+		Port   string `yaml:"port"`
+	} `yaml:"metrics"`
 	ReadTimeout  time.Duration `yaml:"read-timeout"`
 	WriteTimeout time.Duration `yaml:"write-timeout"`
 	IdleTimeout  time.Duration `yaml:"idle-timeout"`
+	// The proxies in front of the gateway, the ip of the client is taken
+	// from their X-Forwarded-For. None are trusted by default
+	TrustedProxies []string `yaml:"trusted-proxies"`
 }
 
 func (s *server) validate() error {
@@ -41,6 +51,12 @@ func (s *server) validate() error {
 	if s.HTTP.Port == "" {
 		s.HTTP.Port = "9090"
 	}
+	if s.Metrics.Host == "" {
+		s.Metrics.Host = "localhost"
+	}
+	if s.Metrics.Port == "" {
+		s.Metrics.Port = "9093"
+	}
 	if s.ReadTimeout < time.Millisecond {
 		return errors.New("too little read timeout")
 	}
@@ -54,6 +70,163 @@ func (s *server) validate() error {
 	return nil
 }
 
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+type tracing struct {
+	Enable bool `yaml:"enable"`
+	// otlp (over grpc) or stdout for local use
+	Exporter string `yaml:"exporter"`
+	// host:port of the otlp collector
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"`
+	// Share of the traces started here that are recorded,
+	// the ones continued from the client follow its decision
+	SampleRatio float64 `yaml:"sample-ratio"`
+}
+
+func (t *tracing) validate() error {
+	if !t.Enable {
+		return nil
+	}
+
+	if t.Exporter == "" {
+		t.Exporter = ExporterOTLP
+	}
+	if t.Exporter != ExporterOTLP && t.Exporter != ExporterStdout {
+		return fmt.Errorf("invalid exporter: %s", t.Exporter)
+	}
+	if t.Exporter == ExporterOTLP && t.Endpoint == "" {
+		t.Endpoint = "localhost:4317"
+	}
+	if t.SampleRatio <= 0 {
+		t.SampleRatio = 1
+	}
+	if t.SampleRatio > 1 {
+		return errors.New("sample ratio is greater than 1")
+	}
+
+	return nil
+}
+
+const (
+	RateLimitByIP   = "ip"
+	RateLimitByUser = "user"
+)
+
+type RateLimitRule struct {
+	// Method n' pattern of the route, like POST /api/v1/login
+	Route string `yaml:"route"`
+	// user or ip. The requests without a session are limited by ip
+	Key string `yaml:"key"`
+	// Requests allowed per period on average
+	Rate   int           `yaml:"rate"`
+	Period time.Duration `yaml:"period"`
+	// Requests allowed at once, the rate by default
+	Burst int `yaml:"burst"`
+}
+
+func (r *RateLimitRule) validate() error {
+	method, route, ok := strings.Cut(r.Route, " ")
+	if !ok || method == "" || !strings.HasPrefix(route, "/") {
+		return fmt.Errorf("invalid route: %s", r.Route)
+	}
+	if r.Key == "" {
+		r.Key = RateLimitByUser
+	}
+	if r.Key != RateLimitByIP && r.Key != RateLimitByUser {
+		return fmt.Errorf("invalid key: %s", r.Key)
+	}
+	if r.Rate <= 0 {
+		return errors.New("invalid rate")
+	}
+	if r.Period <= 0 {
+		r.Period = time.Minute
+	}
+	if r.Burst <= 0 {
+		r.Burst = r.Rate
+	}
+
+	return nil
+}
+
+// Token buckets in redis, so the limits hold across the replicas.
+// The requests are let through while redis is unavailable
+type rateLimit struct {
+	Enable bool            `yaml:"enable"`
+	Rules  []RateLimitRule `yaml:"rules"`
+}
+
+func (r *rateLimit) validate() error {
+	if !r.Enable {
+		return nil
+	}
+
+	routes := make(map[string]bool, len(r.Rules))
+	for i := range r.Rules {
+		if err := r.Rules[i].validate(); err != nil {
+			return fmt.Errorf("invalid rule #%d: %w", i, err)
+		}
+		if routes[r.Rules[i].Route] {
+			return fmt.Errorf("duplicate rule: %s", r.Rules[i].Route)
+		}
+		routes[r.Rules[i].Route] = true
+	}
+
+	return nil
+}
+
+// ByUser reports whether any of the rules needs the user of the request
+func (r *rateLimit) ByUser() bool {
+	if !r.Enable {
+		return false
+	}
+
+	for _, rule := range r.Rules {
+		if rule.Key == RateLimitByUser {
+			return true
+		}
+	}
+
+	return false
+}
+
+type redis struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Password string `yaml:"password"`
+}
+
+func (r *redis) validate() error {
+	if r.Host == "" {
+		r.Host = "localhost"
+	}
+	if r.Port == "" {
+		r.Port = "6379"
+	}
+
+	return nil
+}
+
+type jwt struct {
+	// The keys of sso are fetched from its GetJWKS n' refetched so often,
+	// 5m by default. The gateway only checks the tokens with them
+	KeysRefresh time.Duration `yaml:"keys-refresh"`
+}
+
+func (j *jwt) validate() error {
+	if j.KeysRefresh == 0 {
+		j.KeysRefresh = 5 * time.Minute
+	}
+	if j.KeysRefresh < time.Second {
+		return errors.New("too little keys refresh")
+	}
+
+	return nil
+}
+
 type coderunService struct {
 	Host string `yaml:"host"`
 	Port string `yaml:"port"`
@@ -71,11 +244,20 @@ func (cs *coderunService) validate() error {
 }
 
 type Config struct {
-	App      app    `yaml:"app"`
-	Server   server `yaml:"server"`
-	Services struct {
-		CoderunSSO coderunService `yaml:"coderun-sso"`
+	App       app       `yaml:"app"`
+	Server    server    `yaml:"server"`
+	Tracing   tracing   `yaml:"tracing"`
+	RateLimit rateLimit `yaml:"rate-limit"`
+	Services  struct {
+		CoderunSSO   coderunService `yaml:"coderun-sso"`
+		CoderunXcutr coderunService `yaml:"coderun-xcutr"`
 	} `yaml:"services"`
+	// Used by the rate limits only
+	Secrets struct {
+		Redis redis `yaml:"redis"`
+		// Used if the requests are limited by user
+		JWT jwt `yaml:"jwt"`
+	} `yaml:"secrets"`
 }
 
 // The path of config file can be used from .env
@@ -115,9 +297,24 @@ func (c *Config) validate() error {
 	if err := c.Server.validate(); err != nil {
 		return fmt.Errorf("invalid server: %w", err)
 	}
+	if err := c.Tracing.validate(); err != nil {
+		return fmt.Errorf("invalid tracing: %w", err)
+	}
+	if err := c.RateLimit.validate(); err != nil {
+		return fmt.Errorf("invalid rate limit: %w", err)
+	}
 	if err := c.Services.CoderunSSO.validate(); err != nil {
 		return fmt.Errorf("invalid coderun-sso: %w", err)
 	}
+	if err := c.Services.CoderunXcutr.validate(); err != nil {
+		return fmt.Errorf("invalid coderun-xcutr: %w", err)
+	}
+	if err := c.Secrets.Redis.validate(); err != nil {
+		return fmt.Errorf("invalid redis: %w", err)
+	}
+	if err := c.Secrets.JWT.validate(); err != nil {
+		return fmt.Errorf("invalid jwt: %w", err)
+	}
 
 	return nil
 }
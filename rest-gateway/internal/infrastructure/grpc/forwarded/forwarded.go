@@ -0,0 +1,47 @@
+package forwarded
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Client is the http client of the gateway, as the services should see it
+type Client struct {
+	IP        string
+	UserAgent string
+	// Like "Firefox on Linux", the sessions are shown by it
+	Device string
+}
+
+type clientKey struct{}
+
+// WithClient keeps the http client for the calls to the services
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// UnaryClientInterceptor passes the client in the metadata: the services limit
+// the calls by its ip n' record its device in the sessions. Otherwise they
+// would see the gateway itself
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if client, ok := ctx.Value(clientKey{}).(Client); ok {
+			kv := make([]string, 0, 6)
+			if client.IP != "" {
+				kv = append(kv, "x-forwarded-for", client.IP)
+			}
+			// grpc sets its own user-agent
+			if client.UserAgent != "" {
+				kv = append(kv, "x-client-user-agent", client.UserAgent)
+			}
+			if client.Device != "" {
+				kv = append(kv, "x-device-label", client.Device)
+			}
+			ctx = metadata.AppendToOutgoingContext(ctx, kv...)
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
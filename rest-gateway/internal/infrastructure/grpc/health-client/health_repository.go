@@ -0,0 +1,60 @@
+package healthclient
+
+import (
+	"context"
+	"sync"
+
+	customerrors "github.com/devathh/coderun/rest-gateway/pkg/errors"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Service of the upstreams' health checks that includes their dependencies
+const readiness = "readiness"
+
+// Unreachable is the status of an upstream that didn't answer
+const Unreachable = "UNREACHABLE"
+
+type HealthClient struct {
+	upstreams map[string]healthpb.HealthClient
+}
+
+// New creates the client of the upstreams' health services by their names
+func New(conns map[string]*grpc.ClientConn) (*HealthClient, error) {
+	upstreams := make(map[string]healthpb.HealthClient, len(conns))
+	for name, conn := range conns {
+		if conn == nil {
+			return nil, customerrors.ErrNilArgs
+		}
+		upstreams[name] = healthpb.NewHealthClient(conn)
+	}
+
+	return &HealthClient{
+		upstreams: upstreams,
+	}, nil
+}
+
+// Check asks every upstream for its readiness at once.
+// It returns the statuses by the names of the upstreams
+func (hc *HealthClient) Check(ctx context.Context) map[string]string {
+	var mu sync.Mutex
+	statuses := make(map[string]string, len(hc.upstreams))
+
+	var wg sync.WaitGroup
+	for name, client := range hc.upstreams {
+		wg.Go(func() {
+			status := Unreachable
+			resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: readiness})
+			if err == nil {
+				status = resp.GetStatus().String()
+			}
+
+			mu.Lock()
+			statuses[name] = status
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	return statuses
+}
@@ -6,6 +6,8 @@ import (
 
 	ssopb "github.com/devathh/coderun/rest-gateway/api/sso/v1"
 	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/config"
+	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/grpc/forwarded"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -16,7 +18,12 @@ func Connect(cfg *config.Config) (ssopb.SSOClient, *grpc.ClientConn, error) {
 		cfg.Services.CoderunSSO.Port,
 	)
 
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		// Passes the trace of the request in the metadata
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithUnaryInterceptor(forwarded.UnaryClientInterceptor()),
+	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to connect to sso: %w", err)
 	}
@@ -49,6 +49,87 @@ func (sc *SSOClient) Refresh(ctx context.Context, req *ssopb.RefreshRequest) (*s
 	return resp, nil
 }
 
+func (sc *SSOClient) Logout(ctx context.Context, req *ssopb.LogoutRequest) error {
+	_, err := sc.client.Logout(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (sc *SSOClient) SendVerification(ctx context.Context, req *ssopb.SendVerificationRequest) error {
+	_, err := sc.client.SendVerification(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (sc *SSOClient) VerifyEmail(ctx context.Context, req *ssopb.VerifyEmailRequest) error {
+	_, err := sc.client.VerifyEmail(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (sc *SSOClient) RequestPasswordReset(ctx context.Context, req *ssopb.RequestPasswordResetRequest) error {
+	_, err := sc.client.RequestPasswordReset(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (sc *SSOClient) ResetPassword(ctx context.Context, req *ssopb.ResetPasswordRequest) error {
+	_, err := sc.client.ResetPassword(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (sc *SSOClient) LogoutAll(ctx context.Context, token string) error {
+	md := metadata.MD{}
+	md.Set("session", token)
+
+	_, err := sc.client.LogoutAll(metadata.NewOutgoingContext(ctx, md), &ssopb.Empty{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (sc *SSOClient) ListSessions(ctx context.Context, token string) (*ssopb.SessionList, error) {
+	md := metadata.MD{}
+	md.Set("session", token)
+
+	resp, err := sc.client.ListSessions(metadata.NewOutgoingContext(ctx, md), &ssopb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (sc *SSOClient) RevokeSession(ctx context.Context, req *ssopb.RevokeSessionRequest, token string) error {
+	md := metadata.MD{}
+	md.Set("session", token)
+
+	_, err := sc.client.RevokeSession(metadata.NewOutgoingContext(ctx, md), req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (sc *SSOClient) UpdateUser(ctx context.Context, req *ssopb.UpdateRequest, token string) error {
 	md := metadata.MD{}
 	md.Set("session", token)
@@ -70,6 +151,15 @@ func (sc *SSOClient) GetUserByID(ctx context.Context, req *ssopb.GetByIDRequest)
 	return resp, nil
 }
 
+func (sc *SSOClient) GetJWKS(ctx context.Context) (*ssopb.JWKS, error) {
+	resp, err := sc.client.GetJWKS(ctx, &ssopb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 func (sc *SSOClient) GetSelf(ctx context.Context, token string) (*ssopb.User, error) {
 	md := metadata.MD{}
 	md.Set("session", token)
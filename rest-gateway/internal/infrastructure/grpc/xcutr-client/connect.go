@@ -0,0 +1,42 @@
+package xcutrclient
+
+import (
+	"fmt"
+	"net"
+
+	xcutrpb "github.com/devathh/coderun/rest-gateway/api/xcutr/v1"
+	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/config"
+	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/grpc/forwarded"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func Connect(cfg *config.Config) (xcutrpb.XcutrClient, *grpc.ClientConn, error) {
+	addr := net.JoinHostPort(
+		cfg.Services.CoderunXcutr.Host,
+		cfg.Services.CoderunXcutr.Port,
+	)
+
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		// Passes the trace of the request in the metadata
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithUnaryInterceptor(forwarded.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to xcutr: %w", err)
+	}
+
+	client := xcutrpb.NewXcutrClient(conn)
+
+	return client, conn, nil
+}
+
+func Close(conn *grpc.ClientConn) error {
+	if err := conn.Close(); err != nil {
+		return fmt.Errorf("failed to close connection with xcutr: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package xcutrclient
+
+import (
+	"context"
+
+	xcutrpb "github.com/devathh/coderun/rest-gateway/api/xcutr/v1"
+	customerrors "github.com/devathh/coderun/rest-gateway/pkg/errors"
+	"google.golang.org/grpc/metadata"
+)
+
+type XcutrClient struct {
+	client xcutrpb.XcutrClient
+}
+
+func New(client xcutrpb.XcutrClient) (*XcutrClient, error) {
+	if client == nil {
+		return nil, customerrors.ErrNilArgs
+	}
+
+	return &XcutrClient{
+		client: client,
+	}, nil
+}
+
+func (xc *XcutrClient) ListExecutions(ctx context.Context, req *xcutrpb.ListExecutionsRequest, token string) (*xcutrpb.ListExecutionsResponse, error) {
+	md := metadata.MD{}
+	md.Set("session", token)
+
+	resp, err := xc.client.ListExecutions(metadata.NewOutgoingContext(ctx, md), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (xc *XcutrClient) GetExecution(ctx context.Context, req *xcutrpb.GetExecutionRequest, token string) (*xcutrpb.ExecutionRecord, error) {
+	md := metadata.MD{}
+	md.Set("session", token)
+
+	resp, err := xc.client.GetExecution(metadata.NewOutgoingContext(ctx, md), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (xc *XcutrClient) GetUsageStats(ctx context.Context, req *xcutrpb.UsageStatsRequest, token string) (*xcutrpb.UsageStats, error) {
+	md := metadata.MD{}
+	md.Set("session", token)
+
+	resp, err := xc.client.GetUsageStats(metadata.NewOutgoingContext(ctx, md), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (xc *XcutrClient) EraseUserData(ctx context.Context, req *xcutrpb.EraseUserDataRequest, token string) error {
+	md := metadata.MD{}
+	md.Set("session", token)
+
+	_, err := xc.client.EraseUserData(metadata.NewOutgoingContext(ctx, md), req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
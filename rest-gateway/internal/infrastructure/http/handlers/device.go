@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// The label given by the client is cut to this length
+const maxDeviceLabel = 64
+
+// deviceLabel names the device of the session: by the X-Device-Label of the client,
+// otherwise by its user agent, like "Firefox on Linux"
+func deviceLabel(label, userAgent string) string {
+	label = strings.TrimSpace(label)
+	if label != "" {
+		if utf8.RuneCountInString(label) > maxDeviceLabel {
+			label = string([]rune(label)[:maxDeviceLabel])
+		}
+		return label
+	}
+
+	browser := match(userAgent, []labelRule{
+		// The order matters: Edge n' Opera mention Chrome, Chrome mentions Safari
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	})
+	system := match(userAgent, []labelRule{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	})
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+
+	return "Unknown device"
+}
+
+type labelRule struct {
+	token string
+	name  string
+}
+
+func match(userAgent string, rules []labelRule) string {
+	for _, rule := range rules {
+		if strings.Contains(userAgent, rule.token) {
+			return rule.name
+		}
+	}
+
+	return ""
+}
@@ -8,9 +8,11 @@ import (
 	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/config"
 	customerrors "github.com/devathh/coderun/rest-gateway/pkg/errors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func New(cfg *config.Config, service services.RestGatewayService) (http.Handler, error) {
+// The rate limiter is nil if the limits are disabled
+func New(cfg *config.Config, service services.RestGatewayService, rateLimiter *RateLimiter) (http.Handler, error) {
 	if cfg == nil {
 		return nil, customerrors.ErrNilArgs
 	}
@@ -27,8 +29,28 @@ func New(cfg *config.Config, service services.RestGatewayService) (http.Handler,
 		return nil, fmt.Errorf("invalid environment")
 	}
 
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	// The routes pass gin's context to the services,
+	// so it must give out the values of the request's one, like the span
+	router.ContextWithFallback = true
+	router.Use(
+		otelgin.Middleware(cfg.App.Name),
+		metricsMiddleware(),
+		clientMiddleware(),
+	)
+	if rateLimiter != nil {
+		router.Use(rateLimiter.Middleware())
+	}
+
 	routes := NewRoutes(service)
 
+	router.GET("/healthz", routes.Healthz())
+	router.GET("/readyz", routes.Readyz())
+	router.GET("/.well-known/jwks.json", routes.JWKS())
+
 	api := router.Group("/api")
 	{
 		v1 := api.Group("/v1")
@@ -36,10 +58,25 @@ func New(cfg *config.Config, service services.RestGatewayService) (http.Handler,
 			v1.POST("/register", routes.Register())
 			v1.POST("/login", routes.Login())
 			v1.POST("/refresh", routes.Refresh())
+			v1.POST("/logout", routes.Logout())
+			v1.POST("/logout-all", routes.LogoutAll())
+			v1.POST("/verify-email", routes.VerifyEmail())
+			v1.POST("/verify-email/send", routes.SendVerification())
+			v1.POST("/reset-password", routes.ResetPassword())
+			v1.POST("/reset-password/send", routes.RequestPasswordReset())
+
+			v1.GET("/sessions", routes.ListSessions())
+			v1.DELETE("/sessions/:id", routes.RevokeSession())
 
 			v1.PATCH("/user", routes.UpdateUser())
 			v1.GET("/user", routes.GetSelf())
 			v1.GET("/user/:id", routes.GetUserByID())
+
+			v1.GET("/executions", routes.ListExecutions())
+			v1.GET("/executions/:id", routes.GetExecution())
+
+			v1.GET("/admin/usage-stats", routes.GetUsageStats())
+			v1.DELETE("/admin/users/:id/data", routes.EraseUserData())
 		}
 	}
 
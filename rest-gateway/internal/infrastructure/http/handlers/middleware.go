@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devathh/coderun/rest-gateway/internal/domain/auth"
+	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/config"
+	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/grpc/forwarded"
+	"github.com/devathh/coderun/rest-gateway/internal/infrastructure/metrics"
+	"github.com/devathh/coderun/shared/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// metricsMiddleware records the requests by the pattern of their route,
+// not by the path, so ids don't blow up the labels
+func metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// clientMiddleware keeps the client for the services,
+// they limit the calls by its ip too n' record its device in the sessions
+func clientMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAgent := c.Request.UserAgent()
+
+		c.Request = c.Request.WithContext(forwarded.WithClient(c.Request.Context(), forwarded.Client{
+			IP:        c.ClientIP(),
+			UserAgent: userAgent,
+			Device:    deviceLabel(c.GetHeader("X-Device-Label"), userAgent),
+		}))
+		c.Next()
+	}
+}
+
+// RateLimiter limits the requests by the rules of their routes
+type RateLimiter struct {
+	log     *slog.Logger
+	limiter *ratelimit.Limiter
+	// Nil if no rule is by user
+	jwtManager auth.JWTManager
+}
+
+func NewRateLimiter(log *slog.Logger, limiter *ratelimit.Limiter, jwtManager auth.JWTManager) *RateLimiter {
+	return &RateLimiter{
+		log:        log,
+		limiter:    limiter,
+		jwtManager: jwtManager,
+	}
+}
+
+func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rule, ok := rl.limiter.Rule(c.Request.Method + " " + c.FullPath())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		result, err := rl.limiter.Allow(c, rule, rl.client(c, rule))
+		if err != nil {
+			// A limit isn't worth failing the request
+			rl.log.Warn("rate limit is skipped", slog.String("route", rule.Name), slog.String("error", err.Error()))
+			c.Next()
+			return
+		}
+
+		for key, value := range result.Headers() {
+			c.Header(key, value)
+		}
+
+		if !result.Allowed {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "rate limit is exceeded",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// client is the user from the session or the ip of the request
+func (rl *RateLimiter) client(c *gin.Context, rule ratelimit.Rule) string {
+	if rule.Key == config.RateLimitByUser && rl.jwtManager != nil {
+		if token, err := c.Cookie("session"); err == nil {
+			if claims, err := rl.jwtManager.Validate(token); err == nil {
+				return "user:" + claims.UserID.String()
+			}
+		}
+	}
+
+	return "ip:" + c.ClientIP()
+}
@@ -84,6 +84,194 @@ func (r *Routes) Refresh() gin.HandlerFunc {
 	}
 }
 
+// Logout revokes the refresh token. The cookie is cleared anyway,
+// the client is logged out here even if the token is unknown
+func (r *Routes) Logout() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		clearSession(ctx)
+
+		var req dto.LogoutRequest
+		if err := ctx.BindJSON(&req); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request",
+			})
+			return
+		}
+
+		code, err := r.service.Logout(ctx, &req)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.Status(code)
+	}
+}
+
+// SendVerification emails a new token to verify the email
+func (r *Routes) SendVerification() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req dto.SendVerificationRequest
+		if err := ctx.BindJSON(&req); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request",
+			})
+			return
+		}
+
+		code, err := r.service.SendVerification(ctx, &req)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.Status(code)
+	}
+}
+
+func (r *Routes) VerifyEmail() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req dto.VerifyEmailRequest
+		if err := ctx.BindJSON(&req); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request",
+			})
+			return
+		}
+
+		code, err := r.service.VerifyEmail(ctx, &req)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.Status(code)
+	}
+}
+
+// RequestPasswordReset emails a token to reset the password
+func (r *Routes) RequestPasswordReset() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req dto.RequestPasswordResetRequest
+		if err := ctx.BindJSON(&req); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request",
+			})
+			return
+		}
+
+		code, err := r.service.RequestPasswordReset(ctx, &req)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.Status(code)
+	}
+}
+
+// ResetPassword sets the new password, every session of the user is revoked
+func (r *Routes) ResetPassword() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req dto.ResetPasswordRequest
+		if err := ctx.BindJSON(&req); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request",
+			})
+			return
+		}
+
+		code, err := r.service.ResetPassword(ctx, &req)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.Status(code)
+	}
+}
+
+// LogoutAll revokes every session of the user
+func (r *Routes) LogoutAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := ctx.Cookie("session")
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid token",
+			})
+			return
+		}
+		clearSession(ctx)
+
+		code, err := r.service.LogoutAll(ctx, token)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.Status(code)
+	}
+}
+
+func (r *Routes) ListSessions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := ctx.Cookie("session")
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid token",
+			})
+			return
+		}
+
+		resp, code, err := r.service.ListSessions(ctx, token)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.JSON(code, resp)
+	}
+}
+
+// RevokeSession signs out one device of the user
+func (r *Routes) RevokeSession() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := ctx.Cookie("session")
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid token",
+			})
+			return
+		}
+
+		code, err := r.service.RevokeSession(ctx, &dto.RevokeSessionRequest{
+			SessionID: ctx.Param("id"),
+		}, token)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.Status(code)
+	}
+}
+
 func (r *Routes) UpdateUser() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		token, err := ctx.Cookie("session")
@@ -153,3 +341,156 @@ func (r *Routes) GetSelf() gin.HandlerFunc {
 		ctx.JSON(code, resp)
 	}
 }
+
+func (r *Routes) ListExecutions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := ctx.Cookie("session")
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid token",
+			})
+			return
+		}
+
+		var req dto.ListExecutionsRequest
+		if err := ctx.BindQuery(&req); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request",
+			})
+			return
+		}
+
+		resp, code, err := r.service.ListExecutions(ctx, &req, token)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.JSON(code, resp)
+	}
+}
+
+func (r *Routes) GetExecution() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := ctx.Cookie("session")
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid token",
+			})
+			return
+		}
+
+		resp, code, err := r.service.GetExecution(ctx, &dto.GetExecutionRequest{
+			ExecutionID: ctx.Param("id"),
+		}, token)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.JSON(code, resp)
+	}
+}
+
+func (r *Routes) GetUsageStats() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := ctx.Cookie("session")
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid token",
+			})
+			return
+		}
+
+		var req dto.UsageStatsRequest
+		if err := ctx.BindQuery(&req); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request",
+			})
+			return
+		}
+
+		resp, code, err := r.service.GetUsageStats(ctx, &req, token)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.JSON(code, resp)
+	}
+}
+
+func (r *Routes) EraseUserData() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := ctx.Cookie("session")
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid token",
+			})
+			return
+		}
+
+		code, err := r.service.EraseUserData(ctx, &dto.EraseUserDataRequest{
+			UserID: ctx.Param("id"),
+		}, token)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.Status(code)
+	}
+}
+
+// JWKS serves the public keys of sso, so the tokens can be checked without it
+func (r *Routes) JWKS() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		resp, code, err := r.service.GetJWKS(ctx)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		// The new keys are published before they sign the tokens
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.JSON(code, resp)
+	}
+}
+
+// Healthz is the liveness of the gateway, it doesn't depend on the upstreams
+func (r *Routes) Healthz() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{
+			"status": "alive",
+		})
+	}
+}
+
+func (r *Routes) Readyz() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		resp, code, err := r.service.Readiness(ctx)
+		if err != nil {
+			ctx.AbortWithStatusJSON(code, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ctx.JSON(code, resp)
+	}
+}
+
+// clearSession removes the cookie with the access token
+func clearSession(ctx *gin.Context) {
+	ctx.SetCookie("session", "", -1, "/", "", false, true)
+}
@@ -7,5 +7,9 @@ var (
 
 	ErrInternalServer = errors.New("internal server error")
 
+	ErrInvalidToken = errors.New("invalid token")
+
 	ErrUserNotFound = errors.New("user not found")
+
+	ErrExecutionNotFound = errors.New("execution not found")
 )
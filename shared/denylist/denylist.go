@@ -0,0 +1,127 @@
+// Package denylist is shared by sso, which revokes the sessions,
+// n' the services that check them
+package denylist
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/devathh/coderun/shared/tracing"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/devathh/coderun/shared/denylist")
+
+// The cache is dropped when it's full of live entries
+const maxCacheEntries = 10000
+
+type cacheEntry struct {
+	revoked bool
+	expires time.Time
+}
+
+// Cache keeps the answers of redis for a short time,
+// so not every call goes there
+type Cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[uuid.UUID]cacheEntry
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]cacheEntry),
+	}
+}
+
+// Get returns the cached answer, ok is false if there is none or it's expired
+func (lc *Cache) Get(sessionID uuid.UUID) (revoked, ok bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	entry, ok := lc.entries[sessionID]
+	if !ok || time.Now().After(entry.expires) {
+		return false, false
+	}
+
+	return entry.revoked, true
+}
+
+func (lc *Cache) Set(sessionID uuid.UUID, revoked bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	now := time.Now()
+	if len(lc.entries) >= maxCacheEntries {
+		for id, entry := range lc.entries {
+			if now.After(entry.expires) {
+				delete(lc.entries, id)
+			}
+		}
+		if len(lc.entries) >= maxCacheEntries {
+			clear(lc.entries)
+		}
+	}
+
+	lc.entries[sessionID] = cacheEntry{
+		revoked: revoked,
+		expires: now.Add(lc.ttl),
+	}
+}
+
+// Key of the revoked session in redis
+func Key(sessionID uuid.UUID) string {
+	return "denylist:session:" + sessionID.String()
+}
+
+// Checker reads the denylist in redis through the cache.
+// The sessions are revoked as a whole, with every access token issued in them
+type Checker struct {
+	client *redis.Client
+	cache  *Cache
+}
+
+func NewChecker(client *redis.Client, cacheTTL time.Duration) *Checker {
+	return &Checker{
+		client: client,
+		cache:  NewCache(cacheTTL),
+	}
+}
+
+// IsRevoked tells whether the session is in the denylist. The tokens issued
+// before the sessions had ids have no session, they can't be revoked
+func (c *Checker) IsRevoked(ctx context.Context, sessionID uuid.UUID) (_ bool, err error) {
+	if sessionID == uuid.Nil {
+		return false, nil
+	}
+
+	if revoked, ok := c.cache.Get(sessionID); ok {
+		return revoked, nil
+	}
+
+	ctx, span := tracer.Start(ctx, "denylist.IsRevoked")
+	defer func() { tracing.End(span, err) }()
+
+	exists, err := c.client.Exists(ctx, Key(sessionID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+
+	revoked := exists > 0
+	c.cache.Set(sessionID, revoked)
+
+	return revoked, nil
+}
+
+// Revoked caches the sessions revoked by this replica,
+// so they are refused here without waiting for the cached answers to expire
+func (c *Checker) Revoked(sessionIDs ...uuid.UUID) {
+	for _, id := range sessionIDs {
+		c.cache.Set(id, true)
+	}
+}
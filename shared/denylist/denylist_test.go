@@ -0,0 +1,100 @@
+package denylist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func newChecker(t *testing.T, cacheTTL time.Duration) (*Checker, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewChecker(client, cacheTTL), server
+}
+
+func TestCheckerIsRevoked(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		CacheTTL time.Duration
+		// The session is revoked by another replica after the first check
+		RevokedLater bool
+		// The session is revoked by this replica
+		RevokedHere bool
+		WantFirst   bool
+		WantSecond  bool
+	}{
+		{Name: "not_revoked", CacheTTL: time.Hour},
+		// The cached answer is used till it expires
+		{Name: "cached", CacheTTL: time.Hour, RevokedLater: true, WantSecond: false},
+		{Name: "no_cache", CacheTTL: 0, RevokedLater: true, WantSecond: true},
+		{Name: "revoked_here", CacheTTL: time.Hour, RevokedHere: true, WantSecond: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			checker, server := newChecker(t, tc.CacheTTL)
+			sessionID := uuid.New()
+
+			revoked, err := checker.IsRevoked(t.Context(), sessionID)
+			if err != nil {
+				t.Fatalf("failed to check session: %v", err)
+			}
+			if revoked != tc.WantFirst {
+				t.Errorf("got %v on the first check, want %v", revoked, tc.WantFirst)
+			}
+
+			if tc.RevokedLater {
+				server.Set(Key(sessionID), "1")
+			}
+			if tc.RevokedHere {
+				checker.Revoked(sessionID)
+			}
+
+			revoked, err = checker.IsRevoked(t.Context(), sessionID)
+			if err != nil {
+				t.Fatalf("failed to check session: %v", err)
+			}
+			if revoked != tc.WantSecond {
+				t.Errorf("got %v on the second check, want %v", revoked, tc.WantSecond)
+			}
+		})
+	}
+}
+
+func TestCheckerUnavailable(t *testing.T) {
+	checker, server := newChecker(t, time.Hour)
+	cached, revoked := uuid.New(), uuid.New()
+	server.Set(Key(revoked), "1")
+
+	for _, id := range []uuid.UUID{cached, revoked} {
+		if _, err := checker.IsRevoked(t.Context(), id); err != nil {
+			t.Fatalf("failed to check session: %v", err)
+		}
+	}
+
+	server.Close()
+
+	// The cached answers are served while redis is down, the others fail
+	if got, err := checker.IsRevoked(t.Context(), cached); err != nil || got {
+		t.Errorf("got %v, %v for the cached session, want false", got, err)
+	}
+	if got, err := checker.IsRevoked(t.Context(), revoked); err != nil || !got {
+		t.Errorf("got %v, %v for the revoked session, want true", got, err)
+	}
+	if _, err := checker.IsRevoked(t.Context(), uuid.New()); err == nil {
+		t.Errorf("got nil for an unknown session, want error")
+	}
+	// The tokens without session aren't checked
+	if got, err := checker.IsRevoked(t.Context(), uuid.Nil); err != nil || got {
+		t.Errorf("got %v, %v for no session, want false", got, err)
+	}
+}
@@ -0,0 +1,171 @@
+// Package health reports the dependencies of the services through grpc.health.v1
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Services of the health checks. Liveness is the process itself,
+// readiness is the process with all its dependencies.
+// Every dependency is reported under its own name too
+const (
+	Liveness  = ""
+	Readiness = "readiness"
+)
+
+// Check reports whether the dependency is available
+type Check func(ctx context.Context) error
+
+// Checker checks the dependencies on an interval
+// n' reports them through grpc.health.v1
+type Checker struct {
+	log      *slog.Logger
+	server   *grpchealth.Server
+	checks   map[string]Check
+	services []string
+	interval time.Duration
+	timeout  time.Duration
+
+	// Failed dependencies of the last check, only changes are logged
+	failed map[string]bool
+	stop   chan struct{}
+	once   sync.Once
+
+	// Guards the statuses against the checks still running on shutdown
+	mu       sync.Mutex
+	draining bool
+}
+
+// New creates the checker. The services are ready along with the readiness,
+// so the clients asking for them by name get the same answer
+func New(log *slog.Logger, interval, timeout time.Duration, checks map[string]Check, services ...string) (*Checker, error) {
+	if log == nil {
+		return nil, errors.New("logger is nil")
+	}
+	if interval <= 0 || timeout <= 0 {
+		return nil, errors.New("invalid interval or timeout")
+	}
+
+	server := grpchealth.NewServer()
+	for _, service := range append([]string{Readiness}, services...) {
+		server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+
+	return &Checker{
+		log:      log,
+		server:   server,
+		checks:   checks,
+		services: services,
+		interval: interval,
+		timeout:  timeout,
+		failed:   make(map[string]bool),
+		stop:     make(chan struct{}),
+	}, nil
+}
+
+func (c *Checker) Register(srv *grpc.Server) {
+	healthpb.RegisterHealthServer(srv, c.server)
+}
+
+// Start checks the dependencies right away n' then on the interval
+func (c *Checker) Start() {
+	c.check()
+	go c.watch()
+}
+
+// Shutdown reports the readiness as not serving, so the clients
+// stop sending new requests while the server is draining. The liveness
+// stays serving till the process exits, the drain isn't a reason to kill it.
+// The checks that are still running don't change it anymore
+func (c *Checker) Shutdown() {
+	c.once.Do(func() {
+		close(c.stop)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.draining = true
+		for _, service := range append([]string{Readiness}, c.services...) {
+			c.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+	})
+}
+
+func (c *Checker) watch() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.check()
+		}
+	}
+}
+
+func (c *Checker) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	errs := make(map[string]error, len(c.checks))
+
+	var wg sync.WaitGroup
+	for name, check := range c.checks {
+		wg.Go(func() {
+			err := check(ctx)
+
+			mu.Lock()
+			errs[name] = err
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.draining {
+		return
+	}
+
+	ready := true
+	for name, err := range errs {
+		status := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			ready = false
+		}
+		c.server.SetServingStatus(name, status)
+		c.logChange(name, err)
+	}
+
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if ready {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	for _, service := range append([]string{Readiness}, c.services...) {
+		c.server.SetServingStatus(service, status)
+	}
+}
+
+func (c *Checker) logChange(name string, err error) {
+	if err != nil && !c.failed[name] {
+		c.failed[name] = true
+		c.log.Warn("dependency is unavailable", slog.String("dependency", name), slog.String("error", err.Error()))
+	}
+	if err == nil && c.failed[name] {
+		delete(c.failed, name)
+		c.log.Info("dependency is available again", slog.String("dependency", name))
+	}
+}
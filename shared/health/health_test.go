@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// fakeDependency fails while err is set
+type fakeDependency struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (fd *fakeDependency) check(_ context.Context) error {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+
+	return fd.err
+}
+
+func (fd *fakeDependency) set(err error) {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+
+	fd.err = err
+}
+
+func TestCheckerNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	testCases := []struct {
+		Name     string
+		Log      *slog.Logger
+		Interval time.Duration
+		Timeout  time.Duration
+		WantErr  bool
+	}{
+		{Name: "base", Log: log, Interval: time.Second, Timeout: time.Second},
+		{Name: "nil_log", Log: nil, Interval: time.Second, Timeout: time.Second, WantErr: true},
+		{Name: "zero_interval", Log: log, Interval: 0, Timeout: time.Second, WantErr: true},
+		{Name: "zero_timeout", Log: log, Interval: time.Second, Timeout: 0, WantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := New(tc.Log, tc.Interval, tc.Timeout, nil); (err != nil) != tc.WantErr {
+				t.Errorf("got %v, want error %v", err, tc.WantErr)
+			}
+		})
+	}
+}
+
+func TestCheckerStatus(t *testing.T) {
+	const (
+		serving    = healthpb.HealthCheckResponse_SERVING
+		notServing = healthpb.HealthCheckResponse_NOT_SERVING
+	)
+
+	errDown := errors.New("dependency is down")
+	mongo, redis := &fakeDependency{}, &fakeDependency{}
+	checker, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), time.Hour, time.Second,
+		map[string]Check{"mongo": mongo.check, "redis": redis.check},
+		"sso.v1.SSO",
+	)
+	if err != nil {
+		t.Fatalf("failed to create checker: %v", err)
+	}
+
+	// The steps go in order on the same checker
+	steps := []struct {
+		Name     string
+		MongoErr error
+		RedisErr error
+		// Check isn't run
+		Skip     bool
+		Shutdown bool
+		Want     map[string]healthpb.HealthCheckResponse_ServingStatus
+	}{
+		// Not ready till the first check, but alive
+		{Name: "not_checked", Skip: true, Want: map[string]healthpb.HealthCheckResponse_ServingStatus{
+			Liveness: serving, Readiness: notServing, "sso.v1.SSO": notServing,
+		}},
+		{Name: "ready", Want: map[string]healthpb.HealthCheckResponse_ServingStatus{
+			Liveness: serving, Readiness: serving, "sso.v1.SSO": serving, "mongo": serving, "redis": serving,
+		}},
+		// A failed dependency takes the readiness down, not the liveness
+		{Name: "redis_down", RedisErr: errDown, Want: map[string]healthpb.HealthCheckResponse_ServingStatus{
+			Liveness: serving, Readiness: notServing, "sso.v1.SSO": notServing, "mongo": serving, "redis": notServing,
+		}},
+		{Name: "redis_back", Want: map[string]healthpb.HealthCheckResponse_ServingStatus{
+			Liveness: serving, Readiness: serving, "sso.v1.SSO": serving, "mongo": serving, "redis": serving,
+		}},
+		// The drain keeps the liveness serving, so the process isn't killed
+		{Name: "drain", Skip: true, Shutdown: true, Want: map[string]healthpb.HealthCheckResponse_ServingStatus{
+			Liveness: serving, Readiness: notServing, "sso.v1.SSO": notServing,
+		}},
+		// The checks still running don't make it ready again
+		{Name: "check_after_drain", Want: map[string]healthpb.HealthCheckResponse_ServingStatus{
+			Liveness: serving, Readiness: notServing, "sso.v1.SSO": notServing,
+		}},
+	}
+
+	for _, step := range steps {
+		mongo.set(step.MongoErr)
+		redis.set(step.RedisErr)
+		if step.Shutdown {
+			checker.Shutdown()
+		}
+		if !step.Skip {
+			checker.check()
+		}
+
+		for service, want := range step.Want {
+			resp, err := checker.server.Check(t.Context(), &healthpb.HealthCheckRequest{Service: service})
+			if err != nil {
+				t.Fatalf("%s: failed to check %q: %v", step.Name, service, err)
+			}
+			if got := resp.GetStatus(); got != want {
+				t.Errorf("%s: got %s for %q, want %s", step.Name, got, service, want)
+			}
+		}
+	}
+
+	// Shutdown can be called again
+	checker.Shutdown()
+}
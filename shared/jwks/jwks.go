@@ -0,0 +1,251 @@
+// Package jwks keeps the public keys of sso, the services check its tokens with them
+package jwks
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// The unknown kids don't fetch the keys more often
+	minRefetch   = 30 * time.Second
+	fetchTimeout = 5 * time.Second
+)
+
+// JWK is a public key of sso in the JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// Modulus n' exponent of the rsa key, base64url without padding
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Curve n' public key of the ed25519 one
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// ErrUnknownKey is returned for a kid that sso doesn't have
+var ErrUnknownKey = errors.New("unknown key")
+
+// FetchFunc returns the current keys of sso
+type FetchFunc func(ctx context.Context) ([]JWK, error)
+
+// KeySet is the public keys of sso by their kids. They are fetched lazily
+// n' refetched every refresh or when a token has an unknown kid,
+// so a new key is learned before it signs the tokens. The fetches are
+// at least minRefetch apart, the known keys are used in between
+type KeySet struct {
+	fetch   FetchFunc
+	refresh time.Duration
+
+	// One fetch at a time, it isn't done under mu
+	fetchMu sync.Mutex
+
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+	// The first key of sso, it checks the tokens without kid
+	activeKID string
+	fetchedAt time.Time
+	triedAt   time.Time
+	// The error of the last fetch, while no key is known
+	fetchErr error
+}
+
+func NewKeySet(fetch FetchFunc, refresh time.Duration) *KeySet {
+	return &KeySet{
+		fetch:   fetch,
+		refresh: refresh,
+	}
+}
+
+// Get returns the key of the kid, the first key of sso if there is no kid
+func (ks *KeySet) Get(kid string) (crypto.PublicKey, error) {
+	key, ok, due := ks.peek(kid)
+	if !due {
+		return ks.result(key, ok)
+	}
+
+	// The known key is used while somebody else fetches,
+	// an unknown one waits for the fetch
+	if ok {
+		if !ks.fetchMu.TryLock() {
+			return key, nil
+		}
+	} else {
+		ks.fetchMu.Lock()
+	}
+	defer ks.fetchMu.Unlock()
+
+	// Somebody could fetch them while the lock was awaited
+	if key, ok, due = ks.peek(kid); !due {
+		return ks.result(key, ok)
+	}
+
+	ks.mu.Lock()
+	ks.triedAt = time.Now()
+	ks.mu.Unlock()
+
+	keys, activeKID, err := ks.load()
+
+	ks.mu.Lock()
+	if err == nil {
+		ks.keys = keys
+		ks.activeKID = activeKID
+		ks.fetchedAt = time.Now()
+	}
+	ks.fetchErr = err
+	key, ok = ks.lookup(kid)
+	ks.mu.Unlock()
+
+	return ks.result(key, ok)
+}
+
+// peek returns the known key of the kid n' whether the keys must be fetched
+func (ks *KeySet) peek(kid string) (crypto.PublicKey, bool, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.lookup(kid)
+	stale := !ok || time.Since(ks.fetchedAt) >= ks.refresh
+
+	return key, ok, stale && time.Since(ks.triedAt) >= minRefetch
+}
+
+func (ks *KeySet) result(key crypto.PublicKey, ok bool) (crypto.PublicKey, error) {
+	if ok {
+		return key, nil
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	// Without any key the kid can't be told unknown
+	if len(ks.keys) == 0 && ks.fetchErr != nil {
+		return nil, ks.fetchErr
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// lookup must be called under the lock
+func (ks *KeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" {
+		kid = ks.activeKID
+	}
+
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+// load fetches the keys n' returns them with the active kid
+func (ks *KeySet) load() (map[string]crypto.PublicKey, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	jwks, err := ks.fetch(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks))
+	activeKID := ""
+	for _, jwk := range jwks {
+		key, err := parseJWK(jwk)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid key %s: %w", jwk.Kid, err)
+		}
+
+		keys[jwk.Kid] = key
+		if activeKID == "" {
+			activeKID = jwk.Kid
+		}
+	}
+	if len(keys) == 0 {
+		return nil, "", errors.New("no keys")
+	}
+
+	return keys, activeKID, nil
+}
+
+func parseJWK(jwk JWK) (crypto.PublicKey, error) {
+	var (
+		key crypto.PublicKey
+		err error
+	)
+	switch jwk.Kty {
+	case "RSA":
+		key, err = parseRSA(jwk)
+	case "OKP":
+		key, err = parseEd25519(jwk)
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", jwk.Kty)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The alg is optional, but a key of another type can't sign with it
+	if jwk.Alg != "" && jwk.Alg != MethodOf(key).Alg() {
+		return nil, fmt.Errorf("alg %s of %s key", jwk.Alg, jwk.Kty)
+	}
+
+	return key, nil
+}
+
+func parseRSA(jwk JWK) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	if len(n) == 0 {
+		return nil, errors.New("invalid modulus")
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+func parseEd25519(jwk JWK) (ed25519.PublicKey, error) {
+	if jwk.Crv != "Ed25519" {
+		return nil, fmt.Errorf("unsupported curve: %s", jwk.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil || len(x) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key")
+	}
+
+	return ed25519.PublicKey(x), nil
+}
+
+// MethodOf is the alg of the tokens signed with the key
+func MethodOf(key crypto.PublicKey) jwt.SigningMethod {
+	if _, ok := key.(ed25519.PublicKey); ok {
+		return jwt.SigningMethodEdDSA
+	}
+
+	return jwt.SigningMethodRS256
+}
@@ -0,0 +1,291 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func rsaJWK(t *testing.T, kid string) JWK {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	return JWK{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ed25519JWK(t *testing.T, kid string) JWK {
+	t.Helper()
+
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	return JWK{
+		Kty: "OKP",
+		Kid: kid,
+		Use: "sig",
+		Alg: "EdDSA",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(public),
+	}
+}
+
+func TestParseJWK(t *testing.T) {
+	rsaKey := rsaJWK(t, "rsa")
+	edKey := ed25519JWK(t, "ed")
+
+	with := func(jwk JWK, change func(*JWK)) JWK {
+		change(&jwk)
+		return jwk
+	}
+
+	testCases := []struct {
+		Name       string
+		JWK        JWK
+		WantErr    bool
+		WantMethod string
+	}{
+		{Name: "rsa", JWK: rsaKey, WantMethod: "RS256"},
+		{Name: "ed25519", JWK: edKey, WantMethod: "EdDSA"},
+		{Name: "no_alg", JWK: with(edKey, func(jwk *JWK) { jwk.Alg = "" }), WantMethod: "EdDSA"},
+		{Name: "unknown_kty", JWK: with(rsaKey, func(jwk *JWK) { jwk.Kty = "EC" }), WantErr: true},
+		{Name: "small_exponent", JWK: with(rsaKey, func(jwk *JWK) { jwk.E = "AQ" }), WantErr: true},
+		{Name: "huge_exponent", JWK: with(rsaKey, func(jwk *JWK) { jwk.E = "AQAAAAAAAAAB" }), WantErr: true},
+		{Name: "no_exponent", JWK: with(rsaKey, func(jwk *JWK) { jwk.E = "" }), WantErr: true},
+		{Name: "broken_exponent", JWK: with(rsaKey, func(jwk *JWK) { jwk.E = "!!" }), WantErr: true},
+		{Name: "no_modulus", JWK: with(rsaKey, func(jwk *JWK) { jwk.N = "" }), WantErr: true},
+		{Name: "wrong_curve", JWK: with(edKey, func(jwk *JWK) { jwk.Crv = "X25519" }), WantErr: true},
+		{Name: "short_key", JWK: with(edKey, func(jwk *JWK) { jwk.X = jwk.X[:10] }), WantErr: true},
+		// The alg must match the type of the key
+		{Name: "rsa_as_eddsa", JWK: with(rsaKey, func(jwk *JWK) { jwk.Alg = "EdDSA" }), WantErr: true},
+		{Name: "ed25519_as_rs256", JWK: with(edKey, func(jwk *JWK) { jwk.Alg = "RS256" }), WantErr: true},
+		{Name: "hmac", JWK: with(rsaKey, func(jwk *JWK) { jwk.Alg = "HS256" }), WantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			key, err := parseJWK(tc.JWK)
+			if (err != nil) != tc.WantErr {
+				t.Fatalf("got %v, want error %v", err, tc.WantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := MethodOf(key).Alg(); got != tc.WantMethod {
+				t.Errorf("got %s, want %s", got, tc.WantMethod)
+			}
+		})
+	}
+}
+
+// fakeSSO serves the keys, or fails while err is set
+type fakeSSO struct {
+	mu    sync.Mutex
+	keys  []JWK
+	err   error
+	calls atomic.Int32
+}
+
+func (fs *fakeSSO) fetch(_ context.Context) ([]JWK, error) {
+	fs.calls.Add(1)
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return fs.keys, fs.err
+}
+
+func (fs *fakeSSO) set(keys []JWK, err error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.keys, fs.err = keys, err
+}
+
+// allowRefetch acts as if minRefetch passed since the last fetch
+func allowRefetch(ks *KeySet) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.triedAt = time.Now().Add(-minRefetch)
+}
+
+func TestKeySetGet(t *testing.T) {
+	first, second := ed25519JWK(t, "first"), rsaJWK(t, "second")
+
+	testCases := []struct {
+		Name string
+		KID  string
+		// The alg tells the two keys apart
+		WantAlg   string
+		WantErr   error
+		WantCalls int32
+	}{
+		{Name: "kid", KID: "second", WantAlg: "RS256", WantCalls: 1},
+		// The tokens without kid are checked by the first key of sso
+		{Name: "no_kid", KID: "", WantAlg: "EdDSA", WantCalls: 1},
+		{Name: "unknown_kid", KID: "unknown", WantErr: ErrUnknownKey, WantCalls: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			sso := &fakeSSO{keys: []JWK{first, second}}
+			ks := NewKeySet(sso.fetch, time.Hour)
+
+			for range 3 {
+				key, err := ks.Get(tc.KID)
+				if !errors.Is(err, tc.WantErr) {
+					t.Fatalf("got %v, want %v", err, tc.WantErr)
+				}
+				if err == nil && MethodOf(key).Alg() != tc.WantAlg {
+					t.Errorf("got %s key, want %s", MethodOf(key).Alg(), tc.WantAlg)
+				}
+			}
+
+			if got := sso.calls.Load(); got != tc.WantCalls {
+				t.Errorf("got %d fetches, want %d", got, tc.WantCalls)
+			}
+		})
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	old, next := ed25519JWK(t, "old"), ed25519JWK(t, "next")
+	sso := &fakeSSO{keys: []JWK{old}}
+	ks := NewKeySet(sso.fetch, time.Hour)
+
+	if _, err := ks.Get("old"); err != nil {
+		t.Fatalf("failed to get key: %v", err)
+	}
+
+	// The new key is published before it signs, the old one is kept for its tokens
+	sso.set([]JWK{next, old}, nil)
+
+	// The unknown kids don't refetch until minRefetch passes
+	if _, err := ks.Get("next"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("got %v, want %v", err, ErrUnknownKey)
+	}
+	if got := sso.calls.Load(); got != 1 {
+		t.Fatalf("got %d fetches, want 1", got)
+	}
+
+	allowRefetch(ks)
+	for _, kid := range []string{"next", "old", "next"} {
+		if _, err := ks.Get(kid); err != nil {
+			t.Errorf("got %v for %s, want nil", err, kid)
+		}
+	}
+	if got := sso.calls.Load(); got != 2 {
+		t.Errorf("got %d fetches, want 2", got)
+	}
+
+	// The tokens without kid follow the new first key
+	key, err := ks.Get("")
+	if err != nil {
+		t.Fatalf("failed to get key: %v", err)
+	}
+	nextKey, _ := parseJWK(next)
+	if !nextKey.(ed25519.PublicKey).Equal(key) {
+		t.Errorf("got the old key for no kid, want the new one")
+	}
+}
+
+func TestKeySetUnavailable(t *testing.T) {
+	errDown := errors.New("sso is down")
+	first := ed25519JWK(t, "first")
+
+	t.Run("never_fetched", func(t *testing.T) {
+		t.Parallel()
+
+		sso := &fakeSSO{err: errDown}
+		ks := NewKeySet(sso.fetch, time.Hour)
+
+		for range 100 {
+			if _, err := ks.Get("first"); !errors.Is(err, errDown) {
+				t.Fatalf("got %v, want %v", err, errDown)
+			}
+		}
+		if got := sso.calls.Load(); got != 1 {
+			t.Errorf("got %d fetches, want 1", got)
+		}
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		t.Parallel()
+
+		sso := &fakeSSO{keys: []JWK{first}}
+		// The keys are stale right after the fetch
+		ks := NewKeySet(sso.fetch, time.Nanosecond)
+		if _, err := ks.Get("first"); err != nil {
+			t.Fatalf("failed to get key: %v", err)
+		}
+
+		sso.set(nil, errDown)
+		allowRefetch(ks)
+
+		var wg sync.WaitGroup
+		for range 100 {
+			wg.Go(func() {
+				if _, err := ks.Get("first"); err != nil {
+					t.Errorf("got %v, want the known key", err)
+				}
+				if _, err := ks.Get("unknown"); !errors.Is(err, ErrUnknownKey) {
+					t.Errorf("got %v, want %v", err, ErrUnknownKey)
+				}
+			})
+		}
+		wg.Wait()
+
+		// One refetch for all the calls, the known keys are used after it failed
+		if got := sso.calls.Load(); got != 2 {
+			t.Errorf("got %d fetches, want 2", got)
+		}
+	})
+
+	t.Run("invalid_keys", func(t *testing.T) {
+		t.Parallel()
+
+		broken := first
+		broken.Crv = "X25519"
+		sso := &fakeSSO{keys: []JWK{first}}
+		ks := NewKeySet(sso.fetch, time.Hour)
+		if _, err := ks.Get("first"); err != nil {
+			t.Fatalf("failed to get key: %v", err)
+		}
+
+		// A broken key doesn't drop the known ones
+		sso.set([]JWK{broken}, nil)
+		allowRefetch(ks)
+		if _, err := ks.Get("unknown"); !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("got %v, want %v", err, ErrUnknownKey)
+		}
+		if _, err := ks.Get("first"); err != nil {
+			t.Errorf("got %v, want the known key", err)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+// Package ratelimit limits the calls with token buckets kept in redis
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The bucket is refilled by the time passed since the last call.
+// The time is taken from redis, so the clocks of the replicas don't matter.
+// It returns {allowed, remaining tokens, ms to retry, ms to refill}
+var takeToken = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+local refill = math.ceil((capacity - tokens) / rate)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], refill + 1000)
+
+return {allowed, math.floor(tokens), retry, refill}
+`)
+
+// Rule is the limit of the calls by its name, the grpc method or the route
+type Rule struct {
+	Name string
+	// How the clients are told apart, up to the service
+	Key string
+	// Calls allowed per period on average
+	Rate   int
+	Period time.Duration
+	// Calls allowed at once
+	Burst int
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Time until the bucket is full again
+	Reset time.Duration
+	// Time until the next call is allowed, if this one isn't
+	RetryAfter time.Duration
+	Policy     string
+}
+
+// Headers are the standard RateLimit-* n' Retry-After ones
+func (r Result) Headers() map[string]string {
+	headers := map[string]string{
+		"RateLimit-Limit":     strconv.Itoa(r.Limit),
+		"RateLimit-Remaining": strconv.Itoa(r.Remaining),
+		"RateLimit-Reset":     strconv.Itoa(seconds(r.Reset)),
+		"RateLimit-Policy":    r.Policy,
+	}
+	if !r.Allowed {
+		headers["Retry-After"] = strconv.Itoa(seconds(r.RetryAfter))
+	}
+
+	return headers
+}
+
+// Limiter keeps a token bucket per rule n' client
+type Limiter struct {
+	client *redis.Client
+	rules  map[string]Rule
+}
+
+func New(client *redis.Client, rules []Rule) (*Limiter, error) {
+	if client == nil {
+		return nil, errors.New("redis client is nil")
+	}
+
+	byName := make(map[string]Rule, len(rules))
+	for _, rule := range rules {
+		if rule.Rate <= 0 || rule.Period <= 0 || rule.Burst <= 0 {
+			return nil, fmt.Errorf("invalid rule: %s", rule.Name)
+		}
+		byName[rule.Name] = rule
+	}
+
+	return &Limiter{
+		client: client,
+		rules:  byName,
+	}, nil
+}
+
+// Rule returns the limit of the name, if it's limited
+func (l *Limiter) Rule(name string) (Rule, bool) {
+	rule, ok := l.rules[name]
+	return rule, ok
+}
+
+// Allow takes a token from the bucket of the client
+func (l *Limiter) Allow(ctx context.Context, rule Rule, client string) (Result, error) {
+	rate := float64(rule.Rate) / float64(rule.Period.Milliseconds())
+
+	values, err := takeToken.Run(ctx, l.client,
+		[]string{"ratelimit:" + rule.Name + ":" + client},
+		rule.Burst,
+		strconv.FormatFloat(rate, 'g', -1, 64),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take token: %w", err)
+	}
+	if len(values) != 4 {
+		return Result{}, fmt.Errorf("failed to take token: unexpected reply %v", values)
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      rule.Burst,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		Reset:      time.Duration(values[3]) * time.Millisecond,
+		Policy:     fmt.Sprintf("%d;w=%d", rule.Burst, seconds(rule.Window())),
+	}, nil
+}
+
+// Window is the time the bucket is refilled in from empty.
+// The headers advertise the burst per window, the same quota as RateLimit-Limit
+func (r Rule) Window() time.Duration {
+	return r.Period * time.Duration(r.Burst) / time.Duration(r.Rate)
+}
+
+// seconds rounds up, so the client doesn't come back too early
+func seconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
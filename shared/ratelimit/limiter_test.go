@@ -0,0 +1,219 @@
+package ratelimit
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestResultHeaders(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Result Result
+		Want   map[string]string
+	}{
+		{Name: "allowed", Result: Result{
+			Allowed:   true,
+			Limit:     10,
+			Remaining: 9,
+			Reset:     1500 * time.Millisecond,
+			Policy:    "10;w=60",
+		}, Want: map[string]string{
+			"RateLimit-Limit":     "10",
+			"RateLimit-Remaining": "9",
+			"RateLimit-Reset":     "2",
+			"RateLimit-Policy":    "10;w=60",
+		}},
+		{Name: "full_bucket", Result: Result{
+			Allowed:   true,
+			Limit:     5,
+			Remaining: 5,
+			Reset:     0,
+			Policy:    "5;w=1",
+		}, Want: map[string]string{
+			"RateLimit-Limit":     "5",
+			"RateLimit-Remaining": "5",
+			"RateLimit-Reset":     "0",
+			"RateLimit-Policy":    "5;w=1",
+		}},
+		{Name: "denied", Result: Result{
+			Allowed:    false,
+			Limit:      10,
+			Remaining:  0,
+			Reset:      6 * time.Second,
+			RetryAfter: 1 * time.Millisecond,
+			Policy:     "10;w=60",
+		}, Want: map[string]string{
+			"RateLimit-Limit":     "10",
+			"RateLimit-Remaining": "0",
+			"RateLimit-Reset":     "6",
+			"RateLimit-Policy":    "10;w=60",
+			// Rounded up, so the client doesn't come back too early
+			"Retry-After": "1",
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.Result.Headers(); !maps.Equal(got, tc.Want) {
+				t.Errorf("got %v, want %v", got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestSeconds(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Duration time.Duration
+		Want     int
+	}{
+		{Name: "zero", Duration: 0, Want: 0},
+		{Name: "millisecond", Duration: time.Millisecond, Want: 1},
+		{Name: "second", Duration: time.Second, Want: 1},
+		{Name: "over_second", Duration: time.Second + time.Nanosecond, Want: 2},
+		{Name: "minute", Duration: time.Minute, Want: 60},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := seconds(tc.Duration); got != tc.Want {
+				t.Errorf("got %d, want %d", got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	client := redis.NewClient(&redis.Options{})
+	t.Cleanup(func() { client.Close() })
+
+	testCases := []struct {
+		Name    string
+		Client  *redis.Client
+		Rules   []Rule
+		WantErr bool
+	}{
+		{Name: "base", Client: client, Rules: []Rule{{Name: "/sso.v1.SSO/Login", Rate: 5, Period: time.Minute, Burst: 5}}},
+		{Name: "no_rules", Client: client, Rules: nil},
+		{Name: "nil_client", Client: nil, Rules: nil, WantErr: true},
+		{Name: "zero_rate", Client: client, Rules: []Rule{{Name: "login", Rate: 0, Period: time.Minute, Burst: 5}}, WantErr: true},
+		{Name: "zero_period", Client: client, Rules: []Rule{{Name: "login", Rate: 5, Period: 0, Burst: 5}}, WantErr: true},
+		{Name: "zero_burst", Client: client, Rules: []Rule{{Name: "login", Rate: 5, Period: time.Minute, Burst: 0}}, WantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter, err := New(tc.Client, tc.Rules)
+			if (err != nil) != tc.WantErr {
+				t.Fatalf("got %v, want error %v", err, tc.WantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, rule := range tc.Rules {
+				if got, ok := limiter.Rule(rule.Name); !ok || got != rule {
+					t.Errorf("got %v, want %v", got, rule)
+				}
+			}
+			if _, ok := limiter.Rule("unknown"); ok {
+				t.Errorf("got rule for unknown name")
+			}
+		})
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	// 2 calls per second on average, 3 at once
+	rule := Rule{Name: "login", Rate: 2, Period: time.Second, Burst: 3}
+	limiter, err := New(client, []Rule{rule})
+	if err != nil {
+		t.Fatalf("failed to create limiter: %v", err)
+	}
+
+	now := time.Now()
+	server.SetTime(now)
+
+	// The steps go in order on the same bucket, the time is the one of redis
+	testCases := []struct {
+		Name           string
+		Client         string
+		Elapse         time.Duration
+		WantAllowed    bool
+		WantRemaining  int
+		WantRetryAfter time.Duration
+		WantReset      time.Duration
+	}{
+		{Name: "first", Client: "user", WantAllowed: true, WantRemaining: 2, WantReset: 500 * time.Millisecond},
+		{Name: "second", Client: "user", WantAllowed: true, WantRemaining: 1, WantReset: time.Second},
+		{Name: "burst", Client: "user", WantAllowed: true, WantRemaining: 0, WantReset: 1500 * time.Millisecond},
+		{Name: "denied", Client: "user", WantAllowed: false, WantRemaining: 0, WantRetryAfter: 500 * time.Millisecond, WantReset: 1500 * time.Millisecond},
+		// Another client has its own bucket
+		{Name: "other_client", Client: "other", WantAllowed: true, WantRemaining: 2, WantReset: 500 * time.Millisecond},
+		{Name: "partly_refilled", Client: "user", Elapse: 250 * time.Millisecond, WantAllowed: false, WantRemaining: 0, WantRetryAfter: 250 * time.Millisecond, WantReset: 1250 * time.Millisecond},
+		{Name: "refilled", Client: "user", Elapse: 250 * time.Millisecond, WantAllowed: true, WantRemaining: 0, WantReset: 1500 * time.Millisecond},
+		// The bucket doesn't grow past the burst
+		{Name: "full", Client: "user", Elapse: time.Minute, WantAllowed: true, WantRemaining: 2, WantReset: 500 * time.Millisecond},
+	}
+
+	for _, tc := range testCases {
+		now = now.Add(tc.Elapse)
+		server.SetTime(now)
+
+		got, err := limiter.Allow(t.Context(), rule, tc.Client)
+		if err != nil {
+			t.Fatalf("%s: failed to allow: %v", tc.Name, err)
+		}
+		if got.Allowed != tc.WantAllowed || got.Remaining != tc.WantRemaining {
+			t.Errorf("%s: got allowed %v with %d left, want %v with %d", tc.Name, got.Allowed, got.Remaining, tc.WantAllowed, tc.WantRemaining)
+		}
+		if got.RetryAfter != tc.WantRetryAfter || got.Reset != tc.WantReset {
+			t.Errorf("%s: got retry %s n' reset %s, want %s n' %s", tc.Name, got.RetryAfter, got.Reset, tc.WantRetryAfter, tc.WantReset)
+		}
+
+		// The limit n' the policy advertise the same quota: the burst per 1.5s window
+		headers := got.Headers()
+		if headers["RateLimit-Limit"] != "3" || headers["RateLimit-Policy"] != "3;w=2" {
+			t.Errorf("%s: got limit %s n' policy %s, want 3 n' 3;w=2", tc.Name, headers["RateLimit-Limit"], headers["RateLimit-Policy"])
+		}
+		if _, ok := headers["Retry-After"]; ok == tc.WantAllowed {
+			t.Errorf("%s: got Retry-After %v, want it on the denied calls only", tc.Name, ok)
+		}
+	}
+}
+
+func TestRuleWindow(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Rule Rule
+		Want time.Duration
+	}{
+		{Name: "burst_of_rate", Rule: Rule{Rate: 5, Period: time.Minute, Burst: 5}, Want: time.Minute},
+		{Name: "small_burst", Rule: Rule{Rate: 10, Period: time.Minute, Burst: 2}, Want: 12 * time.Second},
+		{Name: "big_burst", Rule: Rule{Rate: 2, Period: time.Second, Burst: 3}, Want: 1500 * time.Millisecond},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.Rule.Window(); got != tc.Want {
+				t.Errorf("got %s, want %s", got, tc.Want)
+			}
+		})
+	}
+}
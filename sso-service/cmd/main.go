@@ -10,6 +10,15 @@ import (
 )
 
 func main() {
+	// sso keys generate [rsa | ed25519] | list | promote <kid>
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		if err := app.Keys(os.Args[2:], os.Stdout); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	app, cleanup, err := app.New()
 	if err != nil {
 		slog.Error("failed to setup app", slog.String("error", err.Error()))
@@ -1,30 +1,54 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
+	"time"
 
+	"github.com/devathh/coderun/shared/health"
+	sharedmetrics "github.com/devathh/coderun/shared/metrics"
+	"github.com/devathh/coderun/shared/ratelimit"
+	"github.com/devathh/coderun/shared/tracing"
 	ssopb "github.com/devathh/coderun/sso-service/api/sso/v1"
 	"github.com/devathh/coderun/sso-service/internal/application/services"
+	"github.com/devathh/coderun/sso-service/internal/domain/auth"
 	jwt "github.com/devathh/coderun/sso-service/internal/infrastructure/auth"
 	rediscache "github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis"
 	authredis "github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis/auth"
+	denylistredis "github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis/denylist"
+	tokenredis "github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis/token"
 	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
 	server "github.com/devathh/coderun/sso-service/internal/infrastructure/grpc"
 	"github.com/devathh/coderun/sso-service/internal/infrastructure/grpc/handlers"
 	"github.com/devathh/coderun/sso-service/internal/infrastructure/grpc/interceptors"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/mailer"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/metrics"
 	mongodb "github.com/devathh/coderun/sso-service/internal/infrastructure/persistence/mongo"
 	usermongo "github.com/devathh/coderun/sso-service/internal/infrastructure/persistence/mongo/user"
 	"github.com/devathh/coderun/sso-service/pkg/log"
 	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 )
 
+const (
+	metricsShutdownTimeout = 5 * time.Second
+	tracingFlushTimeout    = 5 * time.Second
+	// The queued emails are sent within it
+	mailShutdownTimeout = 30 * time.Second
+)
+
 type App struct {
-	log *slog.Logger
-	srv *server.Server
+	log     *slog.Logger
+	srv     *server.Server
+	service services.SSOService
+	health  *health.Checker
+	// Nil if the metrics are disabled
+	metrics *sharedmetrics.Server
 }
 
 func New() (*App, func(), error) {
@@ -45,11 +69,33 @@ func New() (*App, func(), error) {
 
 	log.Info("config was loaded", slog.Any("server", cfg.Server), slog.Any("app", cfg.App))
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Enable:      cfg.Tracing.Enable,
+		Stdout:      cfg.Tracing.Exporter == config.ExporterStdout,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		Name:        cfg.App.Name,
+		Version:     cfg.App.Version,
+		Env:         cfg.App.Env,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to setup tracing: %w", err)
+	}
+
 	db, err := connectMongo(cfg)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	backfilled, err := mongodb.BackfillEmailVerified(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	if backfilled > 0 {
+		log.Info("users registered before the email verification are marked verified", slog.Int64("users", backfilled))
+	}
+
 	userMongo, err := usermongo.New(log, db)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create user mongo repository: %w", err)
@@ -70,27 +116,80 @@ func New() (*App, func(), error) {
 		return nil, nil, fmt.Errorf("failed to load jwt manager: %w", err)
 	}
 
-	service, err := services.New(cfg, log, userMongo, authCache, jwtMngr)
+	// The interface stays nil if the denylist is disabled
+	var denylist auth.Denylist
+	if cfg.Denylist.Enable {
+		denylist, err = denylistredis.New(cfg, redisClient)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load denylist: %w", err)
+		}
+	}
+
+	tokens, err := tokenredis.New(redisClient)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load token redis repository: %w", err)
+	}
+
+	mail, err := mailer.New(cfg, log)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load mailer: %w", err)
+	}
+
+	service, err := services.New(cfg, log, userMongo, authCache, jwtMngr, denylist, tokens, mail)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to load service: %w", err)
 	}
 	api := handlers.New(service)
 
-	packInterceptors := interceptors.New(log, jwtMngr, map[string]bool{
-		ssopb.SSO_UpdateUser_FullMethodName: true,
-		ssopb.SSO_GetSelf_FullMethodName:    true,
+	packInterceptors := interceptors.New(log, jwtMngr, denylist, map[string]bool{
+		ssopb.SSO_UpdateUser_FullMethodName:    true,
+		ssopb.SSO_GetSelf_FullMethodName:       true,
+		ssopb.SSO_LogoutAll_FullMethodName:     true,
+		ssopb.SSO_ListSessions_FullMethodName:  true,
+		ssopb.SSO_RevokeSession_FullMethodName: true,
 	})
 	pack := []grpc.UnaryServerInterceptor{
+		interceptors.MetricsInterceptor(),
+		interceptors.DeviceInterceptor(cfg),
 		packInterceptors.AuthInterceptor(),
 	}
 
-	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
-		pack...,
-	))
+	if cfg.RateLimit.Enable {
+		limiter, err := ratelimit.New(redisClient, rateLimitRules(cfg))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create rate limiter: %w", err)
+		}
+		pack = append(pack, interceptors.NewRateLimiter(cfg, log, limiter).RateLimitInterceptor())
+	}
+
+	grpcServer := grpc.NewServer(
+		// Continues the traces of the callers from the metadata
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			pack...,
+		),
+	)
 	ssopb.RegisterSSOServer(grpcServer, api)
 
+	checker, err := health.New(log, cfg.Server.Health.Interval, cfg.Server.Health.Timeout, map[string]health.Check{
+		"mongo": mongodb.Ping(db),
+		"redis": rediscache.Ping(redisClient),
+	}, ssopb.SSO_ServiceDesc.ServiceName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create health checker: %w", err)
+	}
+	checker.Register(grpcServer)
+
 	server := server.New(cfg, grpcServer)
 
+	var metricsServer *sharedmetrics.Server
+	if cfg.Server.Metrics.Enable {
+		metricsServer = sharedmetrics.NewServer(
+			net.JoinHostPort(cfg.Server.Metrics.Host, fmt.Sprintf("%d", cfg.Server.Metrics.Port)),
+			metrics.Registry,
+		)
+	}
+
 	cleanup := func() {
 		if err := mongodb.Close(db); err != nil {
 			log.Error("failed to close mongo connection", slog.String("error", err.Error()))
@@ -101,22 +200,61 @@ func New() (*App, func(), error) {
 		}
 
 		log.Info("all the connections were closed")
+
+		ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+		defer cancel()
+
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("failed to flush spans", slog.String("error", err.Error()))
+		}
 	}
 
 	return &App{
-		log: log,
-		srv: server,
+		log:     log,
+		srv:     server,
+		service: service,
+		health:  checker,
+		metrics: metricsServer,
 	}, cleanup, nil
 }
 
 func (a *App) Start() error {
+	if a.metrics != nil {
+		go func() {
+			a.log.Info("metrics server is running")
+			if err := a.metrics.Start(); err != nil {
+				a.log.Error("metrics server is stopped", slog.String("error", err.Error()))
+			}
+		}()
+	}
+
+	a.health.Start()
+
 	a.log.Info("server is running")
 	return a.srv.Start()
 }
 
 func (a *App) Shutdown() {
 	a.log.Info("server shutdown")
+	a.health.Shutdown()
 	a.srv.Shutdown()
+
+	// The requests are done, their emails are sent before the connections close
+	ctx, cancel := context.WithTimeout(context.Background(), mailShutdownTimeout)
+	defer cancel()
+
+	if err := a.service.Shutdown(ctx); err != nil {
+		a.log.Warn("failed to send queued emails", slog.String("error", err.Error()))
+	}
+
+	if a.metrics != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+
+		if err := a.metrics.Shutdown(ctx); err != nil {
+			a.log.Warn("failed to stop metrics server", slog.String("error", err.Error()))
+		}
+	}
 }
 
 func connectMongo(cfg *config.Config) (*mongo.Client, error) {
@@ -130,3 +268,18 @@ func connectMongo(cfg *config.Config) (*mongo.Client, error) {
 
 	return db, nil
 }
+
+func rateLimitRules(cfg *config.Config) []ratelimit.Rule {
+	rules := make([]ratelimit.Rule, 0, len(cfg.RateLimit.Rules))
+	for _, rule := range cfg.RateLimit.Rules {
+		rules = append(rules, ratelimit.Rule{
+			Name:   rule.Method,
+			Key:    rule.Key,
+			Rate:   rule.Rate,
+			Period: rule.Period,
+			Burst:  rule.Burst,
+		})
+	}
+
+	return rules
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	jwt "github.com/devathh/coderun/sso-service/internal/infrastructure/auth"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	"github.com/joho/godotenv"
+)
+
+const keysUsage = "usage: keys generate [rsa | ed25519] | list | promote <kid>"
+
+// Keys manages the signing keys of secrets.jwt.keys-dir without starting the server.
+// A new key isn't active: it's promoted once the services fetched it
+func Keys(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(keysUsage)
+	}
+
+	if err := godotenv.Load(".env"); err != nil {
+		return fmt.Errorf("failed to load .env: %w", err)
+	}
+
+	cfg, err := config.New(os.Getenv("APP_CONFIG_PATH"))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	switch args[0] {
+	case "generate":
+		kind := jwt.KeyRSA
+		if len(args) > 1 {
+			kind = args[1]
+		}
+
+		key, err := jwt.GenerateKey(cfg, kind)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "generated %s key %s\n", key.Method().Alg(), key.KID)
+		return printKeys(cfg, out)
+	case "list":
+		return printKeys(cfg, out)
+	case "promote":
+		if len(args) < 2 {
+			return errors.New(keysUsage)
+		}
+
+		if err := jwt.PromoteKey(cfg, args[1]); err != nil {
+			return err
+		}
+		return printKeys(cfg, out)
+	}
+
+	return errors.New(keysUsage)
+}
+
+func printKeys(cfg *config.Config, out io.Writer) error {
+	keys, err := jwt.LoadKeys(cfg)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KID\tALG\tFINGERPRINT\tSTATUS\tPATH")
+	for i, key := range keys {
+		state := "standby"
+		switch {
+		case i == 0:
+			state = "active"
+		case key.Private == nil:
+			state = "verify-only"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", key.KID, key.Method().Alg(), key.Fingerprint(), state, key.Path)
+	}
+
+	return w.Flush()
+}
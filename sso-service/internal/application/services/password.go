@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	ssopb "github.com/devathh/coderun/sso-service/api/sso/v1"
+	"github.com/devathh/coderun/sso-service/internal/domain/auth"
+	"github.com/devathh/coderun/sso-service/internal/domain/mail"
+	"github.com/devathh/coderun/sso-service/internal/domain/user"
+	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
+)
+
+// RequestPasswordReset doesn't tell whether the user exists
+func (s *ssoService) RequestPasswordReset(ctx context.Context, req *ssopb.RequestPasswordResetRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if req == nil {
+		return customerrors.ErrNilRequest
+	}
+
+	email := strings.TrimSpace(req.GetEmail())
+	if email == "" {
+		return customerrors.ErrInvalidRequest
+	}
+
+	s.mailUser(ctx, email, s.sendPasswordReset)
+
+	return nil
+}
+
+// ResetPassword sets the new password n' revokes every session of the user,
+// the ones of whoever knew the old password too
+func (s *ssoService) ResetPassword(ctx context.Context, req *ssopb.ResetPasswordRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if req == nil {
+		return customerrors.ErrNilRequest
+	}
+
+	// Before the token is consumed, so it can be used again with a valid password
+	password, err := user.NewPassword(req.GetNewPassword())
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidPassword) {
+			return err
+		}
+
+		s.log.Error("failed to hash password", slog.String("error", err.Error()))
+		return customerrors.ErrInternalServer
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, s.cfg.Server.Timeout)
+	defer cancel()
+
+	s.log.Debug("start to consume password reset token")
+	userID, err := s.tokens.ConsumeToken(ctxTimeout, auth.TokenPasswordReset, strings.TrimSpace(req.GetToken()))
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidToken) {
+			return err
+		}
+
+		s.log.Error("failed to consume password reset token", slog.String("error", err.Error()))
+		return customerrors.ErrInternalServer
+	}
+
+	if err := s.userMongo.SetPassword(ctxTimeout, userID.String(), password); err != nil {
+		// The user is deleted since the token was sent
+		if errors.Is(err, customerrors.ErrUserDoesntExist) {
+			return customerrors.ErrInvalidToken
+		}
+
+		s.log.Error("failed to set password", slog.String("error", err.Error()))
+		return customerrors.ErrInternalServer
+	}
+
+	s.log.Debug("start to delete all the user's sessions", slog.String("id", userID.String()))
+	sessionIDs, err := s.authCache.DeleteAllSessions(ctxTimeout, userID)
+	if err != nil {
+		// The password is changed anyway, LogoutAll can revoke the sessions later
+		s.log.Error("failed to delete sessions", slog.String("error", err.Error()))
+		return customerrors.ErrInternalServer
+	}
+	s.revokeAccess(ctxTimeout, sessionIDs...)
+
+	s.log.Info("password is reset", slog.String("user_id", userID.String()))
+	return nil
+}
+
+// sendPasswordReset emails a new token to the user, the previous one is revoked
+func (s *ssoService) sendPasswordReset(ctx context.Context, user *user.User) error {
+	ttl := s.cfg.PasswordReset.TTL
+	return s.sendToken(ctx, auth.TokenPasswordReset, ttl, user, func(token string) mail.Message {
+		return mail.Message{
+			To:      string(user.Email()),
+			Subject: "Reset your password",
+			Body: fmt.Sprintf(
+				"Hi, %s!\n\nTo set a new password on coderun, follow the link or enter the token:\n\n%s\n\n"+
+					"It expires in %s. If you didn't ask for it, ignore this email.\n",
+				user.Username(), mailLink(s.cfg.PasswordReset.URL, token), ttl,
+			),
+		}
+	})
+}
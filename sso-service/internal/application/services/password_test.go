@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	ssopb "github.com/devathh/coderun/sso-service/api/sso/v1"
+	"github.com/devathh/coderun/sso-service/internal/domain/auth"
+	"github.com/devathh/coderun/sso-service/internal/domain/user"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis/auth"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis/denylist"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis/token"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakePasswords is the mongo of the users, it keeps the passwords set by id
+type fakePasswords struct {
+	user.MongoRepository
+
+	mu        sync.Mutex
+	passwords map[string]user.Password
+	// The user is deleted, SetPassword doesn't find it
+	deleted bool
+}
+
+func (fp *fakePasswords) SetPassword(_ context.Context, id string, password user.Password) error {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	if fp.deleted {
+		return customerrors.ErrUserDoesntExist
+	}
+
+	fp.passwords[id] = password
+	return nil
+}
+
+func (fp *fakePasswords) get(id string) (user.Password, bool) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	password, ok := fp.passwords[id]
+	return password, ok
+}
+
+// resetFixture is the service on miniredis with a reset token of the user
+type resetFixture struct {
+	service  *ssoService
+	users    *fakePasswords
+	denylist *denylistredis.Denylist
+	userID   uuid.UUID
+	token    string
+	// The sessions of the user n' of another one
+	sessions []uuid.UUID
+	other    uuid.UUID
+}
+
+func newResetFixture(t *testing.T) *resetFixture {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := &config.Config{}
+	cfg.Server.Timeout = time.Minute
+	cfg.Secrets.Redis.RefreshTTL = time.Hour
+	cfg.Secrets.JWT.TTL = time.Hour
+	cfg.Denylist.Enable = true
+
+	authCache, err := authredis.New(cfg, client)
+	if err != nil {
+		t.Fatalf("failed to create auth repository: %v", err)
+	}
+	deny, err := denylistredis.New(cfg, client)
+	if err != nil {
+		t.Fatalf("failed to create denylist: %v", err)
+	}
+	tokens, err := tokenredis.New(client)
+	if err != nil {
+		t.Fatalf("failed to create token repository: %v", err)
+	}
+
+	users := &fakePasswords{passwords: map[string]user.Password{}}
+	service, err := New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), users, authCache, nil, deny, tokens, nil)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	rf := &resetFixture{
+		service:  service.(*ssoService),
+		users:    users,
+		denylist: deny,
+		userID:   uuid.New(),
+		token:    "reset_token",
+	}
+	if err := tokens.SaveToken(t.Context(), auth.TokenPasswordReset, rf.token, rf.userID, time.Hour); err != nil {
+		t.Fatalf("failed to save token: %v", err)
+	}
+
+	login := func(userID uuid.UUID, refresh string) uuid.UUID {
+		session, err := auth.NewSession(userID, "user@coderun.dev", true, auth.Device{IP: "127.0.0.1"})
+		if err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+		if err := authCache.CreateSession(t.Context(), refresh, session); err != nil {
+			t.Fatalf("failed to save session: %v", err)
+		}
+
+		return session.ID()
+	}
+	rf.sessions = []uuid.UUID{login(rf.userID, "rt_first"), login(rf.userID, "rt_second")}
+	rf.other = login(uuid.New(), "rt_other")
+
+	return rf
+}
+
+func (rf *resetFixture) reset(ctx context.Context, token, password string) error {
+	return rf.service.ResetPassword(ctx, &ssopb.ResetPasswordRequest{Token: token, NewPassword: password})
+}
+
+func (rf *resetFixture) isRevoked(t *testing.T, sessionID uuid.UUID) bool {
+	t.Helper()
+
+	revoked, err := rf.denylist.IsRevoked(t.Context(), &auth.CoderunClaims{SessionID: sessionID})
+	if err != nil {
+		t.Fatalf("failed to check session: %v", err)
+	}
+
+	return revoked
+}
+
+func TestResetPassword(t *testing.T) {
+	type step struct {
+		Token    string
+		Password string
+		WantErr  error
+	}
+
+	testCases := []struct {
+		Name    string
+		Deleted bool
+		// The steps go in order on the same token
+		Steps     []step
+		WantReset bool
+	}{
+		{
+			Name:      "reset",
+			Steps:     []step{{Token: "reset_token", Password: "new_strong_password"}},
+			WantReset: true,
+		},
+		{
+			Name: "single_use",
+			Steps: []step{
+				{Token: "reset_token", Password: "new_strong_password"},
+				{Token: "reset_token", Password: "another_password", WantErr: customerrors.ErrInvalidToken},
+			},
+			WantReset: true,
+		},
+		// An invalid password doesn't consume the token
+		{
+			Name: "invalid_password",
+			Steps: []step{
+				{Token: "reset_token", Password: "short", WantErr: customerrors.ErrInvalidPassword},
+				{Token: "reset_token", Password: "new_strong_password"},
+			},
+			WantReset: true,
+		},
+		{
+			Name:  "unknown_token",
+			Steps: []step{{Token: "unknown_token", Password: "new_strong_password", WantErr: customerrors.ErrInvalidToken}},
+		},
+		{
+			Name:    "deleted_user",
+			Deleted: true,
+			Steps:   []step{{Token: "reset_token", Password: "new_strong_password", WantErr: customerrors.ErrInvalidToken}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			rf := newResetFixture(t)
+			rf.users.deleted = tc.Deleted
+
+			for i, step := range tc.Steps {
+				if err := rf.reset(t.Context(), step.Token, step.Password); !errors.Is(err, step.WantErr) {
+					t.Fatalf("step %d: got %v, want %v", i, err, step.WantErr)
+				}
+			}
+
+			password, ok := rf.users.get(rf.userID.String())
+			if ok != tc.WantReset {
+				t.Fatalf("got password set %v, want %v", ok, tc.WantReset)
+			}
+			// The first valid password is kept, the token isn't used again
+			if ok && !password.Check("new_strong_password") {
+				t.Errorf("got another password, want the one of the first reset")
+			}
+
+			sessions, err := rf.service.authCache.GetSessions(t.Context(), rf.userID)
+			if err != nil {
+				t.Fatalf("failed to get sessions: %v", err)
+			}
+			want := len(rf.sessions)
+			if tc.WantReset {
+				want = 0
+			}
+			if len(sessions) != want {
+				t.Errorf("got %d sessions, want %d", len(sessions), want)
+			}
+
+			// The access tokens of the sessions are revoked till they expire
+			for _, sessionID := range rf.sessions {
+				if got := rf.isRevoked(t, sessionID); got != tc.WantReset {
+					t.Errorf("got revoked %v for session %s, want %v", got, sessionID, tc.WantReset)
+				}
+			}
+			if rf.isRevoked(t, rf.other) {
+				t.Errorf("got the session of another user revoked")
+			}
+		})
+	}
+}
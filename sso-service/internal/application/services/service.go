@@ -4,14 +4,19 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"sort"
 	"strings"
+	"sync"
 
 	ssopb "github.com/devathh/coderun/sso-service/api/sso/v1"
 	"github.com/devathh/coderun/sso-service/internal/domain/auth"
+	"github.com/devathh/coderun/sso-service/internal/domain/mail"
 	"github.com/devathh/coderun/sso-service/internal/domain/user"
 	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/metrics"
 	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type ssoService struct {
@@ -20,15 +25,36 @@ type ssoService struct {
 	userMongo  user.MongoRepository
 	authCache  auth.AuthRedis
 	jwtManager auth.JWTManager
+	// Nil if the denylist is disabled
+	denylist auth.Denylist
+	tokens   auth.TokenRedis
+	mailer   mail.Mailer
+
+	// The emails wait there for the workers, see mailUser
+	mailsMu     sync.RWMutex
+	mailsClosed bool
+	mails       chan mailJob
+	mailWorkers sync.WaitGroup
 }
 
 type SSOService interface {
 	Register(context.Context, *ssopb.RegisterRequest) (*ssopb.Token, error)
 	Login(context.Context, *ssopb.LoginRequest) (*ssopb.Token, error)
 	Refresh(context.Context, *ssopb.RefreshRequest) (*ssopb.Token, error)
+	Logout(context.Context, *ssopb.LogoutRequest) error
+	LogoutAll(context.Context) error
+	ListSessions(context.Context) (*ssopb.SessionList, error)
+	RevokeSession(context.Context, *ssopb.RevokeSessionRequest) error
+	SendVerification(context.Context, *ssopb.SendVerificationRequest) error
+	VerifyEmail(context.Context, *ssopb.VerifyEmailRequest) error
+	RequestPasswordReset(context.Context, *ssopb.RequestPasswordResetRequest) error
+	ResetPassword(context.Context, *ssopb.ResetPasswordRequest) error
 	UpdateUser(context.Context, *ssopb.UpdateRequest) error
 	GetUserByID(context.Context, *ssopb.GetByIDRequest) (*ssopb.User, error)
 	GetSelf(context.Context) (*ssopb.User, error)
+	GetJWKS(context.Context) (*ssopb.JWKS, error)
+	// Shutdown sends the queued emails, the new ones are dropped
+	Shutdown(context.Context) error
 }
 
 func New(
@@ -37,18 +63,30 @@ func New(
 	userMongo user.MongoRepository,
 	authCache auth.AuthRedis,
 	jwtManager auth.JWTManager,
+	denylist auth.Denylist,
+	tokens auth.TokenRedis,
+	mailer mail.Mailer,
 ) (SSOService, error) {
 	if cfg == nil || log == nil {
 		return nil, customerrors.ErrNilArgs
 	}
 
-	return &ssoService{
+	s := &ssoService{
 		cfg:        cfg,
 		log:        log,
 		userMongo:  userMongo,
 		authCache:  authCache,
 		jwtManager: jwtManager,
-	}, nil
+		denylist:   denylist,
+		tokens:     tokens,
+		mailer:     mailer,
+		mails:      make(chan mailJob, cfg.Mailer.QueueSize),
+	}
+	for range max(cfg.Mailer.Workers, 1) {
+		s.mailWorkers.Go(s.mailWorker)
+	}
+
+	return s, nil
 }
 
 func (s *ssoService) GetUserByID(ctx context.Context, req *ssopb.GetByIDRequest) (*ssopb.User, error) {
@@ -81,13 +119,18 @@ func (s *ssoService) GetUserByID(ctx context.Context, req *ssopb.GetByIDRequest)
 
 	s.log.Debug("user found successfully")
 	return &ssopb.User{
-		Id:       user.ID().String(),
-		Email:    string(user.Email()),
-		Username: user.Username(),
+		Id:            user.ID().String(),
+		Email:         string(user.Email()),
+		Username:      user.Username(),
+		EmailVerified: user.EmailVerified(),
 	}, nil
 }
 
-func (s *ssoService) Login(ctx context.Context, req *ssopb.LoginRequest) (*ssopb.Token, error) {
+func (s *ssoService) Login(ctx context.Context, req *ssopb.LoginRequest) (token *ssopb.Token, err error) {
+	defer func() {
+		metrics.Logins.WithLabelValues(outcome(err)).Inc()
+	}()
+
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -119,8 +162,12 @@ func (s *ssoService) Login(ctx context.Context, req *ssopb.LoginRequest) (*ssopb
 		return nil, customerrors.ErrInvalidCredentials
 	}
 
+	if s.cfg.EmailVerification.Require && !user.EmailVerified() {
+		return nil, customerrors.ErrEmailNotVerified
+	}
+
 	s.log.Debug("start to create user's session")
-	access, refresh, err := s.createSession(ctxTimeout, user.ID(), string(user.Email()))
+	access, refresh, err := s.createSession(ctxTimeout, user.ID(), string(user.Email()), user.EmailVerified())
 	if err != nil {
 		s.log.Error("failed to create session", slog.String("error", err.Error()))
 		return nil, customerrors.ErrInternalServer
@@ -144,25 +191,41 @@ func (s *ssoService) Refresh(ctx context.Context, req *ssopb.RefreshRequest) (*s
 	ctxTimeout, cancel := context.WithTimeout(ctx, s.cfg.Server.Timeout)
 	defer cancel()
 
+	refresh := req.GetRefreshToken()
+
 	s.log.Debug("start to search user's session by refresh token")
-	session, err := s.authCache.GetSession(ctxTimeout, req.GetRefreshToken())
+	session, err := s.authCache.GetSession(ctxTimeout, refresh)
 	if err != nil {
 		if errors.Is(err, customerrors.ErrNoSessions) {
-			return nil, err
+			return nil, s.checkReuse(ctxTimeout, refresh)
 		}
 
 		s.log.Error("failed to get session", slog.String("error", err.Error()))
 		return nil, customerrors.ErrInternalServer
 	}
 
-	s.log.Debug("creating user's session")
-	newAccess, newRefresh, err := s.createSession(ctxTimeout, session.UserID(), session.Email())
+	// The email could be verified since the last refresh
+	if !session.EmailVerified() {
+		s.checkEmailVerified(ctxTimeout, session)
+	}
+
+	newAccess, newRefresh, err := s.jwtManager.GeneratePair(session)
 	if err != nil {
-		s.log.Error("failed to create new session", slog.String("error", err.Error()))
+		s.log.Error("failed to generate tokens", slog.String("error", err.Error()))
 		return nil, customerrors.ErrInternalServer
 	}
 
-	go s.clearOldSession(context.Background(), req.GetRefreshToken())
+	s.log.Debug("rotating user's session")
+	session.Touch(s.getDevice(ctx))
+	if err := s.authCache.RotateSession(ctxTimeout, refresh, newRefresh, session); err != nil {
+		// The token is rotated by a concurrent call meanwhile
+		if errors.Is(err, customerrors.ErrNoSessions) {
+			return nil, s.checkReuse(ctxTimeout, refresh)
+		}
+
+		s.log.Error("failed to rotate session", slog.String("error", err.Error()))
+		return nil, customerrors.ErrInternalServer
+	}
 
 	return &ssopb.Token{
 		Access:  newAccess,
@@ -170,7 +233,174 @@ func (s *ssoService) Refresh(ctx context.Context, req *ssopb.RefreshRequest) (*s
 	}, nil
 }
 
-func (s *ssoService) Register(ctx context.Context, req *ssopb.RegisterRequest) (*ssopb.Token, error) {
+// checkReuse tells a refresh token that is already rotated from an unknown one.
+// The rotated one is stolen either from the user or by them, so its session
+// (the family of the tokens rotated from the same login) is revoked
+func (s *ssoService) checkReuse(ctx context.Context, refresh string) error {
+	userID, sessionID, err := s.authCache.DeleteFamily(ctx, refresh)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrNoSessions) {
+			return err
+		}
+
+		s.log.Error("failed to check reuse of refresh token", slog.String("error", err.Error()))
+		return customerrors.ErrInternalServer
+	}
+
+	metrics.RefreshReuses.Inc()
+	s.revokeAccess(ctx, sessionID)
+
+	device := s.getDevice(ctx)
+	s.log.Warn("reuse of rotated refresh token, the session is revoked",
+		slog.String("event", "refresh_token_reuse"),
+		slog.String("user_id", userID.String()),
+		slog.String("session_id", sessionID.String()),
+		slog.String("ip", device.IP),
+		slog.String("user_agent", device.UserAgent),
+	)
+
+	return customerrors.ErrRefreshReused
+}
+
+func (s *ssoService) Logout(ctx context.Context, req *ssopb.LogoutRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if req == nil {
+		return customerrors.ErrNilRequest
+	}
+
+	refresh := strings.TrimSpace(req.GetRefreshToken())
+	if refresh == "" {
+		return customerrors.ErrInvalidRequest
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, s.cfg.Server.Timeout)
+	defer cancel()
+
+	s.log.Debug("start to delete user's session")
+	sessionID, err := s.authCache.DeleteSession(ctxTimeout, refresh)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrNoSessions) {
+			return err
+		}
+
+		s.log.Error("failed to delete session", slog.String("error", err.Error()))
+		return customerrors.ErrInternalServer
+	}
+	s.revokeAccess(ctxTimeout, sessionID)
+
+	return nil
+}
+
+func (s *ssoService) LogoutAll(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	userID, err := s.getUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, s.cfg.Server.Timeout)
+	defer cancel()
+
+	s.log.Debug("start to delete all the user's sessions", slog.String("id", userID.String()))
+	sessionIDs, err := s.authCache.DeleteAllSessions(ctxTimeout, userID)
+	if err != nil {
+		s.log.Error("failed to delete sessions", slog.String("error", err.Error()))
+		return customerrors.ErrInternalServer
+	}
+	s.revokeAccess(ctxTimeout, sessionIDs...)
+
+	return nil
+}
+
+func (s *ssoService) ListSessions(ctx context.Context) (*ssopb.SessionList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	userID, err := s.getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, s.cfg.Server.Timeout)
+	defer cancel()
+
+	s.log.Debug("start to get user's sessions", slog.String("id", userID.String()))
+	sessions, err := s.authCache.GetSessions(ctxTimeout, userID)
+	if err != nil {
+		s.log.Error("failed to get sessions", slog.String("error", err.Error()))
+		return nil, customerrors.ErrInternalServer
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt().After(sessions[j].LastUsedAt())
+	})
+
+	resp := &ssopb.SessionList{
+		Sessions: make([]*ssopb.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		device := session.Device()
+		resp.Sessions = append(resp.Sessions, &ssopb.Session{
+			Id:         session.ID().String(),
+			Ip:         device.IP,
+			UserAgent:  device.UserAgent,
+			Device:     device.Label,
+			CreatedAt:  timestamppb.New(session.CreatedAt()),
+			LastUsedAt: timestamppb.New(session.LastUsedAt()),
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *ssoService) RevokeSession(ctx context.Context, req *ssopb.RevokeSessionRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if req == nil {
+		return customerrors.ErrNilRequest
+	}
+
+	userID, err := s.getUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	sessionID, err := uuid.Parse(strings.TrimSpace(req.GetSessionId()))
+	if err != nil {
+		return customerrors.ErrInvalidRequest
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, s.cfg.Server.Timeout)
+	defer cancel()
+
+	s.log.Debug("start to delete user's session", slog.String("session_id", sessionID.String()))
+	if err := s.authCache.DeleteSessionByID(ctxTimeout, userID, sessionID); err != nil {
+		if errors.Is(err, customerrors.ErrSessionNotFound) {
+			return err
+		}
+
+		s.log.Error("failed to delete session", slog.String("error", err.Error()))
+		return customerrors.ErrInternalServer
+	}
+	s.revokeAccess(ctxTimeout, sessionID)
+
+	return nil
+}
+
+func (s *ssoService) Register(ctx context.Context, req *ssopb.RegisterRequest) (token *ssopb.Token, err error) {
+	defer func() {
+		metrics.Registrations.WithLabelValues(outcome(err)).Inc()
+	}()
+
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -200,12 +430,17 @@ func (s *ssoService) Register(ctx context.Context, req *ssopb.RegisterRequest) (
 	}
 
 	s.log.Debug("creating session")
-	access, refresh, err := s.createSession(ctxTimeout, saved.ID(), string(saved.Email()))
+	access, refresh, err := s.createSession(ctxTimeout, saved.ID(), string(saved.Email()), saved.EmailVerified())
 	if err != nil {
 		s.log.Error("failed to create session", slog.String("error", err.Error()))
 		return nil, customerrors.ErrInternalServer
 	}
 
+	// The user can ask for another one, so it isn't fatal
+	if err := s.sendVerification(ctxTimeout, saved); err != nil {
+		s.log.Error("failed to send verification", slog.String("error", err.Error()))
+	}
+
 	return &ssopb.Token{
 		Access:  access,
 		Refresh: refresh,
@@ -226,7 +461,7 @@ func (s *ssoService) UpdateUser(ctx context.Context, req *ssopb.UpdateRequest) e
 	defer cancel()
 
 	s.log.Debug("creating domain to update")
-	userUpd := user.From(userID, req.GetUsername(), user.Email(""), user.Password(""))
+	userUpd := user.From(userID, req.GetUsername(), user.Email(""), user.Password(""), false)
 
 	s.log.Debug("starting update user")
 	if err := s.userMongo.Update(ctxTimeout, userUpd); err != nil {
@@ -262,12 +497,35 @@ func (s *ssoService) GetSelf(ctx context.Context) (*ssopb.User, error) {
 	}
 
 	return &ssopb.User{
-		Id:       userID.String(),
-		Email:    string(user.Email()),
-		Username: user.Username(),
+		Id:            userID.String(),
+		Email:         string(user.Email()),
+		Username:      user.Username(),
+		EmailVerified: user.EmailVerified(),
 	}, nil
 }
 
+func (s *ssoService) GetJWKS(_ context.Context) (*ssopb.JWKS, error) {
+	keys := s.jwtManager.JWKS()
+
+	resp := &ssopb.JWKS{
+		Keys: make([]*ssopb.JWK, 0, len(keys)),
+	}
+	for _, key := range keys {
+		resp.Keys = append(resp.Keys, &ssopb.JWK{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Use: key.Use,
+			Alg: key.Alg,
+			N:   key.N,
+			E:   key.E,
+			Crv: key.Crv,
+			X:   key.X,
+		})
+	}
+
+	return resp, nil
+}
+
 func (s *ssoService) getUserID(ctx context.Context) (uuid.UUID, error) {
 	rawID := ctx.Value(auth.CtxKey("user_id"))
 	if rawID == nil {
@@ -281,6 +539,12 @@ func (s *ssoService) getUserID(ctx context.Context) (uuid.UUID, error) {
 	return uuid.Nil, customerrors.ErrInvalidUserID
 }
 
+// getDevice is the device of the caller, unknown if it's called in bypass of the interceptors
+func (s *ssoService) getDevice(ctx context.Context) auth.Device {
+	device, _ := ctx.Value(auth.CtxKey("device")).(auth.Device)
+	return device
+}
+
 func (s *ssoService) createUser(req *ssopb.RegisterRequest) (*user.User, error) {
 	email, err := user.NewEmail(req.GetEmail())
 	if err != nil {
@@ -295,13 +559,18 @@ func (s *ssoService) createUser(req *ssopb.RegisterRequest) (*user.User, error)
 	return user.New(req.GetUsername(), email, password)
 }
 
-func (s *ssoService) createSession(ctx context.Context, userID uuid.UUID, email string) (string, string, error) {
-	access, refresh, err := s.jwtManager.GeneratePair(userID, email)
+func (s *ssoService) createSession(ctx context.Context, userID uuid.UUID, email string, emailVerified bool) (string, string, error) {
+	session, err := auth.NewSession(userID, email, emailVerified, s.getDevice(ctx))
 	if err != nil {
 		return "", "", err
 	}
 
-	session, err := auth.NewSession(userID, email)
+	return s.issueTokens(ctx, session)
+}
+
+// issueTokens generates a pair of tokens for the session n' saves it by the refresh one
+func (s *ssoService) issueTokens(ctx context.Context, session *auth.Session) (string, string, error) {
+	access, refresh, err := s.jwtManager.GeneratePair(session)
 	if err != nil {
 		return "", "", err
 	}
@@ -313,9 +582,35 @@ func (s *ssoService) createSession(ctx context.Context, userID uuid.UUID, email
 	return access, refresh, nil
 }
 
-func (s *ssoService) clearOldSession(ctx context.Context, refresh string) {
-	s.log.Debug("start to clear old session")
-	if err := s.authCache.DeleteSession(ctx, refresh); err != nil {
-		s.log.Error("failed to clear session", slog.String("error", err.Error()))
+// revokeAccess denies the access tokens of the deleted sessions. They are
+// deleted anyway, so the access tokens just live till the expiry on failure
+func (s *ssoService) revokeAccess(ctx context.Context, sessionIDs ...uuid.UUID) {
+	if s.denylist == nil {
+		return
 	}
+
+	if err := s.denylist.RevokeSessions(ctx, sessionIDs...); err != nil {
+		s.log.Error("failed to revoke access tokens", slog.String("error", err.Error()))
+	}
+}
+
+// outcome of a login or a registration for the metrics
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return metrics.OutcomeSuccess
+	case errors.Is(err, customerrors.ErrInvalidCredentials),
+		errors.Is(err, customerrors.ErrUserDoesntExist):
+		return metrics.OutcomeInvalidCredentials
+	case errors.Is(err, customerrors.ErrUserAlreadyRegistered):
+		return metrics.OutcomeAlreadyRegistered
+	case errors.Is(err, customerrors.ErrEmailNotVerified):
+		return metrics.OutcomeEmailNotVerified
+	case errors.Is(err, customerrors.ErrInternalServer),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return metrics.OutcomeError
+	}
+
+	return metrics.OutcomeInvalidRequest
 }
@@ -11,7 +11,9 @@ import (
 	jwt "github.com/devathh/coderun/sso-service/internal/infrastructure/auth"
 	rediscache "github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis"
 	authredis "github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis/auth"
+	tokenredis "github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis/token"
 	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/mailer"
 	mongodb "github.com/devathh/coderun/sso-service/internal/infrastructure/persistence/mongo"
 	usermongo "github.com/devathh/coderun/sso-service/internal/infrastructure/persistence/mongo/user"
 	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
@@ -145,7 +147,19 @@ func buildTestSetup() SSOService {
 		os.Exit(1)
 	}
 
-	service, err := New(cfg, slog.Default(), userMongo, authCache, jwtMngr)
+	tokens, err := tokenredis.New(redisClient)
+	if err != nil {
+		slog.Error("failed to create token cache", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	mail, err := mailer.New(cfg, slog.Default())
+	if err != nil {
+		slog.Error("failed to create mailer", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	service, err := New(cfg, slog.Default(), userMongo, authCache, jwtMngr, nil, tokens, mail)
 	if err != nil {
 		slog.Error("failed to create service", slog.String("error", err.Error()))
 		os.Exit(1)
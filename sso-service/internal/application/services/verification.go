@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	ssopb "github.com/devathh/coderun/sso-service/api/sso/v1"
+	"github.com/devathh/coderun/sso-service/internal/domain/auth"
+	"github.com/devathh/coderun/sso-service/internal/domain/mail"
+	"github.com/devathh/coderun/sso-service/internal/domain/user"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/metrics"
+	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
+)
+
+// SendVerification doesn't tell whether the user exists or is already verified
+func (s *ssoService) SendVerification(ctx context.Context, req *ssopb.SendVerificationRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if req == nil {
+		return customerrors.ErrNilRequest
+	}
+
+	email := strings.TrimSpace(req.GetEmail())
+	if email == "" {
+		return customerrors.ErrInvalidRequest
+	}
+
+	s.mailUser(ctx, email, func(ctx context.Context, user *user.User) error {
+		if user.EmailVerified() {
+			return nil
+		}
+
+		return s.sendVerification(ctx, user)
+	})
+
+	return nil
+}
+
+func (s *ssoService) VerifyEmail(ctx context.Context, req *ssopb.VerifyEmailRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if req == nil {
+		return customerrors.ErrNilRequest
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, s.cfg.Server.Timeout)
+	defer cancel()
+
+	s.log.Debug("start to consume verification token")
+	userID, err := s.tokens.ConsumeToken(ctxTimeout, auth.TokenEmailVerification, strings.TrimSpace(req.GetToken()))
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidToken) {
+			return err
+		}
+
+		s.log.Error("failed to consume verification token", slog.String("error", err.Error()))
+		return customerrors.ErrInternalServer
+	}
+
+	if err := s.userMongo.SetEmailVerified(ctxTimeout, userID.String()); err != nil {
+		// The user is deleted since the token was sent
+		if errors.Is(err, customerrors.ErrUserDoesntExist) {
+			return customerrors.ErrInvalidToken
+		}
+
+		s.log.Error("failed to verify email", slog.String("error", err.Error()))
+		return customerrors.ErrInternalServer
+	}
+
+	s.log.Info("email is verified", slog.String("user_id", userID.String()))
+	return nil
+}
+
+// mailJob is an email to the user with the address, waiting in the queue
+type mailJob struct {
+	ctx   context.Context
+	email string
+	send  func(context.Context, *user.User) error
+}
+
+// mailUser finds the user by the email n' sends to them in the background,
+// so neither the answer nor its time tell whether the user exists.
+// The email is dropped if the queue is full
+func (s *ssoService) mailUser(ctx context.Context, email string, send func(context.Context, *user.User) error) {
+	s.mailsMu.RLock()
+	defer s.mailsMu.RUnlock()
+
+	if s.mailsClosed {
+		metrics.EmailsDropped.Inc()
+		return
+	}
+
+	select {
+	case s.mails <- mailJob{ctx: context.WithoutCancel(ctx), email: email, send: send}:
+	default:
+		metrics.EmailsDropped.Inc()
+		s.log.Warn("mail queue is full, email is dropped")
+	}
+}
+
+func (s *ssoService) mailWorker() {
+	for job := range s.mails {
+		s.deliver(job)
+	}
+}
+
+func (s *ssoService) deliver(job mailJob) {
+	ctx, cancel := context.WithTimeout(job.ctx, s.cfg.Server.Timeout)
+	defer cancel()
+
+	user, err := s.userMongo.GetByEmail(ctx, job.email)
+	if err != nil {
+		if !errors.Is(err, customerrors.ErrUserDoesntExist) {
+			s.log.Error("failed to get user by email", slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	if err := job.send(ctx, user); err != nil {
+		s.log.Error("failed to send email", slog.String("error", err.Error()))
+	}
+}
+
+func (s *ssoService) Shutdown(ctx context.Context) error {
+	s.mailsMu.Lock()
+	if !s.mailsClosed {
+		s.mailsClosed = true
+		close(s.mails)
+	}
+	s.mailsMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.mailWorkers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("emails are left in the queue: %w", ctx.Err())
+	}
+}
+
+// sendVerification emails a new token to the user, the previous one is revoked
+func (s *ssoService) sendVerification(ctx context.Context, user *user.User) error {
+	ttl := s.cfg.EmailVerification.TTL
+	return s.sendToken(ctx, auth.TokenEmailVerification, ttl, user, func(token string) mail.Message {
+		return mail.Message{
+			To:      string(user.Email()),
+			Subject: "Verify your email",
+			Body: fmt.Sprintf(
+				"Hi, %s!\n\nTo verify your email on coderun, follow the link or enter the token:\n\n%s\n\nIt expires in %s.\n",
+				user.Username(), mailLink(s.cfg.EmailVerification.URL, token), ttl,
+			),
+		}
+	})
+}
+
+// sendToken saves a new token of the kind n' emails the message with it
+func (s *ssoService) sendToken(
+	ctx context.Context,
+	kind auth.TokenKind,
+	ttl time.Duration,
+	user *user.User,
+	message func(token string) mail.Message,
+) (err error) {
+	defer func() {
+		metrics.EmailsSent.WithLabelValues(string(kind), result(err)).Inc()
+	}()
+
+	token, err := generateMailToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.tokens.SaveToken(ctx, kind, token, user.ID(), ttl); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+
+	// The mailer has its own timeout, longer than the one of the calls
+	return s.mailer.Send(context.WithoutCancel(ctx), message(token))
+}
+
+// checkEmailVerified marks the email of the session verified if the user did it
+// since the session was created. The session stays unverified on failure
+func (s *ssoService) checkEmailVerified(ctx context.Context, session *auth.Session) {
+	user, err := s.userMongo.GetByID(ctx, session.UserID().String())
+	if err != nil {
+		s.log.Warn("failed to check verification of email", slog.String("error", err.Error()))
+		return
+	}
+
+	if user.EmailVerified() {
+		session.VerifyEmail()
+	}
+}
+
+// generateMailToken is a random url-safe token for the links of the emails
+func generateMailToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// mailLink adds the token to the query of the link, the token alone without the link
+func mailLink(link, token string) string {
+	if link == "" {
+		return token
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return token
+	}
+
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// result of sending an email for the metrics
+func result(err error) string {
+	if err != nil {
+		return metrics.OutcomeError
+	}
+
+	return metrics.OutcomeSuccess
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devathh/coderun/sso-service/internal/domain/user"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+func TestMailLink(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Link  string
+		Token string
+		Want  string
+	}{
+		{Name: "no_link", Link: "", Token: "abc", Want: "abc"},
+		{Name: "base", Link: "https://coderun.dev/verify", Token: "abc", Want: "https://coderun.dev/verify?token=abc"},
+		{Name: "query_kept", Link: "https://coderun.dev/verify?lang=en", Token: "abc", Want: "https://coderun.dev/verify?lang=en&token=abc"},
+		{Name: "token_replaced", Link: "https://coderun.dev/verify?token=old", Token: "abc", Want: "https://coderun.dev/verify?token=abc"},
+		{Name: "token_escaped", Link: "https://coderun.dev/verify", Token: "a+b/c=", Want: "https://coderun.dev/verify?token=a%2Bb%2Fc%3D"},
+		{Name: "fragment_kept", Link: "https://coderun.dev/#/reset", Token: "abc", Want: "https://coderun.dev/?token=abc#/reset"},
+		// The token is still useful without the link
+		{Name: "invalid_link", Link: "://coderun.dev", Token: "abc", Want: "abc"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := mailLink(tc.Link, tc.Token); got != tc.Want {
+				t.Errorf("got %q, want %q", got, tc.Want)
+			}
+		})
+	}
+}
+
+// fakeUsers is the mongo of the users, GetByEmail waits for release if it's set
+type fakeUsers struct {
+	user.MongoRepository
+
+	mu      sync.Mutex
+	users   map[string]*user.User
+	started chan struct{}
+	release chan struct{}
+}
+
+func (fu *fakeUsers) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	if fu.release != nil {
+		fu.started <- struct{}{}
+		select {
+		case <-fu.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	fu.mu.Lock()
+	defer fu.mu.Unlock()
+
+	found, ok := fu.users[email]
+	if !ok {
+		return nil, customerrors.ErrUserDoesntExist
+	}
+
+	return found, nil
+}
+
+func newUser(t *testing.T, email string) *user.User {
+	t.Helper()
+
+	password, err := user.NewPassword("very_strong_password")
+	if err != nil {
+		t.Fatalf("failed to create password: %v", err)
+	}
+
+	return user.From(uuid.New(), "user", user.Email(email), password, false)
+}
+
+func newMailService(t *testing.T, users *fakeUsers, queueSize int) *ssoService {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Server.Timeout = time.Minute
+	cfg.Mailer.QueueSize = queueSize
+	cfg.Mailer.Workers = 1
+
+	service, err := New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), users, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	return service.(*ssoService)
+}
+
+// sentTo counts the emails sent by address
+type sentTo struct {
+	mu    sync.Mutex
+	count map[string]int
+}
+
+func (st *sentTo) send(_ context.Context, user *user.User) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.count[string(user.Email())]++
+	return nil
+}
+
+func TestMailQueue(t *testing.T) {
+	users := &fakeUsers{
+		users: map[string]*user.User{
+			"a@coderun.dev": newUser(t, "a@coderun.dev"),
+			"b@coderun.dev": newUser(t, "b@coderun.dev"),
+			"c@coderun.dev": newUser(t, "c@coderun.dev"),
+		},
+		started: make(chan struct{}, 3),
+		release: make(chan struct{}),
+	}
+	service := newMailService(t, users, 1)
+	sent := &sentTo{count: make(map[string]int)}
+
+	// The worker is busy with the first email, the second one waits in the queue
+	service.mailUser(t.Context(), "a@coderun.dev", sent.send)
+	<-users.started
+	service.mailUser(t.Context(), "b@coderun.dev", sent.send)
+	// The queue is full, it's dropped without waiting
+	service.mailUser(t.Context(), "c@coderun.dev", sent.send)
+	close(users.release)
+
+	// The queued emails are sent on shutdown, even though the requests are done
+	if err := service.Shutdown(t.Context()); err != nil {
+		t.Fatalf("failed to shutdown: %v", err)
+	}
+	want := map[string]int{"a@coderun.dev": 1, "b@coderun.dev": 1}
+	if !maps.Equal(sent.count, want) {
+		t.Errorf("got %v, want %v", sent.count, want)
+	}
+
+	// Nothing is queued after shutdown
+	service.mailUser(t.Context(), "c@coderun.dev", sent.send)
+	if err := service.Shutdown(t.Context()); err != nil {
+		t.Errorf("got %v on second shutdown, want nil", err)
+	}
+	if !maps.Equal(sent.count, want) {
+		t.Errorf("got %v, want %v", sent.count, want)
+	}
+}
+
+func TestMailQueueShutdownTimeout(t *testing.T) {
+	users := &fakeUsers{
+		users:   map[string]*user.User{},
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	service := newMailService(t, users, 1)
+	t.Cleanup(func() { close(users.release) })
+
+	service.mailUser(t.Context(), "a@coderun.dev", func(context.Context, *user.User) error { return nil })
+	<-users.started
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := service.Shutdown(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}
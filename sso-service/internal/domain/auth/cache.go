@@ -1,9 +1,24 @@
 package auth
 
-import "context"
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
 
 type AuthRedis interface {
 	CreateSession(ctx context.Context, refresh string, session *Session) error
 	GetSession(ctx context.Context, refresh string) (*Session, error)
-	DeleteSession(ctx context.Context, refresh string) error
+	// RotateSession moves the session to the new refresh token at once with
+	// revoking the old one. ErrNoSessions if the old one is already invalid
+	RotateSession(ctx context.Context, refresh, newRefresh string, session *Session) error
+	// DeleteFamily revokes the session the refresh token was rotated in.
+	// ErrNoSessions if the token wasn't rotated
+	DeleteFamily(ctx context.Context, refresh string) (userID, sessionID uuid.UUID, err error)
+	// DeleteSession returns the id of the deleted session
+	DeleteSession(ctx context.Context, refresh string) (uuid.UUID, error)
+	GetSessions(ctx context.Context, userID uuid.UUID) ([]*Session, error)
+	DeleteSessionByID(ctx context.Context, userID, sessionID uuid.UUID) error
+	// DeleteAllSessions returns the ids of the deleted sessions
+	DeleteAllSessions(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
 }
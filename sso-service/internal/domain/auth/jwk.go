@@ -0,0 +1,15 @@
+package auth
+
+// JWK is a public key of sso in the JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// Modulus n' exponent of the rsa key, base64url without padding
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Curve n' public key of the ed25519 one
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
@@ -1,10 +1,22 @@
 package auth
 
-import "github.com/google/uuid"
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
 
 type JWTManager interface {
-	GenerateAccess(userID uuid.UUID, email string) (string, error)
+	GenerateAccess(session *Session) (string, error)
 	GenerateRefresh() (string, error)
-	GeneratePair(userID uuid.UUID, email string) (string, string, error)
+	GeneratePair(session *Session) (string, string, error)
 	Validate(tokenString string) (*CoderunClaims, error)
+	// Public keys of the tokens, the active one first
+	JWKS() []JWK
+}
+
+// Denylist of the access tokens: they stay valid till the expiry otherwise
+type Denylist interface {
+	RevokeSessions(ctx context.Context, sessionIDs ...uuid.UUID) error
+	IsRevoked(ctx context.Context, claims *CoderunClaims) (bool, error)
 }
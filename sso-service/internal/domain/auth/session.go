@@ -3,32 +3,98 @@ package auth
 import (
 	"net/mail"
 	"strings"
+	"time"
 
 	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
 	"github.com/google/uuid"
 )
 
+// Device is where the session is used from, as the gateway saw it
+type Device struct {
+	IP        string
+	UserAgent string
+	// Like "Firefox on Linux" or the name given by the client
+	Label string
+}
+
+// Session is a login of the user on a device.
+// It keeps its id while the refresh tokens are rotated
 type Session struct {
+	id     uuid.UUID
 	userID uuid.UUID
 	email  string
+	// Goes to the access tokens
+	emailVerified bool
+	device        Device
+	createdAt     time.Time
+	lastUsedAt    time.Time
 }
 
-func NewSession(userID uuid.UUID, email string) (*Session, error) {
+func NewSession(userID uuid.UUID, email string, emailVerified bool, device Device) (*Session, error) {
 	email = strings.TrimSpace(email)
 	if _, err := mail.ParseAddress(email); err != nil {
 		return nil, customerrors.ErrInvalidEmail
 	}
 
+	now := time.Now().UTC()
 	return &Session{
-		userID: userID,
-		email:  email,
+		id:            uuid.New(),
+		userID:        userID,
+		email:         email,
+		emailVerified: emailVerified,
+		device:        device,
+		createdAt:     now,
+		lastUsedAt:    now,
 	}, nil
 }
 
+func SessionFrom(id, userID uuid.UUID, email string, emailVerified bool, device Device, createdAt, lastUsedAt time.Time) *Session {
+	return &Session{
+		id:            id,
+		userID:        userID,
+		email:         email,
+		emailVerified: emailVerified,
+		device:        device,
+		createdAt:     createdAt,
+		lastUsedAt:    lastUsedAt,
+	}
+}
+
+// Touch marks the session as used from the device now
+func (s *Session) Touch(device Device) {
+	s.device = device
+	s.lastUsedAt = time.Now().UTC()
+}
+
+// VerifyEmail marks the email as verified after the session was created
+func (s *Session) VerifyEmail() {
+	s.emailVerified = true
+}
+
+func (s *Session) ID() uuid.UUID {
+	return s.id
+}
+
 func (s *Session) Email() string {
 	return s.email
 }
 
+func (s *Session) EmailVerified() bool {
+	return s.emailVerified
+}
+
 func (s *Session) UserID() uuid.UUID {
 	return s.userID
 }
+
+func (s *Session) Device() Device {
+	return s.device
+}
+
+func (s *Session) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+func (s *Session) LastUsedAt() time.Time {
+	return s.lastUsedAt
+}
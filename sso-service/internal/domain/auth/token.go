@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenKind is what a single-use token sent by email is for
+type TokenKind string
+
+const (
+	TokenEmailVerification TokenKind = "email-verification"
+	TokenPasswordReset     TokenKind = "password-reset"
+)
+
+// TokenRedis keeps the single-use tokens sent by email. Only their hashes are stored
+type TokenRedis interface {
+	// SaveToken revokes the previous token of the kind of the user
+	SaveToken(ctx context.Context, kind TokenKind, token string, userID uuid.UUID, ttl time.Duration) error
+	// ConsumeToken revokes the token n' returns its user.
+	// ErrInvalidToken if it's unknown, already used or expired
+	ConsumeToken(ctx context.Context, kind TokenKind, token string) (uuid.UUID, error)
+}
@@ -9,4 +9,8 @@ type MongoRepository interface {
 	Update(context.Context, *User) error // With id
 	GetByID(context.Context, string) (*User, error)
 	GetByEmail(context.Context, string) (*User, error)
+	// ErrUserDoesntExist if there is no user with the id
+	SetEmailVerified(context.Context, string) error
+	// ErrUserDoesntExist if there is no user with the id
+	SetPassword(context.Context, string, Password) error
 }
@@ -12,6 +12,8 @@ type User struct {
 	email    Email
 	username string
 	password Password
+	// Set by the token sent to the email
+	emailVerified bool
 }
 
 func New(username string, email Email, password Password) (*User, error) {
@@ -28,12 +30,13 @@ func New(username string, email Email, password Password) (*User, error) {
 	}, nil
 }
 
-func From(id uuid.UUID, username string, email Email, password Password) *User {
+func From(id uuid.UUID, username string, email Email, password Password, emailVerified bool) *User {
 	return &User{
-		id:       id,
-		username: username,
-		email:    email,
-		password: password,
+		id:            id,
+		username:      username,
+		email:         email,
+		password:      password,
+		emailVerified: emailVerified,
 	}
 }
 
@@ -52,3 +55,7 @@ func (u *User) Username() string {
 func (u *User) Password() Password {
 	return u.password
 }
+
+func (u *User) EmailVerified() bool {
+	return u.emailVerified
+}
@@ -1,24 +1,29 @@
 package jwt
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/devathh/coderun/sso-service/internal/domain/auth"
 	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
 	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
 )
 
 type JWTManager struct {
-	cfg     *config.Config
-	private *rsa.PrivateKey
-	public  *rsa.PublicKey
+	cfg *config.Config
+	// Signs the new tokens
+	active Key
+	// Every key validates the tokens with its kid
+	keys map[string]Key
+	// The kids of the keys, the active one first
+	kids []string
 }
 
 func New(cfg *config.Config) (*JWTManager, error) {
@@ -26,27 +31,36 @@ func New(cfg *config.Config) (*JWTManager, error) {
 		return nil, customerrors.ErrNilArgs
 	}
 
-	private, err := loadPrivate(cfg)
+	keys, err := LoadKeys(cfg)
 	if err != nil {
 		return nil, err
 	}
+	if len(keys) == 0 {
+		return nil, errors.New("no keys")
+	}
+	if keys[0].Private == nil {
+		return nil, fmt.Errorf("%w of the active kid: %s", ErrNoPrivateKey, keys[0].KID)
+	}
 
-	public, err := loadPublic(cfg)
-	if err != nil {
-		return nil, err
+	jm := &JWTManager{
+		cfg:    cfg,
+		active: keys[0],
+		keys:   make(map[string]Key, len(keys)),
+	}
+	for _, key := range keys {
+		jm.keys[key.KID] = key
+		jm.kids = append(jm.kids, key.KID)
 	}
 
-	return &JWTManager{
-		cfg:     cfg,
-		private: private,
-		public:  public,
-	}, nil
+	return jm, nil
 }
 
-func (jm *JWTManager) GenerateAccess(userID uuid.UUID, email string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, auth.CoderunClaims{
-		UserID: userID,
-		Email:  email,
+func (jm *JWTManager) GenerateAccess(session *auth.Session) (string, error) {
+	token := jwt.NewWithClaims(jm.active.Method(), auth.CoderunClaims{
+		UserID:        session.UserID(),
+		SessionID:     session.ID(),
+		Email:         session.Email(),
+		EmailVerified: session.EmailVerified(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    "shost-sso",
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -55,7 +69,9 @@ func (jm *JWTManager) GenerateAccess(userID uuid.UUID, email string) (string, er
 		},
 	})
 
-	tokenString, err := token.SignedString(jm.private)
+	token.Header["kid"] = jm.active.KID
+
+	tokenString, err := token.SignedString(jm.active.Private)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -73,8 +89,8 @@ func (jm *JWTManager) GenerateRefresh() (string, error) {
 }
 
 // access, refresh
-func (jm *JWTManager) GeneratePair(userID uuid.UUID, email string) (string, string, error) {
-	access, err := jm.GenerateAccess(userID, email)
+func (jm *JWTManager) GeneratePair(session *auth.Session) (string, string, error) {
+	access, err := jm.GenerateAccess(session)
 	if err != nil {
 		return "", "", err
 	}
@@ -93,11 +109,20 @@ func (jm *JWTManager) Validate(tokenString string) (*auth.CoderunClaims, error)
 	}
 
 	token, err := jwt.ParseWithClaims(tokenString, &auth.CoderunClaims{}, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+		// The tokens signed before the kids were set
+		key := jm.active
+		if kid, ok := t.Header["kid"].(string); ok {
+			if key, ok = jm.keys[kid]; !ok {
+				return nil, customerrors.ErrInvalidToken
+			}
+		}
+
+		// The alg of the token must be the one of its key
+		if t.Method.Alg() != key.Method().Alg() {
 			return nil, customerrors.ErrInvalidToken
 		}
 
-		return jm.public, nil
+		return key.Public, nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -110,30 +135,28 @@ func (jm *JWTManager) Validate(tokenString string) (*auth.CoderunClaims, error)
 	return nil, customerrors.ErrInvalidToken
 }
 
-func loadPrivate(cfg *config.Config) (*rsa.PrivateKey, error) {
-	bytesKey, err := os.ReadFile(cfg.Secrets.JWT.PrivatePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read private key: %w", err)
-	}
-
-	key, err := jwt.ParseRSAPrivateKeyFromPEM(bytesKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
-	}
-
-	return key, nil
-}
+func (jm *JWTManager) JWKS() []auth.JWK {
+	keys := make([]auth.JWK, 0, len(jm.kids))
+	for _, kid := range jm.kids {
+		key := jm.keys[kid]
 
-func loadPublic(cfg *config.Config) (*rsa.PublicKey, error) {
-	bytesKey, err := os.ReadFile(cfg.Secrets.JWT.PublicPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read public key: %w", err)
-	}
+		jwk := auth.JWK{
+			Kid: kid,
+			Use: "sig",
+			Alg: key.Method().Alg(),
+		}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N, jwk.E = rsaParams(public)
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
 
-	key, err := jwt.ParseRSAPublicKeyFromPEM(bytesKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse public key: %w", err)
+		keys = append(keys, jwk)
 	}
 
-	return key, nil
+	return keys
 }
@@ -0,0 +1,164 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/devathh/coderun/sso-service/internal/domain/auth"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func newManager(t *testing.T, cfg *config.Config, activeKID string) *JWTManager {
+	t.Helper()
+
+	copied := *cfg
+	copied.Secrets.JWT.TTL = time.Minute
+	copied.Secrets.JWT.ActiveKID = activeKID
+
+	jm, err := New(&copied)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+
+	return jm
+}
+
+func newAccess(t *testing.T, jm *JWTManager) string {
+	t.Helper()
+
+	session, err := auth.NewSession(uuid.New(), "user@coderun.dev", true, auth.Device{})
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	access, err := jm.GenerateAccess(session)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	return access
+}
+
+// signWith signs the claims with the key, under the kid of the header
+func signWith(t *testing.T, key *Key, kid string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(key.Method(), auth.CoderunClaims{
+		UserID: uuid.New(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key.Private)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	return signed
+}
+
+func TestJWKS(t *testing.T) {
+	cfg := newKeysConfig(t)
+	edKey := generate(t, cfg, KeyEd25519)
+	rsaKey := generate(t, cfg, KeyRSA)
+
+	// The active key goes first, the tokens without kid are checked by it
+	jwks := newManager(t, cfg, rsaKey.KID).JWKS()
+	got := make([]string, 0, len(jwks))
+	for _, jwk := range jwks {
+		got = append(got, jwk.Kid)
+	}
+	if want := []string{rsaKey.KID, edKey.KID}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	rsaJWK, edJWK := jwks[0], jwks[1]
+	if rsaJWK.Kty != "RSA" || rsaJWK.Alg != "RS256" || rsaJWK.Use != "sig" {
+		t.Errorf("got %s %s %s, want RSA RS256 sig", rsaJWK.Kty, rsaJWK.Alg, rsaJWK.Use)
+	}
+	n, _ := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+	e, _ := base64.RawURLEncoding.DecodeString(rsaJWK.E)
+	public := rsaKey.Public.(*rsa.PublicKey)
+	if new(big.Int).SetBytes(n).Cmp(public.N) != 0 || new(big.Int).SetBytes(e).Int64() != int64(public.E) {
+		t.Errorf("got rsa params of another key")
+	}
+
+	if edJWK.Kty != "OKP" || edJWK.Crv != "Ed25519" || edJWK.Alg != "EdDSA" || edJWK.Use != "sig" {
+		t.Errorf("got %s %s %s %s, want OKP Ed25519 EdDSA sig", edJWK.Kty, edJWK.Crv, edJWK.Alg, edJWK.Use)
+	}
+	if x, _ := base64.RawURLEncoding.DecodeString(edJWK.X); !edKey.Public.(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+		t.Errorf("got ed25519 key of another key")
+	}
+}
+
+func TestValidateRotation(t *testing.T) {
+	cfg := newKeysConfig(t)
+	old := generate(t, cfg, KeyEd25519)
+	next := generate(t, cfg, KeyRSA)
+	unknown := generate(t, newKeysConfig(t), KeyEd25519)
+
+	beforeRotation := newAccess(t, newManager(t, cfg, old.KID))
+	// The next key signs the new tokens, the old one still checks its tokens
+	rotated := newManager(t, cfg, next.KID)
+
+	testCases := []struct {
+		Name    string
+		Token   string
+		WantErr bool
+	}{
+		{Name: "old_key", Token: beforeRotation},
+		{Name: "new_key", Token: newAccess(t, rotated)},
+		// The tokens signed before the kids were set are checked by the active key
+		{Name: "no_kid", Token: signWith(t, next, "")},
+		{Name: "no_kid_old_key", Token: signWith(t, old, ""), WantErr: true},
+		{Name: "unknown_kid", Token: signWith(t, unknown, unknown.KID), WantErr: true},
+		// Signed by a known key, but under the kid of another one
+		{Name: "wrong_kid", Token: signWith(t, next, old.KID), WantErr: true},
+		{Name: "other_key_same_alg", Token: signWith(t, unknown, old.KID), WantErr: true},
+		{Name: "empty", Token: "", WantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := rotated.Validate(tc.Token)
+			if (err != nil) != tc.WantErr {
+				t.Errorf("got %v, want error %v", err, tc.WantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDroppedKey(t *testing.T) {
+	cfg := newKeysConfig(t)
+	old := generate(t, cfg, KeyEd25519)
+	next := generate(t, cfg, KeyRSA)
+	beforeRotation := newAccess(t, newManager(t, cfg, old.KID))
+
+	// The old key is dropped once its tokens expired
+	if err := os.Remove(old.Path); err != nil {
+		t.Fatalf("failed to remove key: %v", err)
+	}
+	if err := os.Remove(filepath.Join(cfg.Secrets.JWT.KeysDir, old.KID+".key")); err != nil {
+		t.Fatalf("failed to remove key: %v", err)
+	}
+	if _, err := newManager(t, cfg, next.KID).Validate(beforeRotation); !errors.Is(err, customerrors.ErrInvalidToken) {
+		t.Errorf("got %v, want %v", err, customerrors.ErrInvalidToken)
+	}
+}
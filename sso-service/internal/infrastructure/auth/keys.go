@@ -0,0 +1,383 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	KeyRSA     = "rsa"
+	KeyEd25519 = "ed25519"
+
+	rsaBits = 3072
+	// Holds the kid of the active key in the keys dir
+	activeFile = "active"
+)
+
+var (
+	ErrUnknownKID   = errors.New("unknown kid")
+	ErrNoPrivateKey = errors.New("no private key")
+)
+
+// Key is a key of sso from the config or the keys dir
+type Key struct {
+	KID    string
+	Public crypto.PublicKey
+	// Nil if only the public key is known, the key only verifies the tokens then
+	Private crypto.Signer
+	// Path of the public key
+	Path string
+}
+
+// Method the tokens are signed with: RS256 or EdDSA
+func (k *Key) Method() jwt.SigningMethod {
+	if _, ok := k.Public.(ed25519.PublicKey); ok {
+		return jwt.SigningMethodEdDSA
+	}
+
+	return jwt.SigningMethodRS256
+}
+
+// Fingerprint is the sha256 of the public key in DER, like the one of ssh-keygen
+func (k *Key) Fingerprint() string {
+	der, err := x509.MarshalPKIXPublicKey(k.Public)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(der)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// LoadKeys reads the keys of the config n' its keys dir. The active one is first
+func LoadKeys(cfg *config.Config) ([]Key, error) {
+	var keys []Key
+	seen := make(map[string]bool)
+
+	add := func(key Key) error {
+		if seen[key.KID] {
+			return fmt.Errorf("duplicate kid: %s", key.KID)
+		}
+		seen[key.KID] = true
+
+		keys = append(keys, key)
+		return nil
+	}
+
+	for _, keyCfg := range cfg.Secrets.JWT.Keys {
+		key, err := loadKey(keyCfg.KID, keyCfg.PublicPath, keyCfg.PrivatePath)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	activeKID := cfg.Secrets.JWT.ActiveKID
+	if dir := cfg.Secrets.JWT.KeysDir; dir != "" {
+		dirKeys, err := loadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range dirKeys {
+			if err := add(key); err != nil {
+				return nil, err
+			}
+		}
+
+		if activeKID == "" {
+			activeKID, err = readActive(dir)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(keys) == 0 || activeKID == "" {
+		return keys, nil
+	}
+
+	for i := range keys {
+		if keys[i].KID == activeKID {
+			// The rest keep their order
+			rest := append(keys[:i:i], keys[i+1:]...)
+			return append([]Key{keys[i]}, rest...), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownKID, activeKID)
+}
+
+// GenerateKey writes a new pair of keys to the keys dir. It isn't active
+// unless it's the first one there, so the services learn it before it signs
+func GenerateKey(cfg *config.Config, kind string) (*Key, error) {
+	dir := cfg.Secrets.JWT.KeysDir
+	if dir == "" {
+		return nil, errors.New("keys dir isn't set")
+	}
+
+	var private crypto.Signer
+	var err error
+	switch kind {
+	case KeyRSA:
+		private, err = rsa.GenerateKey(rand.Reader, rsaBits)
+	case KeyEd25519:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	key := Key{
+		Public:  private.Public(),
+		Private: private,
+	}
+	key.KID, err = thumbprint(key.Public)
+	if err != nil {
+		return nil, err
+	}
+	key.Path = filepath.Join(dir, key.KID+".pub")
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(key.Public)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create keys dir: %w", err)
+	}
+
+	active, err := readActive(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writePEM(filepath.Join(dir, key.KID+".key"), "PRIVATE KEY", privateDER, 0o600); err != nil {
+		return nil, err
+	}
+	if err := writePEM(key.Path, "PUBLIC KEY", publicDER, 0o644); err != nil {
+		return nil, err
+	}
+
+	if active == "" {
+		if err := writeActive(dir, key.KID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &key, nil
+}
+
+// PromoteKey makes the key of the keys dir active. The rest are kept
+// to verify the tokens signed before
+func PromoteKey(cfg *config.Config, kid string) error {
+	dir := cfg.Secrets.JWT.KeysDir
+	if dir == "" {
+		return errors.New("keys dir isn't set")
+	}
+
+	keys, err := loadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if key.KID != kid {
+			continue
+		}
+		if key.Private == nil {
+			return fmt.Errorf("%w: %s", ErrNoPrivateKey, kid)
+		}
+
+		return writeActive(dir, kid)
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownKID, kid)
+}
+
+// loadDir reads the <kid>.pub keys n' their <kid>.key if there is one
+func loadDir(dir string) ([]Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read keys dir: %w", err)
+	}
+
+	var keys []Key
+	for _, entry := range entries {
+		kid, ok := strings.CutSuffix(entry.Name(), ".pub")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		privatePath := filepath.Join(dir, kid+".key")
+		if _, err := os.Stat(privatePath); err != nil {
+			privatePath = ""
+		}
+
+		key, err := loadKey(kid, filepath.Join(dir, entry.Name()), privatePath)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func loadKey(kid, publicPath, privatePath string) (Key, error) {
+	public, err := loadPublic(publicPath)
+	if err != nil {
+		return Key{}, err
+	}
+
+	if kid == "" {
+		kid, err = thumbprint(public)
+		if err != nil {
+			return Key{}, err
+		}
+	}
+
+	key := Key{
+		KID:    kid,
+		Public: public,
+		Path:   publicPath,
+	}
+	if privatePath == "" {
+		return key, nil
+	}
+
+	key.Private, err = loadPrivate(privatePath)
+	if err != nil {
+		return Key{}, err
+	}
+
+	equal, ok := key.Private.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !equal.Equal(public) {
+		return Key{}, fmt.Errorf("private key doesn't match public one of kid: %s", kid)
+	}
+
+	return key, nil
+}
+
+func loadPrivate(path string) (crypto.Signer, error) {
+	bytesKey, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(bytesKey); err == nil {
+		return key, nil
+	}
+
+	key, err := jwt.ParseEdPrivateKeyFromPEM(bytesKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	return key.(crypto.Signer), nil
+}
+
+func loadPublic(path string) (crypto.PublicKey, error) {
+	bytesKey, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(bytesKey); err == nil {
+		return key, nil
+	}
+
+	key, err := jwt.ParseEdPublicKeyFromPEM(bytesKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	return key, nil
+}
+
+func readActive(dir string) (string, error) {
+	bytes, err := os.ReadFile(filepath.Join(dir, activeFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read active kid: %w", err)
+	}
+
+	return strings.TrimSpace(string(bytes)), nil
+}
+
+// writeActive replaces the active file at once, so sso never reads half of it
+func writeActive(dir, kid string) error {
+	tmp := filepath.Join(dir, activeFile+".tmp")
+	if err := os.WriteFile(tmp, []byte(kid+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write active kid: %w", err)
+	}
+
+	if err := os.Rename(tmp, filepath.Join(dir, activeFile)); err != nil {
+		return fmt.Errorf("failed to write active kid: %w", err)
+	}
+
+	return nil
+}
+
+func writePEM(path, blockType string, der []byte, perm os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create key file: %w", err)
+	}
+	defer file.Close()
+
+	if err := pem.Encode(file, &pem.Block{Type: blockType, Bytes: der}); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+
+	return file.Close()
+}
+
+// thumbprint of the key (RFC 7638), the default kid
+func thumbprint(key crypto.PublicKey) (string, error) {
+	var members string
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		n, e := rsaParams(key)
+		members = `{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`
+	case ed25519.PublicKey:
+		members = `{"crv":"Ed25519","kty":"OKP","x":"` + base64.RawURLEncoding.EncodeToString(key) + `"}`
+	default:
+		return "", fmt.Errorf("unsupported key type: %T", key)
+	}
+
+	sum := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func rsaParams(key *rsa.PublicKey) (string, string) {
+	return base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+}
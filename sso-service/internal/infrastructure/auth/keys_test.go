@@ -0,0 +1,230 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+)
+
+func TestThumbprint(t *testing.T) {
+	// The example of RFC 8037, appendix A.3
+	x, err := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	if err != nil {
+		t.Fatalf("failed to decode key: %v", err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherRSA, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	testCases := []struct {
+		Name    string
+		Key     any
+		Want    string
+		WantErr bool
+	}{
+		{Name: "ed25519", Key: ed25519.PublicKey(x), Want: "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"},
+		{Name: "rsa", Key: &rsaKey.PublicKey},
+		{Name: "other_rsa", Key: &otherRSA.PublicKey},
+		{Name: "unsupported", Key: "key", WantErr: true},
+	}
+
+	seen := make(map[string]string)
+	for _, tc := range testCases {
+		got, err := thumbprint(tc.Key)
+		if (err != nil) != tc.WantErr {
+			t.Fatalf("%s: got %v, want error %v", tc.Name, err, tc.WantErr)
+		}
+		if err != nil {
+			continue
+		}
+
+		if tc.Want != "" && got != tc.Want {
+			t.Errorf("%s: got %s, want %s", tc.Name, got, tc.Want)
+		}
+		if again, _ := thumbprint(tc.Key); again != got {
+			t.Errorf("%s: got %s and %s for the same key", tc.Name, got, again)
+		}
+		if name, ok := seen[got]; ok {
+			t.Errorf("%s: got the thumbprint of %s", tc.Name, name)
+		}
+		seen[got] = tc.Name
+	}
+}
+
+func newKeysConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Secrets.JWT.KeysDir = filepath.Join(t.TempDir(), "keys")
+
+	return cfg
+}
+
+func generate(t *testing.T, cfg *config.Config, kind string) *Key {
+	t.Helper()
+
+	key, err := GenerateKey(cfg, kind)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	return key
+}
+
+func kids(keys []Key) []string {
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, key.KID)
+	}
+
+	return result
+}
+
+func TestLoadKeys(t *testing.T) {
+	cfg := newKeysConfig(t)
+	first := generate(t, cfg, KeyEd25519)
+	second := generate(t, cfg, KeyEd25519)
+
+	// The key of the config is outside the dir, its kid is the thumbprint
+	otherCfg := newKeysConfig(t)
+	configuredKey := generate(t, otherCfg, KeyEd25519)
+	configuredJWT := config.JWTKey{
+		PublicPath:  configuredKey.Path,
+		PrivatePath: filepath.Join(otherCfg.Secrets.JWT.KeysDir, configuredKey.KID+".key"),
+	}
+
+	testCases := []struct {
+		Name      string
+		Keys      []config.JWTKey
+		ActiveKID string
+		Want      []string
+		WantErr   error
+	}{
+		// The first generated key is active
+		{Name: "dir", Want: []string{first.KID, second.KID}},
+		{Name: "active_kid", ActiveKID: second.KID, Want: []string{second.KID, first.KID}},
+		{Name: "with_config", Keys: []config.JWTKey{configuredJWT}, Want: []string{first.KID, configuredKey.KID, second.KID}},
+		{Name: "config_active", Keys: []config.JWTKey{configuredJWT}, ActiveKID: configuredKey.KID, Want: []string{configuredKey.KID, first.KID, second.KID}},
+		{Name: "unknown_active", ActiveKID: "unknown", WantErr: ErrUnknownKID},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := *cfg
+			cfg.Secrets.JWT.Keys = tc.Keys
+			cfg.Secrets.JWT.ActiveKID = tc.ActiveKID
+
+			keys, err := LoadKeys(&cfg)
+			if !errors.Is(err, tc.WantErr) {
+				t.Fatalf("got %v, want %v", err, tc.WantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got := kids(keys)
+			// The active key is first, the order of the dir is up to the fs
+			if got[0] != tc.Want[0] || !sameKIDs(got, tc.Want) {
+				t.Errorf("got %v, want %v", got, tc.Want)
+			}
+			for _, key := range keys {
+				if key.Private == nil {
+					t.Errorf("key %s has no private key", key.KID)
+				}
+			}
+		})
+	}
+}
+
+func sameKIDs(got, want []string) bool {
+	got, want = slices.Clone(got), slices.Clone(want)
+	slices.Sort(got)
+	slices.Sort(want)
+
+	return slices.Equal(got, want)
+}
+
+func TestLoadKeysDuplicate(t *testing.T) {
+	cfg := newKeysConfig(t)
+	key := generate(t, cfg, KeyEd25519)
+
+	// The key of the dir is in the config too
+	cfg.Secrets.JWT.Keys = []config.JWTKey{{
+		PublicPath:  key.Path,
+		PrivatePath: filepath.Join(cfg.Secrets.JWT.KeysDir, key.KID+".key"),
+	}}
+
+	if _, err := LoadKeys(cfg); err == nil {
+		t.Errorf("got nil, want duplicate kid error")
+	}
+}
+
+func TestPromoteKey(t *testing.T) {
+	cfg := newKeysConfig(t)
+	first := generate(t, cfg, KeyEd25519)
+	second := generate(t, cfg, KeyRSA)
+	publicOnly := generate(t, cfg, KeyEd25519)
+	if err := os.Remove(filepath.Join(cfg.Secrets.JWT.KeysDir, publicOnly.KID+".key")); err != nil {
+		t.Fatalf("failed to remove private key: %v", err)
+	}
+
+	testCases := []struct {
+		Name       string
+		KID        string
+		WantErr    error
+		WantActive string
+	}{
+		{Name: "promoted", KID: second.KID, WantActive: second.KID},
+		{Name: "back", KID: first.KID, WantActive: first.KID},
+		{Name: "no_private", KID: publicOnly.KID, WantErr: ErrNoPrivateKey, WantActive: first.KID},
+		{Name: "unknown", KID: "unknown", WantErr: ErrUnknownKID, WantActive: first.KID},
+	}
+
+	// The cases go in order, each one starts from the active key of the previous
+	for _, tc := range testCases {
+		if err := PromoteKey(cfg, tc.KID); !errors.Is(err, tc.WantErr) {
+			t.Fatalf("%s: got %v, want %v", tc.Name, err, tc.WantErr)
+		}
+
+		active, err := readActive(cfg.Secrets.JWT.KeysDir)
+		if err != nil {
+			t.Fatalf("%s: failed to read active kid: %v", tc.Name, err)
+		}
+		if active != tc.WantActive {
+			t.Errorf("%s: got active %s, want %s", tc.Name, active, tc.WantActive)
+		}
+
+		keys, err := LoadKeys(cfg)
+		if err != nil {
+			t.Fatalf("%s: failed to load keys: %v", tc.Name, err)
+		}
+		if keys[0].KID != tc.WantActive {
+			t.Errorf("%s: got first key %s, want %s", tc.Name, keys[0].KID, tc.WantActive)
+		}
+		if len(keys) != 3 {
+			t.Errorf("%s: got %d keys, want 3", tc.Name, len(keys))
+		}
+	}
+}
+
+func TestPromoteKeyNoDir(t *testing.T) {
+	if err := PromoteKey(&config.Config{}, "kid"); err == nil {
+		t.Errorf("got nil, want error")
+	}
+}
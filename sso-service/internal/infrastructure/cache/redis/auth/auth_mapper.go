@@ -1,19 +1,44 @@
 package authredis
 
-import "github.com/devathh/coderun/sso-service/internal/domain/auth"
+import (
+	"net/mail"
+
+	"github.com/devathh/coderun/sso-service/internal/domain/auth"
+	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
+)
 
 func toModel(session *auth.Session) *SessionModel {
+	device := session.Device()
+
 	return &SessionModel{
-		UserID: session.UserID(),
-		Email:  session.Email(),
+		ID:            session.ID(),
+		UserID:        session.UserID(),
+		Email:         session.Email(),
+		EmailVerified: session.EmailVerified(),
+		IP:            device.IP,
+		UserAgent:     device.UserAgent,
+		Device:        device.Label,
+		CreatedAt:     session.CreatedAt(),
+		LastUsedAt:    session.LastUsedAt(),
 	}
 }
 
 func toDomain(model *SessionModel) (*auth.Session, error) {
-	session, err := auth.NewSession(model.UserID, model.Email)
-	if err != nil {
-		return nil, err
+	if _, err := mail.ParseAddress(model.Email); err != nil {
+		return nil, customerrors.ErrInvalidEmail
 	}
 
-	return session, nil
+	return auth.SessionFrom(
+		model.ID,
+		model.UserID,
+		model.Email,
+		model.EmailVerified,
+		auth.Device{
+			IP:        model.IP,
+			UserAgent: model.UserAgent,
+			Label:     model.Device,
+		},
+		model.CreatedAt,
+		model.LastUsedAt,
+	), nil
 }
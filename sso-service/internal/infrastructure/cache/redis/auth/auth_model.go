@@ -1,8 +1,20 @@
 package authredis
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type SessionModel struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	Email      string    `json:"email"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	Device     string    `json:"device"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	// False in the sessions created before the verification
+	EmailVerified bool `json:"email_verified"`
 }
@@ -8,12 +8,103 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/devathh/coderun/shared/tracing"
 	"github.com/devathh/coderun/sso-service/internal/domain/auth"
 	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
 	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis/auth")
+
+// Prefix of the index of the user's sessions, the scripts build it too
+const indexPrefix = "rtks_"
+
+// The sessions of the user are indexed in a hash of their ids n' keys.
+// The scripts change the index along with the sessions. They touch the keys
+// read from the index (n' deleteFamily builds the key of the index itself),
+// which aren't in KEYS, so sso supports a single redis node only, not a cluster
+
+// Deletes the session n' its entry in the index, if it's still the key
+// of the session there (a refresh moves the id to the new key)
+var deleteSession = redis.NewScript(`
+redis.call('DEL', KEYS[1])
+if redis.call('HGET', KEYS[2], ARGV[1]) == KEYS[1] then
+	redis.call('HDEL', KEYS[2], ARGV[1])
+end
+
+return 1
+`)
+
+// Deletes the session by its id, 0 if the user hasn't such session
+var deleteSessionByID = redis.NewScript(`
+local key = redis.call('HGET', KEYS[1], ARGV[1])
+if not key then
+	return 0
+end
+redis.call('DEL', key)
+redis.call('HDEL', KEYS[1], ARGV[1])
+
+return 1
+`)
+
+// Moves the session to the new refresh token, if the old one is still its token.
+// The old one is remembered as rotated, so its reuse is noticed.
+// Returns 0 if the old token isn't valid
+var rotateSession = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+redis.call('SET', KEYS[2], ARGV[1], 'PX', ARGV[2])
+redis.call('HSET', KEYS[3], ARGV[3], KEYS[2])
+redis.call('PEXPIRE', KEYS[3], ARGV[2])
+redis.call('HSET', KEYS[4], 'user_id', ARGV[4], 'session_id', ARGV[3])
+redis.call('PEXPIRE', KEYS[4], ARGV[2])
+
+return 1
+`)
+
+// Deletes the family of the rotated token: the session with the current one.
+// The token is forgotten as rotated, so its next replays aren't reuses anymore.
+// Returns {user id, session id} or nothing if the token wasn't rotated
+var deleteFamily = redis.NewScript(`
+local family = redis.call('HMGET', KEYS[1], 'user_id', 'session_id')
+if not family[1] or not family[2] then
+	return false
+end
+redis.call('DEL', KEYS[1])
+
+local index = ARGV[1] .. family[1]
+local key = redis.call('HGET', index, family[2])
+if key then
+	redis.call('DEL', key)
+	redis.call('HDEL', index, family[2])
+end
+
+return family
+`)
+
+// Deletes the sessions of the index n' the index itself at once,
+// so a session created meanwhile isn't left out of it. Returns their ids
+var deleteIndexed = redis.NewScript(`
+local entries = redis.call('HGETALL', KEYS[1])
+local ids = {}
+local keys = {}
+for i = 1, #entries, 2 do
+	ids[#ids + 1] = entries[i]
+	keys[#keys + 1] = entries[i + 1]
+end
+for i = 1, #keys, 500 do
+	redis.call('DEL', unpack(keys, i, math.min(i + 499, #keys)))
+end
+redis.call('DEL', KEYS[1])
+
+return ids
+`)
+
 type AuthRedis struct {
 	cfg    *config.Config
 	client *redis.Client
@@ -30,7 +121,10 @@ func New(cfg *config.Config, client *redis.Client) (*AuthRedis, error) {
 	}, nil
 }
 
-func (ar *AuthRedis) CreateSession(ctx context.Context, refresh string, session *auth.Session) error {
+func (ar *AuthRedis) CreateSession(ctx context.Context, refresh string, session *auth.Session) (err error) {
+	ctx, span := tracer.Start(ctx, "authredis.CreateSession")
+	defer func() { tracing.End(span, err) }()
+
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -42,14 +136,25 @@ func (ar *AuthRedis) CreateSession(ctx context.Context, refresh string, session
 	}
 
 	key := ar.generateKey(refresh)
-	if err := ar.client.Set(ctx, key, bytesModel, ar.cfg.Secrets.Redis.RefreshTTL).Err(); err != nil {
+	index := ar.generateIndexKey(session.UserID())
+	if _, err := ar.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, bytesModel, ar.cfg.Secrets.Redis.RefreshTTL)
+		pipe.HSet(ctx, index, session.ID().String(), key)
+		// The index lives as long as the newest session of the user,
+		// the expired ones in it are just deleted once more on logout
+		pipe.Expire(ctx, index, ar.cfg.Secrets.Redis.RefreshTTL)
+		return nil
+	}); err != nil {
 		return fmt.Errorf("failed to save session: %w", err)
 	}
 
 	return nil
 }
 
-func (ar *AuthRedis) GetSession(ctx context.Context, refresh string) (*auth.Session, error) {
+func (ar *AuthRedis) GetSession(ctx context.Context, refresh string) (_ *auth.Session, err error) {
+	ctx, span := tracer.Start(ctx, "authredis.GetSession")
+	defer func() { tracing.End(span, err) }()
+
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -76,24 +181,227 @@ func (ar *AuthRedis) GetSession(ctx context.Context, refresh string) (*auth.Sess
 	return session, nil
 }
 
-func (ar *AuthRedis) DeleteSession(ctx context.Context, refresh string) error {
+func (ar *AuthRedis) DeleteSession(ctx context.Context, refresh string) (_ uuid.UUID, err error) {
+	ctx, span := tracer.Start(ctx, "authredis.DeleteSession")
+	defer func() { tracing.End(span, err) }()
+
 	if err := ctx.Err(); err != nil {
-		return err
+		return uuid.Nil, err
 	}
 
+	// The session tells whose index it's in
 	key := ar.generateKey(refresh)
-	if err := ar.client.Del(ctx, key).Err(); err != nil {
+	bytesModel, err := ar.client.Get(ctx, key).Bytes()
+	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			return customerrors.ErrNoSessions
+			return uuid.Nil, customerrors.ErrNoSessions
 		}
+		return uuid.Nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	var model SessionModel
+	if err := json.Unmarshal(bytesModel, &model); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to unmarshal session: %w", err)
+	}
 
-		return fmt.Errorf("failed to delete redis: %w", err)
+	if err := deleteSession.Run(ctx, ar.client,
+		[]string{key, ar.generateIndexKey(model.UserID)},
+		model.ID.String(),
+	).Err(); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return model.ID, nil
+}
+
+func (ar *AuthRedis) RotateSession(ctx context.Context, refresh, newRefresh string, session *auth.Session) (err error) {
+	ctx, span := tracer.Start(ctx, "authredis.RotateSession")
+	defer func() { tracing.End(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	model := toModel(session)
+	bytesModel, err := json.Marshal(model)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	rotated, err := rotateSession.Run(ctx, ar.client,
+		[]string{
+			ar.generateKey(refresh),
+			ar.generateKey(newRefresh),
+			ar.generateIndexKey(session.UserID()),
+			ar.generateRotatedKey(refresh),
+		},
+		bytesModel,
+		ar.cfg.Secrets.Redis.RefreshTTL.Milliseconds(),
+		session.ID().String(),
+		session.UserID().String(),
+	).Int()
+	if err != nil {
+		return fmt.Errorf("failed to rotate session: %w", err)
+	}
+	if rotated == 0 {
+		return customerrors.ErrNoSessions
 	}
 
 	return nil
 }
 
+func (ar *AuthRedis) DeleteFamily(ctx context.Context, refresh string) (userID, sessionID uuid.UUID, err error) {
+	ctx, span := tracer.Start(ctx, "authredis.DeleteFamily")
+	defer func() { tracing.End(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	family, err := deleteFamily.Run(ctx, ar.client,
+		[]string{ar.generateRotatedKey(refresh)},
+		indexPrefix,
+	).StringSlice()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return uuid.Nil, uuid.Nil, customerrors.ErrNoSessions
+		}
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to delete family: %w", err)
+	}
+	if len(family) != 2 {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to delete family: unexpected reply %v", family)
+	}
+
+	if userID, err = uuid.Parse(family[0]); err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to parse user id: %w", err)
+	}
+	if sessionID, err = uuid.Parse(family[1]); err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to parse session id: %w", err)
+	}
+
+	return userID, sessionID, nil
+}
+
+func (ar *AuthRedis) GetSessions(ctx context.Context, userID uuid.UUID) (_ []*auth.Session, err error) {
+	ctx, span := tracer.Start(ctx, "authredis.GetSessions")
+	defer func() { tracing.End(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	index := ar.generateIndexKey(userID)
+	entries, err := ar.client.HGetAll(ctx, index).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index of sessions: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(entries))
+	keys := make([]string, 0, len(entries))
+	for id, key := range entries {
+		ids = append(ids, id)
+		keys = append(keys, key)
+	}
+
+	values, err := ar.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	sessions := make([]*auth.Session, 0, len(values))
+	var expired []string
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+
+		var model SessionModel
+		if err := json.Unmarshal([]byte(raw), &model); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+		}
+
+		session, err := toDomain(&model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert to domain: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	// The index isn't notified when the sessions expire
+	if len(expired) > 0 {
+		if err := ar.client.HDel(ctx, index, expired...).Err(); err != nil {
+			return nil, fmt.Errorf("failed to clear expired sessions: %w", err)
+		}
+	}
+
+	return sessions, nil
+}
+
+func (ar *AuthRedis) DeleteSessionByID(ctx context.Context, userID, sessionID uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "authredis.DeleteSessionByID")
+	defer func() { tracing.End(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	deleted, err := deleteSessionByID.Run(ctx, ar.client,
+		[]string{ar.generateIndexKey(userID)},
+		sessionID.String(),
+	).Int()
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if deleted == 0 {
+		return customerrors.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (ar *AuthRedis) DeleteAllSessions(ctx context.Context, userID uuid.UUID) (_ []uuid.UUID, err error) {
+	ctx, span := tracer.Start(ctx, "authredis.DeleteAllSessions")
+	defer func() { tracing.End(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	index := ar.generateIndexKey(userID)
+	rawIDs, err := deleteIndexed.Run(ctx, ar.client, []string{index}).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(rawIDs))
+	for _, rawID := range rawIDs {
+		id, err := uuid.Parse(rawID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse session id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
 func (ar *AuthRedis) generateKey(refresh string) string {
 	bytes := sha256.Sum256([]byte(refresh))
 	return "rtk_" + hex.EncodeToString(bytes[:])
 }
+
+// generateIndexKey is the key of the index of the user's sessions
+func (ar *AuthRedis) generateIndexKey(userID uuid.UUID) string {
+	return indexPrefix + userID.String()
+}
+
+// generateRotatedKey is the key of the family of the rotated refresh token
+func (ar *AuthRedis) generateRotatedKey(refresh string) string {
+	bytes := sha256.Sum256([]byte(refresh))
+	return "rtkr_" + hex.EncodeToString(bytes[:])
+}
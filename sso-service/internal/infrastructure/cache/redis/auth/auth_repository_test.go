@@ -0,0 +1,356 @@
+package authredis
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/devathh/coderun/sso-service/internal/domain/auth"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func newAuthRedis(t *testing.T) (*AuthRedis, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := &config.Config{}
+	cfg.Secrets.Redis.RefreshTTL = time.Hour
+
+	ar, err := New(cfg, client)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	return ar, server
+}
+
+func newSession(t *testing.T, userID uuid.UUID) *auth.Session {
+	t.Helper()
+
+	session, err := auth.NewSession(userID, "user@coderun.dev", true, auth.Device{IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	return session
+}
+
+// login creates a session of the user with the refresh token
+func login(t *testing.T, ar *AuthRedis, userID uuid.UUID, refresh string) *auth.Session {
+	t.Helper()
+
+	session := newSession(t, userID)
+	if err := ar.CreateSession(t.Context(), refresh, session); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	return session
+}
+
+func TestRotateSessionReuse(t *testing.T) {
+	ar, server := newAuthRedis(t)
+	userID := uuid.New()
+	session := login(t, ar, userID, "rt_first")
+
+	if err := ar.RotateSession(t.Context(), "rt_first", "rt_second", session); err != nil {
+		t.Fatalf("failed to rotate session: %v", err)
+	}
+	if _, err := ar.GetSession(t.Context(), "rt_first"); !errors.Is(err, customerrors.ErrNoSessions) {
+		t.Errorf("got %v for the rotated token, want %v", err, customerrors.ErrNoSessions)
+	}
+	if got, err := ar.GetSession(t.Context(), "rt_second"); err != nil || got.ID() != session.ID() {
+		t.Fatalf("got %v, %v for the new token, want the session", got, err)
+	}
+
+	// The rotated token can't be rotated again, it's a reuse
+	if err := ar.RotateSession(t.Context(), "rt_first", "rt_third", session); !errors.Is(err, customerrors.ErrNoSessions) {
+		t.Fatalf("got %v, want %v", err, customerrors.ErrNoSessions)
+	}
+
+	gotUser, gotSession, err := ar.DeleteFamily(t.Context(), "rt_first")
+	if err != nil {
+		t.Fatalf("failed to delete family: %v", err)
+	}
+	if gotUser != userID || gotSession != session.ID() {
+		t.Errorf("got %s %s, want %s %s", gotUser, gotSession, userID, session.ID())
+	}
+
+	// The whole family is revoked: the current token n' the entry of the index
+	if _, err := ar.GetSession(t.Context(), "rt_second"); !errors.Is(err, customerrors.ErrNoSessions) {
+		t.Errorf("got %v for the current token, want %v", err, customerrors.ErrNoSessions)
+	}
+	if sessions, err := ar.GetSessions(t.Context(), userID); err != nil || len(sessions) != 0 {
+		t.Errorf("got %d sessions, %v, want none", len(sessions), err)
+	}
+
+	// The replays of the same token aren't counted as reuses again
+	if _, _, err := ar.DeleteFamily(t.Context(), "rt_first"); !errors.Is(err, customerrors.ErrNoSessions) {
+		t.Errorf("got %v on replay, want %v", err, customerrors.ErrNoSessions)
+	}
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("got keys %v left, want none", keys)
+	}
+}
+
+func TestDeleteFamily(t *testing.T) {
+	testCases := []struct {
+		Name string
+		// Rotations of the token before the reuse
+		Rotations int
+		WantErr   error
+	}{
+		{Name: "rotated", Rotations: 1},
+		{Name: "rotated_twice", Rotations: 2},
+		// The current token isn't a reuse
+		{Name: "current", Rotations: 0, WantErr: customerrors.ErrNoSessions},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			ar, _ := newAuthRedis(t)
+			userID := uuid.New()
+			session := login(t, ar, userID, "rt_0")
+			// Another session of the user isn't in the family
+			other := login(t, ar, userID, "rt_other")
+
+			current := "rt_0"
+			for i := range tc.Rotations {
+				next := "rt_" + string(rune('1'+i))
+				if err := ar.RotateSession(t.Context(), current, next, session); err != nil {
+					t.Fatalf("failed to rotate session: %v", err)
+				}
+				current = next
+			}
+
+			if _, _, err := ar.DeleteFamily(t.Context(), "rt_0"); !errors.Is(err, tc.WantErr) {
+				t.Fatalf("got %v, want %v", err, tc.WantErr)
+			}
+
+			sessions, err := ar.GetSessions(t.Context(), userID)
+			if err != nil {
+				t.Fatalf("failed to get sessions: %v", err)
+			}
+			want := 2
+			if tc.WantErr == nil {
+				want = 1
+			}
+			if len(sessions) != want {
+				t.Errorf("got %d sessions, want %d", len(sessions), want)
+			}
+			if _, err := ar.GetSession(t.Context(), "rt_other"); err != nil {
+				t.Errorf("got %v for the other session %s, want it kept", err, other.ID())
+			}
+		})
+	}
+}
+
+func TestRotateSessionConcurrent(t *testing.T) {
+	ar, _ := newAuthRedis(t)
+	userID := uuid.New()
+	session := login(t, ar, userID, "rt_first")
+
+	// Two refreshes with the same token, only one of them gets the session
+	next := []string{"rt_a", "rt_b"}
+	errs := make([]error, len(next))
+	var wg sync.WaitGroup
+	for i, refresh := range next {
+		wg.Go(func() {
+			errs[i] = ar.RotateSession(t.Context(), "rt_first", refresh, session)
+		})
+	}
+	wg.Wait()
+
+	winner := -1
+	for i, err := range errs {
+		switch {
+		case err == nil && winner == -1:
+			winner = i
+		case err == nil:
+			t.Fatalf("both refreshes rotated the session")
+		case !errors.Is(err, customerrors.ErrNoSessions):
+			t.Fatalf("got %v, want %v", err, customerrors.ErrNoSessions)
+		}
+	}
+	if winner == -1 {
+		t.Fatalf("no refresh rotated the session")
+	}
+
+	if _, err := ar.GetSession(t.Context(), next[winner]); err != nil {
+		t.Errorf("got %v for the winner, want the session", err)
+	}
+	if _, err := ar.GetSession(t.Context(), next[1-winner]); !errors.Is(err, customerrors.ErrNoSessions) {
+		t.Errorf("got %v for the loser, want %v", err, customerrors.ErrNoSessions)
+	}
+	if sessions, err := ar.GetSessions(t.Context(), userID); err != nil || len(sessions) != 1 {
+		t.Errorf("got %d sessions, %v, want 1", len(sessions), err)
+	}
+}
+
+func TestDeleteSession(t *testing.T) {
+	ar, server := newAuthRedis(t)
+	userID := uuid.New()
+	session := login(t, ar, userID, "rt_first")
+	login(t, ar, userID, "rt_second")
+
+	gotID, err := ar.DeleteSession(t.Context(), "rt_first")
+	if err != nil {
+		t.Fatalf("failed to delete session: %v", err)
+	}
+	if gotID != session.ID() {
+		t.Errorf("got %s, want %s", gotID, session.ID())
+	}
+
+	// The entry of the index goes with the session
+	index := ar.generateIndexKey(userID)
+	if fields, _ := server.HKeys(index); len(fields) != 1 {
+		t.Errorf("got %d entries in the index, want 1", len(fields))
+	}
+	if _, err := ar.GetSession(t.Context(), "rt_first"); !errors.Is(err, customerrors.ErrNoSessions) {
+		t.Errorf("got %v, want %v", err, customerrors.ErrNoSessions)
+	}
+	if _, err := ar.DeleteSession(t.Context(), "rt_first"); !errors.Is(err, customerrors.ErrNoSessions) {
+		t.Errorf("got %v on the second logout, want %v", err, customerrors.ErrNoSessions)
+	}
+}
+
+func TestDeleteAllSessions(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Sessions int
+	}{
+		{Name: "no_sessions", Sessions: 0},
+		{Name: "one", Sessions: 1},
+		{Name: "many", Sessions: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			ar, server := newAuthRedis(t)
+			userID := uuid.New()
+			want := make(map[uuid.UUID]bool)
+			for i := range tc.Sessions {
+				want[login(t, ar, userID, "rt_"+string(rune('a'+i))).ID()] = true
+			}
+			// The sessions of another user are kept
+			otherID := uuid.New()
+			login(t, ar, otherID, "rt_other")
+
+			ids, err := ar.DeleteAllSessions(t.Context(), userID)
+			if err != nil {
+				t.Fatalf("failed to delete sessions: %v", err)
+			}
+			if len(ids) != len(want) {
+				t.Fatalf("got %d ids, want %d", len(ids), len(want))
+			}
+			for _, id := range ids {
+				if !want[id] {
+					t.Errorf("got id %s of another session", id)
+				}
+			}
+
+			// The index is deleted along with the sessions
+			if server.Exists(ar.generateIndexKey(userID)) {
+				t.Errorf("got the index left, want it deleted")
+			}
+			for i := range tc.Sessions {
+				if _, err := ar.GetSession(t.Context(), "rt_"+string(rune('a'+i))); !errors.Is(err, customerrors.ErrNoSessions) {
+					t.Errorf("got %v for session %d, want %v", err, i, customerrors.ErrNoSessions)
+				}
+			}
+			if _, err := ar.GetSession(t.Context(), "rt_other"); err != nil {
+				t.Errorf("got %v for the other user, want the session", err)
+			}
+			if !server.Exists(ar.generateIndexKey(otherID)) {
+				t.Errorf("got the index of the other user deleted")
+			}
+		})
+	}
+}
+
+func TestGetSessionsExpired(t *testing.T) {
+	ar, server := newAuthRedis(t)
+	userID := uuid.New()
+	kept := login(t, ar, userID, "rt_kept")
+	expired := login(t, ar, userID, "rt_expired")
+
+	// The session expires before the index, which lives as long as the newest one
+	server.SetTTL(ar.generateKey("rt_expired"), time.Minute)
+	server.FastForward(2 * time.Minute)
+
+	sessions, err := ar.GetSessions(t.Context(), userID)
+	if err != nil {
+		t.Fatalf("failed to get sessions: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID() != kept.ID() {
+		t.Fatalf("got %d sessions, want only the kept one", len(sessions))
+	}
+
+	// The entry of the expired session is dropped from the index
+	fields, err := server.HKeys(ar.generateIndexKey(userID))
+	if err != nil {
+		t.Fatalf("failed to get index: %v", err)
+	}
+	if len(fields) != 1 || fields[0] != kept.ID().String() {
+		t.Errorf("got index %v, want only %s", fields, kept.ID())
+	}
+	if err := ar.DeleteSessionByID(t.Context(), userID, expired.ID()); !errors.Is(err, customerrors.ErrSessionNotFound) {
+		t.Errorf("got %v for the expired session, want %v", err, customerrors.ErrSessionNotFound)
+	}
+}
+
+func TestDeleteSessionByID(t *testing.T) {
+	testCases := []struct {
+		Name string
+		// The session is of another user
+		Other   bool
+		Unknown bool
+		WantErr error
+	}{
+		{Name: "own"},
+		{Name: "other_user", Other: true, WantErr: customerrors.ErrSessionNotFound},
+		{Name: "unknown", Unknown: true, WantErr: customerrors.ErrSessionNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			ar, _ := newAuthRedis(t)
+			userID := uuid.New()
+			session := login(t, ar, userID, "rt_own")
+			other := login(t, ar, uuid.New(), "rt_other")
+
+			sessionID := session.ID()
+			switch {
+			case tc.Other:
+				sessionID = other.ID()
+			case tc.Unknown:
+				sessionID = uuid.New()
+			}
+
+			if err := ar.DeleteSessionByID(t.Context(), userID, sessionID); !errors.Is(err, tc.WantErr) {
+				t.Fatalf("got %v, want %v", err, tc.WantErr)
+			}
+
+			_, err := ar.GetSession(t.Context(), "rt_own")
+			if deleted := errors.Is(err, customerrors.ErrNoSessions); deleted != (tc.WantErr == nil) {
+				t.Errorf("got deleted %v, want %v", deleted, tc.WantErr == nil)
+			}
+			if _, err := ar.GetSession(t.Context(), "rt_other"); err != nil {
+				t.Errorf("got %v for the session of another user, want it kept", err)
+			}
+		})
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"net"
 
 	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/metrics"
 	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
 	"github.com/redis/go-redis/v9"
 )
 
+// Connect connects to a single node. The scripts of the sessions
+// touch keys that aren't in their KEYS, so a cluster isn't supported
 func Connect(cfg *config.Config) (*redis.Client, error) {
 	if cfg == nil {
 		return nil, customerrors.ErrNilArgs
@@ -24,6 +27,8 @@ func Connect(cfg *config.Config) (*redis.Client, error) {
 		DB:       0,
 	})
 
+	client.AddHook(metrics.RedisHook{})
+
 	if err := client.Ping(context.Background()).Err(); err != nil {
 		return nil, fmt.Errorf("failed to ping redis: %w", err)
 	}
@@ -31,6 +36,12 @@ func Connect(cfg *config.Config) (*redis.Client, error) {
 	return client, nil
 }
 
+func Ping(client *redis.Client) func(context.Context) error {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
 func Close(client *redis.Client) error {
 	return client.Conn().Close()
 }
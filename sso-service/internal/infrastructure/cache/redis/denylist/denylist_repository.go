@@ -0,0 +1,64 @@
+package denylistredis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/devathh/coderun/shared/denylist"
+	"github.com/devathh/coderun/shared/tracing"
+	"github.com/devathh/coderun/sso-service/internal/domain/auth"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis/denylist")
+
+// Denylist of the revoked sessions. An entry lives as long as an access token,
+// the tokens issued before the revocation are expired by then.
+// xcutr reads the same keys
+type Denylist struct {
+	cfg     *config.Config
+	client  *redis.Client
+	checker *denylist.Checker
+}
+
+func New(cfg *config.Config, client *redis.Client) (*Denylist, error) {
+	if cfg == nil || client == nil {
+		return nil, customerrors.ErrNilArgs
+	}
+
+	return &Denylist{
+		cfg:     cfg,
+		client:  client,
+		checker: denylist.NewChecker(client, cfg.Denylist.CacheTTL),
+	}, nil
+}
+
+func (d *Denylist) RevokeSessions(ctx context.Context, sessionIDs ...uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "denylistredis.RevokeSessions")
+	defer func() { tracing.End(span, err) }()
+
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+
+	if _, err := d.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range sessionIDs {
+			pipe.Set(ctx, denylist.Key(id), 1, d.cfg.Secrets.JWT.TTL)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	d.checker.Revoked(sessionIDs...)
+
+	return nil
+}
+
+func (d *Denylist) IsRevoked(ctx context.Context, claims *auth.CoderunClaims) (bool, error) {
+	return d.checker.IsRevoked(ctx, claims.SessionID)
+}
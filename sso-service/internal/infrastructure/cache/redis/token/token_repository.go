@@ -0,0 +1,115 @@
+package tokenredis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devathh/coderun/shared/tracing"
+	"github.com/devathh/coderun/sso-service/internal/domain/auth"
+	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/devathh/coderun/sso-service/internal/infrastructure/cache/redis/token")
+
+// The token keeps its user, the user keeps the key of the last token,
+// so a new token revokes the previous one. The scripts touch the keys they
+// read, which aren't in KEYS, so like the sessions they need a single redis node
+
+// Saves the token n' deletes the previous one of the user
+var saveToken = redis.NewScript(`
+local previous = redis.call('GET', KEYS[2])
+if previous then
+	redis.call('DEL', previous)
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+redis.call('SET', KEYS[2], KEYS[1], 'PX', ARGV[2])
+
+return 1
+`)
+
+// Deletes the token n' returns its user, nothing if there is no such token
+var consumeToken = redis.NewScript(`
+local userID = redis.call('GETDEL', KEYS[1])
+if not userID then
+	return false
+end
+
+local userKey = ARGV[1] .. userID
+if redis.call('GET', userKey) == KEYS[1] then
+	redis.call('DEL', userKey)
+end
+
+return userID
+`)
+
+type TokenRedis struct {
+	client *redis.Client
+}
+
+func New(client *redis.Client) (*TokenRedis, error) {
+	if client == nil {
+		return nil, customerrors.ErrNilArgs
+	}
+
+	return &TokenRedis{
+		client: client,
+	}, nil
+}
+
+func (tr *TokenRedis) SaveToken(ctx context.Context, kind auth.TokenKind, token string, userID uuid.UUID, ttl time.Duration) (err error) {
+	ctx, span := tracer.Start(ctx, "tokenredis.SaveToken")
+	defer func() { tracing.End(span, err) }()
+
+	if token == "" || userID == uuid.Nil {
+		return customerrors.ErrNilArgs
+	}
+
+	keys := []string{generateKey(kind, token), userPrefix(kind) + userID.String()}
+	if err := saveToken.Run(ctx, tr.client, keys, userID.String(), ttl.Milliseconds()).Err(); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+
+	return nil
+}
+
+func (tr *TokenRedis) ConsumeToken(ctx context.Context, kind auth.TokenKind, token string) (_ uuid.UUID, err error) {
+	ctx, span := tracer.Start(ctx, "tokenredis.ConsumeToken")
+	defer func() { tracing.End(span, err) }()
+
+	if token == "" {
+		return uuid.Nil, customerrors.ErrInvalidToken
+	}
+
+	rawID, err := consumeToken.Run(ctx, tr.client, []string{generateKey(kind, token)}, userPrefix(kind)).Text()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return uuid.Nil, customerrors.ErrInvalidToken
+		}
+
+		return uuid.Nil, fmt.Errorf("failed to consume token: %w", err)
+	}
+
+	userID, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user id of token: %w", err)
+	}
+
+	return userID, nil
+}
+
+// The token is hashed, so the keys don't leak the tokens
+func generateKey(kind auth.TokenKind, token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return "token:" + string(kind) + ":" + hex.EncodeToString(hash[:])
+}
+
+func userPrefix(kind auth.TokenKind) string {
+	return "token:" + string(kind) + ":user:"
+}
@@ -33,7 +33,23 @@ type server struct {
 			Enable bool `yaml:"enable"`
 		} `yaml:"tls"`
 	} `yaml:"grpc"`
-	Timeout time.Duration `yaml:"timeout"`
+	// grpc.health.v1 checks of the dependencies
+	Health struct {
+		Interval time.Duration `yaml:"interval"`
+		// Time of one check of every dependency
+		Timeout time.Duration `yaml:"timeout"`
+	} `yaml:"health"`
+	// Prometheus metrics on /metrics, disabled by default
+	Metrics struct {
+		Enable bool   `yaml:"enable"`
+		Host   string `yaml:"host"`
+		Port   int    `yaml:"port"`
+	} `yaml:"metrics"`
+	// The ip of the client is taken from x-forwarded-for set by the gateway,
+	// for the rate limits n' the sessions. Only for the services that can't
+	// be reached bypassing it
+	TrustForwarded bool          `yaml:"trust-forwarded"`
+	Timeout        time.Duration `yaml:"timeout"`
 }
 
 func (s *server) validate() error {
@@ -46,6 +62,20 @@ func (s *server) validate() error {
 		s.GRPC.Protocol = "tcp"
 	}
 
+	if s.Health.Interval <= 0 {
+		s.Health.Interval = 10 * time.Second
+	}
+	if s.Health.Timeout <= 0 {
+		s.Health.Timeout = 3 * time.Second
+	}
+	if s.Health.Timeout > s.Health.Interval {
+		return errors.New("health timeout is larger than interval")
+	}
+
+	if s.Metrics.Enable && s.Metrics.Port <= 0 {
+		return errors.New("invalid metrics port")
+	}
+
 	if s.Timeout < time.Millisecond {
 		return errors.New("invalid timeout")
 	}
@@ -53,21 +83,169 @@ func (s *server) validate() error {
 	return nil
 }
 
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+type tracing struct {
+	Enable bool `yaml:"enable"`
+	// otlp (over grpc) or stdout for local use
+	Exporter string `yaml:"exporter"`
+	// host:port of the otlp collector
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"`
+	// Share of the traces started here that are recorded,
+	// the ones continued from the caller follow its decision
+	SampleRatio float64 `yaml:"sample-ratio"`
+}
+
+func (t *tracing) validate() error {
+	if !t.Enable {
+		return nil
+	}
+
+	if t.Exporter == "" {
+		t.Exporter = ExporterOTLP
+	}
+	if t.Exporter != ExporterOTLP && t.Exporter != ExporterStdout {
+		return fmt.Errorf("invalid exporter: %s", t.Exporter)
+	}
+	if t.Exporter == ExporterOTLP && t.Endpoint == "" {
+		t.Endpoint = "localhost:4317"
+	}
+	if t.SampleRatio <= 0 {
+		t.SampleRatio = 1
+	}
+	if t.SampleRatio > 1 {
+		return errors.New("sample ratio is greater than 1")
+	}
+
+	return nil
+}
+
+const (
+	RateLimitByIP   = "ip"
+	RateLimitByUser = "user"
+)
+
+type RateLimitRule struct {
+	// Full name of the grpc method, like /sso.v1.SSO/Login
+	Method string `yaml:"method"`
+	// user or ip. The calls without a user are limited by ip
+	Key string `yaml:"key"`
+	// Calls allowed per period on average
+	Rate   int           `yaml:"rate"`
+	Period time.Duration `yaml:"period"`
+	// Calls allowed at once, the rate by default
+	Burst int `yaml:"burst"`
+}
+
+func (r *RateLimitRule) validate() error {
+	if r.Method == "" {
+		return errors.New("invalid method")
+	}
+	if r.Key == "" {
+		r.Key = RateLimitByUser
+	}
+	if r.Key != RateLimitByIP && r.Key != RateLimitByUser {
+		return fmt.Errorf("invalid key: %s", r.Key)
+	}
+	if r.Rate <= 0 {
+		return errors.New("invalid rate")
+	}
+	if r.Period <= 0 {
+		r.Period = time.Minute
+	}
+	if r.Burst <= 0 {
+		r.Burst = r.Rate
+	}
+
+	return nil
+}
+
+// Token buckets in redis, so the limits hold across the replicas.
+// The calls are let through while redis is unavailable
+type rateLimit struct {
+	Enable bool            `yaml:"enable"`
+	Rules  []RateLimitRule `yaml:"rules"`
+}
+
+func (r *rateLimit) validate() error {
+	if !r.Enable {
+		return nil
+	}
+
+	methods := make(map[string]bool, len(r.Rules))
+	for i := range r.Rules {
+		if err := r.Rules[i].validate(); err != nil {
+			return fmt.Errorf("invalid rule #%d: %w", i, err)
+		}
+		if methods[r.Rules[i].Method] {
+			return fmt.Errorf("duplicate rule: %s", r.Rules[i].Method)
+		}
+		methods[r.Rules[i].Method] = true
+	}
+
+	return nil
+}
+
+type JWTKey struct {
+	// The kid header of the tokens signed with the key,
+	// the thumbprint of the public key (RFC 7638) by default
+	KID         string `yaml:"kid"`
+	PrivatePath string `yaml:"private-key-path"`
+	PublicPath  string `yaml:"public-key-path"`
+}
+
+func (k *JWTKey) validate() error {
+	if k.PublicPath == "" {
+		return errors.New("invalid path to public key")
+	}
+
+	return nil
+}
+
 type jwt struct {
-	TTL         time.Duration `yaml:"ttl"`
-	PrivatePath string        `yaml:"private-key-path"`
-	PublicPath  string        `yaml:"public-key-path"`
+	TTL time.Duration `yaml:"ttl"`
+	// The single key, it's used if the keys aren't set
+	PrivatePath string `yaml:"private-key-path"`
+	PublicPath  string `yaml:"public-key-path"`
+	// The tokens are signed with the active key, the rest only verify them.
+	// A new key is added first, so the services learn it, n' made active later;
+	// the old one is kept till its tokens expire
+	Keys []JWTKey `yaml:"keys"`
+	// The keys managed by the keys command: <kid>.key n' <kid>.pub,
+	// the active kid is in the active file. Used along with the keys above
+	KeysDir string `yaml:"keys-dir"`
+	// The kid of the active file or the first of the keys by default,
+	// it needs the private key
+	ActiveKID string `yaml:"active-kid"`
 }
 
 func (j *jwt) validate() error {
 	if j.TTL < time.Millisecond {
 		return errors.New("too little ttl")
 	}
-	if j.PrivatePath == "" {
-		return errors.New("invalid path to private key")
+
+	if len(j.Keys) == 0 && j.KeysDir == "" {
+		if j.PrivatePath == "" {
+			return errors.New("invalid path to private key")
+		}
+		if j.PublicPath == "" {
+			return errors.New("invalid path to public key")
+		}
+
+		j.Keys = []JWTKey{{
+			PrivatePath: j.PrivatePath,
+			PublicPath:  j.PublicPath,
+		}}
 	}
-	if j.PublicPath == "" {
-		return errors.New("invalid path to public key")
+
+	for i := range j.Keys {
+		if err := j.Keys[i].validate(); err != nil {
+			return fmt.Errorf("invalid key #%d: %w", i, err)
+		}
 	}
 
 	return nil
@@ -107,13 +285,165 @@ func (r *redis) validate() error {
 	return nil
 }
 
+// Revoked sessions in redis, their access tokens are refused before the expiry.
+// xcutr reads the same list
+type denylist struct {
+	Enable bool `yaml:"enable"`
+	// How long the answers of redis are kept in memory,
+	// a revocation reaches the other replicas with this delay
+	CacheTTL time.Duration `yaml:"cache-ttl"`
+}
+
+func (d *denylist) validate() error {
+	if !d.Enable {
+		return nil
+	}
+
+	if d.CacheTTL < 0 {
+		return errors.New("invalid cache ttl")
+	}
+	if d.CacheTTL == 0 {
+		d.CacheTTL = 5 * time.Second
+	}
+
+	return nil
+}
+
+const (
+	MailerOutbox = "outbox"
+	MailerSMTP   = "smtp"
+)
+
+// The emails of sso: sent by smtp or appended to the outbox for the development
+type mailer struct {
+	// Required: the emails carry the tokens of the accounts,
+	// so they aren't dropped into the outbox silently
+	Type string `yaml:"type"`
+	From string `yaml:"from"`
+	// The outbox writes the emails to the file as json lines
+	OutboxPath string        `yaml:"outbox-path"`
+	Timeout    time.Duration `yaml:"timeout"`
+	// Emails waiting for the workers, the new ones are dropped above it
+	QueueSize int `yaml:"queue-size"`
+	Workers   int `yaml:"workers"`
+}
+
+func (m *mailer) validate(env string, smtp *smtp) error {
+	switch m.Type {
+	case "":
+		return errors.New("type isn't set")
+	case MailerOutbox:
+		if env == "prod" {
+			return errors.New("outbox isn't allowed in prod")
+		}
+		if m.OutboxPath == "" {
+			return errors.New("outbox path isn't set")
+		}
+	case MailerSMTP:
+	default:
+		return fmt.Errorf("invalid type: %s", m.Type)
+	}
+	if m.From == "" {
+		m.From = "noreply@coderun.local"
+	}
+	if m.Timeout <= 0 {
+		m.Timeout = 10 * time.Second
+	}
+	if m.QueueSize <= 0 {
+		m.QueueSize = 100
+	}
+	if m.Workers <= 0 {
+		m.Workers = 4
+	}
+
+	if m.Type == MailerSMTP && smtp.Host == "" {
+		return errors.New("smtp host isn't set")
+	}
+
+	return nil
+}
+
+type smtp struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// The emails carry the tokens, so they aren't sent if the relay
+	// doesn't support STARTTLS. True by default
+	RequireTLS *bool `yaml:"require-tls"`
+	// The connection is tls from the start, set by default for 465
+	ImplicitTLS bool `yaml:"implicit-tls"`
+}
+
+func (s *smtp) validate() error {
+	if s.Port == "" {
+		s.Port = "587"
+	}
+	if s.RequireTLS == nil {
+		s.RequireTLS = new(bool)
+		*s.RequireTLS = true
+	}
+	if s.Port == "465" {
+		s.ImplicitTLS = true
+	}
+
+	return nil
+}
+
+type emailVerification struct {
+	// Login (n' Execute of xcutr) need the verified email,
+	// the users are still logged in by Register
+	Require bool `yaml:"require"`
+	// Lifetime of the token sent by email, 24h by default
+	TTL time.Duration `yaml:"ttl"`
+	// The link of the email, the token is added in its query.
+	// The email has the token only if it's empty
+	URL string `yaml:"url"`
+}
+
+func (e *emailVerification) validate() error {
+	if e.TTL == 0 {
+		e.TTL = 24 * time.Hour
+	}
+	if e.TTL < time.Minute {
+		return errors.New("too little ttl")
+	}
+	return nil
+}
+
+type passwordReset struct {
+	// Lifetime of the token sent by email, 15m by default
+	TTL time.Duration `yaml:"ttl"`
+	// The link of the email, the token is added in its query.
+	// The email has the token only if it's empty
+	URL string `yaml:"url"`
+}
+
+func (p *passwordReset) validate() error {
+	if p.TTL == 0 {
+		p.TTL = 15 * time.Minute
+	}
+	if p.TTL < time.Minute {
+		return errors.New("too little ttl")
+	}
+	return nil
+}
+
 type Config struct {
-	App     app    `yaml:"app"`
-	Server  server `yaml:"server"`
-	Secrets struct {
+	App       app       `yaml:"app"`
+	Server    server    `yaml:"server"`
+	Tracing   tracing   `yaml:"tracing"`
+	RateLimit rateLimit `yaml:"rate-limit"`
+	Denylist  denylist  `yaml:"denylist"`
+	Mailer    mailer    `yaml:"mailer"`
+	// The tokens sent to the emails of the users
+	EmailVerification emailVerification `yaml:"email-verification"`
+	PasswordReset     passwordReset     `yaml:"password-reset"`
+	Secrets           struct {
 		JWT   jwt   `yaml:"jwt"`
 		Mongo mongo `yaml:"mongo"`
 		Redis redis `yaml:"redis"`
+		SMTP  smtp  `yaml:"smtp"`
 	} `yaml:"secrets"`
 }
 
@@ -154,6 +484,27 @@ func (c *Config) Validate() error {
 	if err := c.Server.validate(); err != nil {
 		return fmt.Errorf("invalid server: %w", err)
 	}
+	if err := c.Tracing.validate(); err != nil {
+		return fmt.Errorf("invalid tracing: %w", err)
+	}
+	if err := c.RateLimit.validate(); err != nil {
+		return fmt.Errorf("invalid rate limit: %w", err)
+	}
+	if err := c.Denylist.validate(); err != nil {
+		return fmt.Errorf("invalid denylist: %w", err)
+	}
+	if err := c.Secrets.SMTP.validate(); err != nil {
+		return fmt.Errorf("invalid smtp: %w", err)
+	}
+	if err := c.Mailer.validate(c.App.Env, &c.Secrets.SMTP); err != nil {
+		return fmt.Errorf("invalid mailer: %w", err)
+	}
+	if err := c.EmailVerification.validate(); err != nil {
+		return fmt.Errorf("invalid email verification: %w", err)
+	}
+	if err := c.PasswordReset.validate(); err != nil {
+		return fmt.Errorf("invalid password reset: %w", err)
+	}
 	if err := c.Secrets.JWT.validate(); err != nil {
 		return fmt.Errorf("invalid jwt: %w", err)
 	}
@@ -61,6 +61,10 @@ func (api *ServerAPI) Login(ctx context.Context, req *ssopb.LoginRequest) (*ssop
 			return nil, status.Error(codes.InvalidArgument, err.Error())
 		}
 
+		if errors.Is(err, customerrors.ErrEmailNotVerified) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -74,6 +78,27 @@ func (api *ServerAPI) Refresh(ctx context.Context, req *ssopb.RefreshRequest) (*
 
 	resp, err := api.service.Refresh(ctx, req)
 	if err != nil {
+		if errors.Is(err, customerrors.ErrNoSessions) ||
+			errors.Is(err, customerrors.ErrRefreshReused) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return resp, nil
+}
+
+func (api *ServerAPI) Logout(ctx context.Context, req *ssopb.LogoutRequest) (*ssopb.Empty, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be empty")
+	}
+
+	if err := api.service.Logout(ctx, req); err != nil {
+		if errors.Is(err, customerrors.ErrInvalidRequest) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
 		if errors.Is(err, customerrors.ErrNoSessions) {
 			return nil, status.Error(codes.Unauthenticated, err.Error())
 		}
@@ -81,9 +106,120 @@ func (api *ServerAPI) Refresh(ctx context.Context, req *ssopb.RefreshRequest) (*
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	return &ssopb.Empty{}, nil
+}
+
+func (api *ServerAPI) SendVerification(ctx context.Context, req *ssopb.SendVerificationRequest) (*ssopb.Empty, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be empty")
+	}
+
+	if err := api.service.SendVerification(ctx, req); err != nil {
+		if errors.Is(err, customerrors.ErrInvalidRequest) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &ssopb.Empty{}, nil
+}
+
+func (api *ServerAPI) VerifyEmail(ctx context.Context, req *ssopb.VerifyEmailRequest) (*ssopb.Empty, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be empty")
+	}
+
+	if err := api.service.VerifyEmail(ctx, req); err != nil {
+		if errors.Is(err, customerrors.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &ssopb.Empty{}, nil
+}
+
+func (api *ServerAPI) RequestPasswordReset(ctx context.Context, req *ssopb.RequestPasswordResetRequest) (*ssopb.Empty, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be empty")
+	}
+
+	if err := api.service.RequestPasswordReset(ctx, req); err != nil {
+		if errors.Is(err, customerrors.ErrInvalidRequest) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &ssopb.Empty{}, nil
+}
+
+func (api *ServerAPI) ResetPassword(ctx context.Context, req *ssopb.ResetPasswordRequest) (*ssopb.Empty, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be empty")
+	}
+
+	if err := api.service.ResetPassword(ctx, req); err != nil {
+		if errors.Is(err, customerrors.ErrInvalidToken) ||
+			errors.Is(err, customerrors.ErrInvalidPassword) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &ssopb.Empty{}, nil
+}
+
+func (api *ServerAPI) LogoutAll(ctx context.Context, _ *ssopb.Empty) (*ssopb.Empty, error) {
+	if err := api.service.LogoutAll(ctx); err != nil {
+		if errors.Is(err, customerrors.ErrInvalidUserID) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &ssopb.Empty{}, nil
+}
+
+func (api *ServerAPI) ListSessions(ctx context.Context, _ *ssopb.Empty) (*ssopb.SessionList, error) {
+	resp, err := api.service.ListSessions(ctx)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidUserID) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
 	return resp, nil
 }
 
+func (api *ServerAPI) RevokeSession(ctx context.Context, req *ssopb.RevokeSessionRequest) (*ssopb.Empty, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be empty")
+	}
+
+	if err := api.service.RevokeSession(ctx, req); err != nil {
+		if errors.Is(err, customerrors.ErrInvalidRequest) ||
+			errors.Is(err, customerrors.ErrInvalidUserID) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, customerrors.ErrSessionNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &ssopb.Empty{}, nil
+}
+
 func (api *ServerAPI) Register(ctx context.Context, req *ssopb.RegisterRequest) (*ssopb.Token, error) {
 	if req == nil {
 		return nil, status.Error(codes.InvalidArgument, "request cannot be empty")
@@ -142,3 +278,12 @@ func (api *ServerAPI) GetSelf(ctx context.Context, _ *ssopb.Empty) (*ssopb.User,
 
 	return resp, nil
 }
+
+func (api *ServerAPI) GetJWKS(ctx context.Context, _ *ssopb.Empty) (*ssopb.JWKS, error) {
+	resp, err := api.service.GetJWKS(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return resp, nil
+}
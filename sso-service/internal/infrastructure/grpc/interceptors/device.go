@@ -0,0 +1,77 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/devathh/coderun/sso-service/internal/domain/auth"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// The user agent n' the label of the device are forwarded by the gateway,
+// grpc sets its own user-agent
+const (
+	userAgentKey   = "x-client-user-agent"
+	deviceLabelKey = "x-device-label"
+)
+
+// DeviceInterceptor puts the device of the caller into the context,
+// the sessions are opened n' refreshed with it
+func DeviceInterceptor(cfg *config.Config) grpc.UnaryServerInterceptor {
+	trustForwarded := cfg.Server.TrustForwarded
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		userAgent := first(md, userAgentKey)
+		if userAgent == "" {
+			userAgent = first(md, "user-agent")
+		}
+
+		ctx = context.WithValue(ctx, auth.CtxKey("device"), auth.Device{
+			IP:        clientIP(ctx, trustForwarded),
+			UserAgent: userAgent,
+			Label:     first(md, deviceLabelKey),
+		})
+
+		return handler(ctx, req)
+	}
+}
+
+// clientIP is the ip of the caller or the one it forwards
+func clientIP(ctx context.Context, trustForwarded bool) string {
+	if trustForwarded {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if forwarded := md.Get("x-forwarded-for"); len(forwarded) > 0 {
+			ip, _, _ := strings.Cut(forwarded[0], ",")
+			if ip = strings.TrimSpace(ip); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+func first(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(values[0])
+}
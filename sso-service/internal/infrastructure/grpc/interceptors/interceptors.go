@@ -12,15 +12,18 @@ import (
 )
 
 type PackInterceptors struct {
-	log         *slog.Logger
-	jwtManager  auth.JWTManager
+	log        *slog.Logger
+	jwtManager auth.JWTManager
+	// Nil if the denylist is disabled
+	denylist    auth.Denylist
 	authRequire map[string]bool
 }
 
-func New(log *slog.Logger, jwtManager auth.JWTManager, authRequire map[string]bool) *PackInterceptors {
+func New(log *slog.Logger, jwtManager auth.JWTManager, denylist auth.Denylist, authRequire map[string]bool) *PackInterceptors {
 	return &PackInterceptors{
 		log:         log,
 		jwtManager:  jwtManager,
+		denylist:    denylist,
 		authRequire: authRequire,
 	}
 }
@@ -46,6 +49,18 @@ func (p *PackInterceptors) AuthInterceptor() grpc.UnaryServerInterceptor {
 			return nil, status.Error(codes.Unauthenticated, "invalid token")
 		}
 
+		if p.denylist != nil {
+			revoked, err := p.denylist.IsRevoked(ctx, claims)
+			if err != nil {
+				// A revoked token mustn't pass while redis is unavailable
+				p.log.Error("failed to check revocation of token", slog.String("error", err.Error()))
+				return nil, status.Error(codes.Unavailable, "failed to check token")
+			}
+			if revoked {
+				return nil, status.Error(codes.Unauthenticated, "token is revoked")
+			}
+		}
+
 		ctx = context.WithValue(ctx, auth.CtxKey("user_id"), claims.UserID)
 		ctx = context.WithValue(ctx, auth.CtxKey("email"), claims.Email)
 
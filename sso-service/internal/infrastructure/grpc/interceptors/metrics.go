@@ -0,0 +1,24 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsInterceptor counts the calls by their codes n' measures them.
+// It goes first, so the rejected by auth ones are counted too
+func MetricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		metrics.GRPCHandled.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		metrics.GRPCHandling.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+
+		return resp, err
+	}
+}
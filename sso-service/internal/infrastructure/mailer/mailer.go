@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/devathh/coderun/sso-service/internal/domain/mail"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
+)
+
+// New is the mailer of the config
+func New(cfg *config.Config, log *slog.Logger) (mail.Mailer, error) {
+	if cfg == nil || log == nil {
+		return nil, customerrors.ErrNilArgs
+	}
+
+	switch cfg.Mailer.Type {
+	case config.MailerSMTP:
+		return NewSMTP(cfg)
+	case config.MailerOutbox:
+		return NewOutbox(cfg, log)
+	}
+
+	return nil, fmt.Errorf("unknown mailer: %s", cfg.Mailer.Type)
+}
+
+// validate keeps the headers from being injected
+func validate(msg mail.Message) error {
+	if msg.To == "" || strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return customerrors.ErrInvalidEmail
+	}
+
+	return nil
+}
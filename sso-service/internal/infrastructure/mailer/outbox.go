@@ -0,0 +1,76 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/devathh/coderun/sso-service/internal/domain/mail"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+)
+
+// Outbox doesn't send the emails: they are appended to a file as json lines,
+// for the development n' the tests
+type Outbox struct {
+	log  *slog.Logger
+	path string
+	mu   sync.Mutex
+}
+
+type outboxMessage struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+func NewOutbox(cfg *config.Config, log *slog.Logger) (*Outbox, error) {
+	return &Outbox{
+		log:  log,
+		path: cfg.Mailer.OutboxPath,
+	}, nil
+}
+
+func (o *Outbox) Send(ctx context.Context, msg mail.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := validate(msg); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(outboxMessage{
+		To:      msg.To,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+		SentAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal email: %w", err)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	file, err := os.OpenFile(o.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open outbox: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write email to outbox: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write email to outbox: %w", err)
+	}
+
+	// The body has the tokens, it's only in the outbox
+	o.log.Info("email is written to outbox", slog.String("to", msg.To), slog.String("subject", msg.Subject))
+	return nil
+}
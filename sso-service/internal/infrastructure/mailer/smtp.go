@@ -0,0 +1,129 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"time"
+
+	"github.com/devathh/coderun/sso-service/internal/domain/mail"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+)
+
+// ErrNoStartTLS is returned when the relay can't upgrade the connection n' tls is required
+var ErrNoStartTLS = errors.New("smtp relay doesn't support STARTTLS")
+
+// SMTP sends the emails through the relay of the config. The connection
+// is tls from the start or upgraded with STARTTLS; without it the emails
+// aren't sent, unless tls isn't required
+type SMTP struct {
+	cfg  *config.Config
+	addr string
+	tls  *tls.Config
+}
+
+func NewSMTP(cfg *config.Config) (*SMTP, error) {
+	return &SMTP{
+		cfg:  cfg,
+		addr: net.JoinHostPort(cfg.Secrets.SMTP.Host, cfg.Secrets.SMTP.Port),
+		tls:  &tls.Config{ServerName: cfg.Secrets.SMTP.Host},
+	}, nil
+}
+
+func (s *SMTP) Send(ctx context.Context, msg mail.Message) error {
+	if err := validate(msg); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Mailer.Timeout)
+	defer cancel()
+
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect smtp: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return fmt.Errorf("failed to set deadline: %w", err)
+		}
+	}
+
+	host := s.cfg.Secrets.SMTP.Host
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return fmt.Errorf("failed to create smtp client: %w", err)
+	}
+	defer client.Close()
+
+	if !s.cfg.Secrets.SMTP.ImplicitTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(s.tls); err != nil {
+				return fmt.Errorf("failed to start tls: %w", err)
+			}
+		} else if s.requireTLS() {
+			return ErrNoStartTLS
+		}
+	}
+
+	if s.cfg.Secrets.SMTP.Username != "" {
+		auth := smtp.PlainAuth("", s.cfg.Secrets.SMTP.Username, s.cfg.Secrets.SMTP.Password, host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.cfg.Mailer.From); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("failed to set recipient: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start data: %w", err)
+	}
+	if _, err := w.Write(s.format(msg)); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (s *SMTP) dial(ctx context.Context) (net.Conn, error) {
+	if s.cfg.Secrets.SMTP.ImplicitTLS {
+		dialer := tls.Dialer{Config: s.tls}
+		return dialer.DialContext(ctx, "tcp", s.addr)
+	}
+
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", s.addr)
+}
+
+func (s *SMTP) requireTLS() bool {
+	return s.cfg.Secrets.SMTP.RequireTLS == nil || *s.cfg.Secrets.SMTP.RequireTLS
+}
+
+func (s *SMTP) format(msg mail.Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.cfg.Mailer.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(msg.Body)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,191 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devathh/coderun/sso-service/internal/domain/mail"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+)
+
+// fakeRelay is an smtp relay that accepts every email
+type fakeRelay struct {
+	listener net.Listener
+	// Nil if the relay doesn't support STARTTLS
+	startTLS *tls.Config
+
+	mu  sync.Mutex
+	tls []bool
+	// The data of the emails
+	received []string
+}
+
+// newTLSConfigs returns the config of the relay n' the one trusting it
+func newTLSConfigs(t *testing.T) (*tls.Config, *tls.Config) {
+	t.Helper()
+
+	// The test server has the certificate of 127.0.0.1
+	srv := httptest.NewTLSServer(nil)
+	t.Cleanup(srv.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	return &tls.Config{Certificates: srv.TLS.Certificates}, &tls.Config{ServerName: "127.0.0.1", RootCAs: roots}
+}
+
+func newFakeRelay(t *testing.T, implicit, startTLS *tls.Config) *fakeRelay {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	if implicit != nil {
+		listener = tls.NewListener(listener, implicit)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	relay := &fakeRelay{listener: listener, startTLS: startTLS}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go relay.serve(conn, implicit != nil)
+		}
+	}()
+
+	return relay
+}
+
+func (fr *fakeRelay) serve(conn net.Conn, secure bool) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		switch verb, _, _ := strings.Cut(line, " "); strings.ToUpper(verb) {
+		case "EHLO":
+			if fr.startTLS != nil && !secure {
+				text.PrintfLine("250-fake")
+				text.PrintfLine("250 STARTTLS")
+			} else {
+				text.PrintfLine("250 fake")
+			}
+		case "STARTTLS":
+			text.PrintfLine("220 ready")
+			tlsConn := tls.Server(conn, fr.startTLS)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, secure = tlsConn, true
+			text = textproto.NewConn(conn)
+		case "DATA":
+			text.PrintfLine("354 go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			fr.mu.Lock()
+			fr.received = append(fr.received, string(data))
+			fr.tls = append(fr.tls, secure)
+			fr.mu.Unlock()
+			text.PrintfLine("250 ok")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("250 ok")
+		}
+	}
+}
+
+func (fr *fakeRelay) emails() ([]string, []bool) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	return fr.received, fr.tls
+}
+
+func TestSMTPSend(t *testing.T) {
+	relayTLS, clientTLS := newTLSConfigs(t)
+
+	testCases := []struct {
+		Name string
+		// The relay is tls from the start or supports STARTTLS
+		Implicit   bool
+		StartTLS   bool
+		RequireTLS bool
+		WantErr    error
+		WantSent   bool
+	}{
+		{Name: "starttls", StartTLS: true, RequireTLS: true, WantSent: true},
+		{Name: "implicit", Implicit: true, RequireTLS: true, WantSent: true},
+		// A stripped relay doesn't get the tokens in cleartext
+		{Name: "plain_required", RequireTLS: true, WantErr: ErrNoStartTLS},
+		{Name: "plain_allowed", RequireTLS: false, WantSent: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			var implicit, startTLS *tls.Config
+			if tc.Implicit {
+				implicit = relayTLS
+			}
+			if tc.StartTLS {
+				startTLS = relayTLS
+			}
+			relay := newFakeRelay(t, implicit, startTLS)
+
+			cfg := &config.Config{}
+			cfg.Mailer.From = "noreply@coderun.dev"
+			cfg.Mailer.Timeout = 10 * time.Second
+			cfg.Secrets.SMTP.Host, cfg.Secrets.SMTP.Port, _ = net.SplitHostPort(relay.listener.Addr().String())
+			cfg.Secrets.SMTP.RequireTLS = &tc.RequireTLS
+			cfg.Secrets.SMTP.ImplicitTLS = tc.Implicit
+
+			sender, err := NewSMTP(cfg)
+			if err != nil {
+				t.Fatalf("failed to create mailer: %v", err)
+			}
+			sender.tls = clientTLS
+
+			err = sender.Send(t.Context(), mail.Message{To: "user@coderun.dev", Subject: "Reset", Body: "token"})
+			if !errors.Is(err, tc.WantErr) {
+				t.Fatalf("got %v, want %v", err, tc.WantErr)
+			}
+
+			received, secure := relay.emails()
+			if got := len(received) == 1; got != tc.WantSent {
+				t.Fatalf("got sent %v, want %v", got, tc.WantSent)
+			}
+			if !tc.WantSent {
+				return
+			}
+			if !strings.HasSuffix(received[0], "token\n") {
+				t.Errorf("got email %q, want the body", received[0])
+			}
+			if wantTLS := tc.Implicit || tc.StartTLS; secure[0] != wantTLS {
+				t.Errorf("got tls %v, want %v", secure[0], wantTLS)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Registry holds all the metrics of the service,
+// the default one of prometheus isn't used
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Outcomes of logins n' registrations
+const (
+	OutcomeSuccess            = "success"
+	OutcomeInvalidCredentials = "invalid_credentials"
+	OutcomeAlreadyRegistered  = "already_registered"
+	OutcomeEmailNotVerified   = "email_not_verified"
+	OutcomeInvalidRequest     = "invalid_request"
+	OutcomeError              = "error"
+)
+
+var (
+	// grpc server's
+	GRPCHandled = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Handled gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+	GRPCHandling = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Duration of gRPC calls by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// sso's
+	Logins = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "sso_logins_total",
+		Help: "Logins by outcome.",
+	}, []string{"outcome"})
+	Registrations = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "sso_registrations_total",
+		Help: "Registrations by outcome.",
+	}, []string{"outcome"})
+	RefreshReuses = factory.NewCounter(prometheus.CounterOpts{
+		Name: "sso_refresh_reuses_total",
+		Help: "Reuses of rotated refresh tokens, their sessions are revoked.",
+	})
+	EmailsSent = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "sso_emails_sent_total",
+		Help: "Emails by kind and result.",
+	}, []string{"kind", "result"})
+	EmailsDropped = factory.NewCounter(prometheus.CounterOpts{
+		Name: "sso_emails_dropped_total",
+		Help: "Emails dropped because the queue was full or sso was shutting down.",
+	})
+
+	// storages'
+	RedisCall = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sso_redis_call_seconds",
+		Help:    "Duration of redis commands by command and result.",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"command", "result"})
+	MongoCall = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sso_mongo_call_seconds",
+		Help:    "Duration of mongo commands by command and result.",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"command", "result"})
+)
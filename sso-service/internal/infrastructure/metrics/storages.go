@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// RedisHook measures every command of the client, pipelines as one call
+type RedisHook struct{}
+
+func (RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		RedisCall.WithLabelValues(cmd.Name(), redisResult(err)).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+func (RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		RedisCall.WithLabelValues("pipeline", redisResult(err)).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// A missing key is an answer, not a failure
+func redisResult(err error) string {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return "ok"
+	}
+
+	return "error"
+}
+
+// MongoMonitor measures the commands sent to mongo
+func MongoMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			observeMongo(e.CommandFinishedEvent, "ok")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			observeMongo(e.CommandFinishedEvent, "error")
+		},
+	}
+}
+
+func observeMongo(e event.CommandFinishedEvent, result string) {
+	MongoCall.WithLabelValues(e.CommandName, result).Observe(e.Duration.Seconds())
+}
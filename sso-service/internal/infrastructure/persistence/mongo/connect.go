@@ -6,6 +6,7 @@ import (
 	"net"
 
 	"github.com/devathh/coderun/sso-service/internal/infrastructure/config"
+	"github.com/devathh/coderun/sso-service/internal/infrastructure/metrics"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -18,7 +19,11 @@ func Connect(cfg *config.Config) (*mongo.Client, error) {
 		cfg.Secrets.Mongo.Port,
 	))
 
-	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	opts := options.Client().
+		ApplyURI(uri).
+		SetMonitor(metrics.MongoMonitor())
+
+	client, err := mongo.Connect(opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open connection with mongo: %w", err)
 	}
@@ -30,6 +35,12 @@ func Connect(cfg *config.Config) (*mongo.Client, error) {
 	return client, nil
 }
 
+func Ping(client *mongo.Client) func(context.Context) error {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	}
+}
+
 func Close(client *mongo.Client) error {
 	return client.Disconnect(context.Background())
 }
@@ -53,3 +64,20 @@ func CreateIndexes(client *mongo.Client) error {
 
 	return nil
 }
+
+// BackfillEmailVerified marks the users registered before the email verification
+// as verified, so they aren't locked out when it's required. The new users always
+// have the field, so it's a no-op after the first run
+func BackfillEmailVerified(client *mongo.Client) (int64, error) {
+	coll := client.Database("coderun").Collection("users")
+
+	filter := bson.M{"email_verified": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"email_verified": true}}
+
+	res, err := coll.UpdateMany(context.Background(), filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to backfill email verification: %w", err)
+	}
+
+	return res.ModifiedCount, nil
+}
@@ -7,10 +7,11 @@ import (
 
 func toModel(user *user.User) UserModel {
 	return UserModel{
-		ID:           user.ID().String(),
-		Username:     user.Username(),
-		Email:        string(user.Email()),
-		PasswordHash: string(user.Password()),
+		ID:            user.ID().String(),
+		Username:      user.Username(),
+		Email:         string(user.Email()),
+		PasswordHash:  string(user.Password()),
+		EmailVerified: user.EmailVerified(),
 	}
 }
 
@@ -27,7 +28,7 @@ func toDomain(model *UserModel) (*user.User, error) {
 		return nil, err
 	}
 
-	user := user.From(id, model.Username, email, passwordHash)
+	user := user.From(id, model.Username, email, passwordHash, model.EmailVerified)
 
 	return user, nil
 }
@@ -5,4 +5,6 @@ type UserModel struct {
 	Username     string `bson:"username"`
 	Email        string `bson:"email"`
 	PasswordHash string `bson:"password_hash"`
+	// False in the users registered before the verification
+	EmailVerified bool `bson:"email_verified"`
 }
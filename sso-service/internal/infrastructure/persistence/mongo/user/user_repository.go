@@ -7,12 +7,16 @@ import (
 	"log/slog"
 	"strings"
 
+	"github.com/devathh/coderun/shared/tracing"
 	"github.com/devathh/coderun/sso-service/internal/domain/user"
 	customerrors "github.com/devathh/coderun/sso-service/pkg/errors"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("github.com/devathh/coderun/sso-service/internal/infrastructure/persistence/mongo/user")
+
 type MongoRepository struct {
 	log        *slog.Logger
 	client     *mongo.Client
@@ -31,7 +35,10 @@ func New(log *slog.Logger, client *mongo.Client) (*MongoRepository, error) {
 	}, nil
 }
 
-func (mr *MongoRepository) Save(ctx context.Context, user *user.User) (*user.User, error) {
+func (mr *MongoRepository) Save(ctx context.Context, user *user.User) (_ *user.User, err error) {
+	ctx, span := tracer.Start(ctx, "usermongo.Save")
+	defer func() { tracing.End(span, err) }()
+
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -51,7 +58,10 @@ func (mr *MongoRepository) Save(ctx context.Context, user *user.User) (*user.Use
 	return user, nil
 }
 
-func (mr *MongoRepository) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+func (mr *MongoRepository) GetByEmail(ctx context.Context, email string) (_ *user.User, err error) {
+	ctx, span := tracer.Start(ctx, "usermongo.GetByEmail")
+	defer func() { tracing.End(span, err) }()
+
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -78,7 +88,10 @@ func (mr *MongoRepository) GetByEmail(ctx context.Context, email string) (*user.
 	return user, nil
 }
 
-func (mr *MongoRepository) GetByID(ctx context.Context, id string) (*user.User, error) {
+func (mr *MongoRepository) GetByID(ctx context.Context, id string) (_ *user.User, err error) {
+	ctx, span := tracer.Start(ctx, "usermongo.GetByID")
+	defer func() { tracing.End(span, err) }()
+
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -100,7 +113,10 @@ func (mr *MongoRepository) GetByID(ctx context.Context, id string) (*user.User,
 	return user, nil
 }
 
-func (mr *MongoRepository) Update(ctx context.Context, updUser *user.User) error {
+func (mr *MongoRepository) Update(ctx context.Context, updUser *user.User) (err error) {
+	ctx, span := tracer.Start(ctx, "usermongo.Update")
+	defer func() { tracing.End(span, err) }()
+
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -119,3 +135,49 @@ func (mr *MongoRepository) Update(ctx context.Context, updUser *user.User) error
 
 	return nil
 }
+
+func (mr *MongoRepository) SetEmailVerified(ctx context.Context, id string) (err error) {
+	ctx, span := tracer.Start(ctx, "usermongo.SetEmailVerified")
+	defer func() { tracing.End(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	filter := bson.M{"id": id}
+	update := bson.M{"$set": bson.M{"email_verified": true}}
+
+	res, err := mr.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to verify email of user: %w", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return customerrors.ErrUserDoesntExist
+	}
+
+	return nil
+}
+
+func (mr *MongoRepository) SetPassword(ctx context.Context, id string, password user.Password) (err error) {
+	ctx, span := tracer.Start(ctx, "usermongo.SetPassword")
+	defer func() { tracing.End(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	filter := bson.M{"id": id}
+	update := bson.M{"$set": bson.M{"password_hash": string(password)}}
+
+	res, err := mr.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to set password of user: %w", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return customerrors.ErrUserDoesntExist
+	}
+
+	return nil
+}
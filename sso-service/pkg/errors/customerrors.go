@@ -14,6 +14,8 @@ var (
 	ErrUserDoesntExist       = errors.New("user doesn't exist")
 	ErrInvalidToken          = errors.New("invalid token")
 	ErrNoSessions            = errors.New("there aren't any sessions")
+	ErrSessionNotFound       = errors.New("session not found")
+	ErrRefreshReused         = errors.New("refresh token is already used, the session is revoked")
 
 	// Service's
 	ErrNilRequest         = errors.New("request cannot be nil")
@@ -21,4 +23,5 @@ var (
 	ErrInvalidRequest     = errors.New("invalid request")
 	ErrInvalidUserID      = errors.New("invalid user's id")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrEmailNotVerified   = errors.New("email isn't verified")
 )
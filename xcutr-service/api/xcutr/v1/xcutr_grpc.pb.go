@@ -19,7 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Xcutr_Execute_FullMethodName = "/xcutr.v1.Xcutr/Execute"
+	Xcutr_Execute_FullMethodName         = "/xcutr.v1.Xcutr/Execute"
+	Xcutr_CancelExecution_FullMethodName = "/xcutr.v1.Xcutr/CancelExecution"
+	Xcutr_AttachExecution_FullMethodName = "/xcutr.v1.Xcutr/AttachExecution"
+	Xcutr_ListExecutions_FullMethodName  = "/xcutr.v1.Xcutr/ListExecutions"
+	Xcutr_GetExecution_FullMethodName    = "/xcutr.v1.Xcutr/GetExecution"
+	Xcutr_GetUsageStats_FullMethodName   = "/xcutr.v1.Xcutr/GetUsageStats"
+	Xcutr_EraseUserData_FullMethodName   = "/xcutr.v1.Xcutr/EraseUserData"
 )
 
 // XcutrClient is the client API for Xcutr service.
@@ -29,6 +35,26 @@ type XcutrClient interface {
 	// Execute the code
 	// REQUIRES: jwt-token
 	Execute(ctx context.Context, in *ExecutionRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Log], error)
+	// Stop the running execution of the user
+	// REQUIRES: jwt-token
+	CancelExecution(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Re-join a running or recently finished execution
+	// from the given seq of its output.
+	// Users other than the owner must present the share token
+	// REQUIRES: jwt-token
+	AttachExecution(ctx context.Context, in *AttachRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Log], error)
+	// Finished executions of the user from the newest one
+	// REQUIRES: jwt-token
+	ListExecutions(ctx context.Context, in *ListExecutionsRequest, opts ...grpc.CallOption) (*ListExecutionsResponse, error)
+	// Finished execution of the user with its source n' output
+	// REQUIRES: jwt-token
+	GetExecution(ctx context.Context, in *GetExecutionRequest, opts ...grpc.CallOption) (*ExecutionRecord, error)
+	// Usage of the executor over the period
+	// REQUIRES: jwt-token of an admin
+	GetUsageStats(ctx context.Context, in *UsageStatsRequest, opts ...grpc.CallOption) (*UsageStats, error)
+	// Remove the analytics n' the history of executions of the user
+	// REQUIRES: jwt-token of an admin
+	EraseUserData(ctx context.Context, in *EraseUserDataRequest, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type xcutrClient struct {
@@ -58,6 +84,75 @@ func (c *xcutrClient) Execute(ctx context.Context, in *ExecutionRequest, opts ..
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Xcutr_ExecuteClient = grpc.ServerStreamingClient[Log]
 
+func (c *xcutrClient) CancelExecution(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Xcutr_CancelExecution_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *xcutrClient) AttachExecution(ctx context.Context, in *AttachRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Log], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Xcutr_ServiceDesc.Streams[1], Xcutr_AttachExecution_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AttachRequest, Log]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Xcutr_AttachExecutionClient = grpc.ServerStreamingClient[Log]
+
+func (c *xcutrClient) ListExecutions(ctx context.Context, in *ListExecutionsRequest, opts ...grpc.CallOption) (*ListExecutionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListExecutionsResponse)
+	err := c.cc.Invoke(ctx, Xcutr_ListExecutions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *xcutrClient) GetExecution(ctx context.Context, in *GetExecutionRequest, opts ...grpc.CallOption) (*ExecutionRecord, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecutionRecord)
+	err := c.cc.Invoke(ctx, Xcutr_GetExecution_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *xcutrClient) GetUsageStats(ctx context.Context, in *UsageStatsRequest, opts ...grpc.CallOption) (*UsageStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UsageStats)
+	err := c.cc.Invoke(ctx, Xcutr_GetUsageStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *xcutrClient) EraseUserData(ctx context.Context, in *EraseUserDataRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Xcutr_EraseUserData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // XcutrServer is the server API for Xcutr service.
 // All implementations must embed UnimplementedXcutrServer
 // for forward compatibility.
@@ -65,6 +160,26 @@ type XcutrServer interface {
 	// Execute the code
 	// REQUIRES: jwt-token
 	Execute(*ExecutionRequest, grpc.ServerStreamingServer[Log]) error
+	// Stop the running execution of the user
+	// REQUIRES: jwt-token
+	CancelExecution(context.Context, *CancelRequest) (*Empty, error)
+	// Re-join a running or recently finished execution
+	// from the given seq of its output.
+	// Users other than the owner must present the share token
+	// REQUIRES: jwt-token
+	AttachExecution(*AttachRequest, grpc.ServerStreamingServer[Log]) error
+	// Finished executions of the user from the newest one
+	// REQUIRES: jwt-token
+	ListExecutions(context.Context, *ListExecutionsRequest) (*ListExecutionsResponse, error)
+	// Finished execution of the user with its source n' output
+	// REQUIRES: jwt-token
+	GetExecution(context.Context, *GetExecutionRequest) (*ExecutionRecord, error)
+	// Usage of the executor over the period
+	// REQUIRES: jwt-token of an admin
+	GetUsageStats(context.Context, *UsageStatsRequest) (*UsageStats, error)
+	// Remove the analytics n' the history of executions of the user
+	// REQUIRES: jwt-token of an admin
+	EraseUserData(context.Context, *EraseUserDataRequest) (*Empty, error)
 	mustEmbedUnimplementedXcutrServer()
 }
 
@@ -78,6 +193,24 @@ type UnimplementedXcutrServer struct{}
 func (UnimplementedXcutrServer) Execute(*ExecutionRequest, grpc.ServerStreamingServer[Log]) error {
 	return status.Error(codes.Unimplemented, "method Execute not implemented")
 }
+func (UnimplementedXcutrServer) CancelExecution(context.Context, *CancelRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelExecution not implemented")
+}
+func (UnimplementedXcutrServer) AttachExecution(*AttachRequest, grpc.ServerStreamingServer[Log]) error {
+	return status.Error(codes.Unimplemented, "method AttachExecution not implemented")
+}
+func (UnimplementedXcutrServer) ListExecutions(context.Context, *ListExecutionsRequest) (*ListExecutionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListExecutions not implemented")
+}
+func (UnimplementedXcutrServer) GetExecution(context.Context, *GetExecutionRequest) (*ExecutionRecord, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetExecution not implemented")
+}
+func (UnimplementedXcutrServer) GetUsageStats(context.Context, *UsageStatsRequest) (*UsageStats, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetUsageStats not implemented")
+}
+func (UnimplementedXcutrServer) EraseUserData(context.Context, *EraseUserDataRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method EraseUserData not implemented")
+}
 func (UnimplementedXcutrServer) mustEmbedUnimplementedXcutrServer() {}
 func (UnimplementedXcutrServer) testEmbeddedByValue()               {}
 
@@ -110,19 +243,146 @@ func _Xcutr_Execute_Handler(srv interface{}, stream grpc.ServerStream) error {
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Xcutr_ExecuteServer = grpc.ServerStreamingServer[Log]
 
+func _Xcutr_CancelExecution_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(XcutrServer).CancelExecution(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Xcutr_CancelExecution_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(XcutrServer).CancelExecution(ctx, req.(*CancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Xcutr_AttachExecution_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AttachRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(XcutrServer).AttachExecution(m, &grpc.GenericServerStream[AttachRequest, Log]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Xcutr_AttachExecutionServer = grpc.ServerStreamingServer[Log]
+
+func _Xcutr_ListExecutions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListExecutionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(XcutrServer).ListExecutions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Xcutr_ListExecutions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(XcutrServer).ListExecutions(ctx, req.(*ListExecutionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Xcutr_GetExecution_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetExecutionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(XcutrServer).GetExecution(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Xcutr_GetExecution_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(XcutrServer).GetExecution(ctx, req.(*GetExecutionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Xcutr_GetUsageStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UsageStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(XcutrServer).GetUsageStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Xcutr_GetUsageStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(XcutrServer).GetUsageStats(ctx, req.(*UsageStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Xcutr_EraseUserData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EraseUserDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(XcutrServer).EraseUserData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Xcutr_EraseUserData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(XcutrServer).EraseUserData(ctx, req.(*EraseUserDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Xcutr_ServiceDesc is the grpc.ServiceDesc for Xcutr service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var Xcutr_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "xcutr.v1.Xcutr",
 	HandlerType: (*XcutrServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CancelExecution",
+			Handler:    _Xcutr_CancelExecution_Handler,
+		},
+		{
+			MethodName: "ListExecutions",
+			Handler:    _Xcutr_ListExecutions_Handler,
+		},
+		{
+			MethodName: "GetExecution",
+			Handler:    _Xcutr_GetExecution_Handler,
+		},
+		{
+			MethodName: "GetUsageStats",
+			Handler:    _Xcutr_GetUsageStats_Handler,
+		},
+		{
+			MethodName: "EraseUserData",
+			Handler:    _Xcutr_EraseUserData_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "Execute",
 			Handler:       _Xcutr_Execute_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "AttachExecution",
+			Handler:       _Xcutr_AttachExecution_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "xcutr/v1/xcutr.proto",
 }
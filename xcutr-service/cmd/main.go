@@ -10,6 +10,15 @@ import (
 )
 
 func main() {
+	// xcutr migrate status | up | down [steps]
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := app.Migrate(os.Args[2:], os.Stdout); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	app, err := app.New()
 	if err != nil {
 		slog.Error(err.Error())
@@ -23,7 +32,7 @@ func main() {
 	}()
 
 	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGINT)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 	<-stop
 
 	app.Shutdown()
@@ -1,29 +1,56 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
+	"time"
 
+	"github.com/devathh/coderun/shared/health"
+	sharedmetrics "github.com/devathh/coderun/shared/metrics"
+	"github.com/devathh/coderun/shared/ratelimit"
+	"github.com/devathh/coderun/shared/tracing"
 	xcutrpb "github.com/devathh/coderun/xcutr-service/api/xcutr/v1"
 	services "github.com/devathh/coderun/xcutr-service/internal/application/service"
+	"github.com/devathh/coderun/xcutr-service/internal/domain/auth"
+	xcutrexecution "github.com/devathh/coderun/xcutr-service/internal/domain/execution"
 	"github.com/devathh/coderun/xcutr-service/internal/domain/observability"
 	jwt "github.com/devathh/coderun/xcutr-service/internal/infrastructure/auth"
+	rediscache "github.com/devathh/coderun/xcutr-service/internal/infrastructure/cache/redis"
+	denylistredis "github.com/devathh/coderun/xcutr-service/internal/infrastructure/cache/redis/denylist"
 	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
 	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/docker"
 	containerdocker "github.com/devathh/coderun/xcutr-service/internal/infrastructure/docker/container"
 	grpcserver "github.com/devathh/coderun/xcutr-service/internal/infrastructure/grpc"
 	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/grpc/handlers"
 	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/grpc/interceptors"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/metrics"
 	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/observability/clickhouse"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/observability/sinks"
+	executionch "github.com/devathh/coderun/xcutr-service/internal/infrastructure/persistence/clickhouse/execution"
 	"github.com/devathh/coderun/xcutr-service/pkg/log"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 )
 
 type App struct {
-	log    *slog.Logger
-	server *grpcserver.Server
+	cfg     *config.Config
+	log     *slog.Logger
+	server  *grpcserver.Server
+	service services.XcutrService
+	docker  *docker.Pool
+	events  observability.ExecutionEventSink
+	health  *health.Checker
+	// Nil if the rate limits n' the denylist are disabled
+	redis *redis.Client
+	// Nil if the metrics are disabled
+	metrics *sharedmetrics.Server
+	// Flushes the spans left
+	tracing func(context.Context) error
 }
 
 func New() (*App, error) {
@@ -44,29 +71,73 @@ func New() (*App, error) {
 
 	log.Info("config is loaded", slog.Any("app", cfg.App), slog.Any("server", cfg.Server))
 
-	dockerClient, err := docker.Connect()
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Enable:      cfg.Tracing.Enable,
+		Stdout:      cfg.Tracing.Exporter == config.ExporterStdout,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		Name:        cfg.App.Name,
+		Version:     cfg.App.Version,
+		Env:         cfg.App.Env,
+		Instance:    cfg.App.Instance,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup tracing: %w", err)
+	}
+
+	dockerPool, err := docker.NewPool(cfg, log)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open connection with docker client: %w", err)
+		return nil, fmt.Errorf("failed to open connection with docker hosts: %w", err)
 	}
 
-	contRepo, err := containerdocker.New(cfg, dockerClient)
+	contRepo, err := containerdocker.New(cfg, dockerPool)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create container repository: %w", err)
 	}
 
+	checks := map[string]health.Check{
+		"docker": dockerPool.Check,
+	}
+
+	// The history of executions lives in clickhouse too,
+	// so it's disabled along with it
 	var chClient observability.ClickhouseClient
+	var history xcutrexecution.HistoryRepository
 	if cfg.Features.ClickhouseEnable {
-		chClient, err = clickhouse.New(cfg)
+		client, err := clickhouse.New(cfg)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create clickhouse client: %w", err)
 		}
 
-		if err := chClient.Up(); err != nil {
+		if err := client.Up(); err != nil {
 			return nil, fmt.Errorf("failed to create clickhouse client: %w", err)
 		}
+		chClient = client
+		checks["clickhouse"] = client.Ping
+
+		ctxRetention, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		if err := client.SetRetention(ctxRetention, "user_services", "timestamp", cfg.Service.Analytics.Retention); err != nil {
+			return nil, err
+		}
+		if err := client.SetRetention(ctxRetention, "executions", "started_at", cfg.Service.History.Retention); err != nil {
+			return nil, err
+		}
+
+		history, err = executionch.New(client.Conn())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create history repository: %w", err)
+		}
+	}
+
+	events, err := sinks.New(cfg, log, chClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create analytics sinks: %w", err)
 	}
 
-	service, err := services.New(cfg, log, contRepo, chClient)
+	service, err := services.New(cfg, log, contRepo, chClient, events, history)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create service: %w", err)
 	}
@@ -76,27 +147,166 @@ func New() (*App, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create jwt manager: %w", err)
 	}
-	pack := interceptors.New(log, jwtManager, map[string]bool{
-		xcutrpb.Xcutr_Execute_FullMethodName: true,
+
+	// Redis is needed for the rate limits n' the denylist only
+	var redisClient *redis.Client
+	if cfg.RateLimit.Enable || cfg.Denylist.Enable {
+		redisClient, err = rediscache.Connect(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect redis: %w", err)
+		}
+		checks["redis"] = rediscache.Ping(redisClient)
+	}
+
+	// The interface stays nil if the denylist is disabled
+	var denylist auth.Denylist
+	if cfg.Denylist.Enable {
+		denylist, err = denylistredis.New(cfg, redisClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create denylist: %w", err)
+		}
+	}
+
+	pack := interceptors.New(log, jwtManager, denylist, map[string]bool{
+		xcutrpb.Xcutr_Execute_FullMethodName:         true,
+		xcutrpb.Xcutr_CancelExecution_FullMethodName: true,
+		xcutrpb.Xcutr_AttachExecution_FullMethodName: true,
+		xcutrpb.Xcutr_ListExecutions_FullMethodName:  true,
+		xcutrpb.Xcutr_GetExecution_FullMethodName:    true,
+		xcutrpb.Xcutr_GetUsageStats_FullMethodName:   true,
+		xcutrpb.Xcutr_EraseUserData_FullMethodName:   true,
+	}, map[string]bool{
+		xcutrpb.Xcutr_Execute_FullMethodName: cfg.Features.RequireVerifiedEmail,
 	})
 
-	grpcServer := grpc.NewServer(grpc.StreamInterceptor(pack.AuthInterceptor()))
+	streamPack := []grpc.StreamServerInterceptor{
+		interceptors.MetricsInterceptor(),
+		pack.AuthInterceptor(),
+	}
+	unaryPack := []grpc.UnaryServerInterceptor{
+		interceptors.UnaryMetricsInterceptor(),
+		pack.UnaryAuthInterceptor(),
+	}
+
+	if cfg.RateLimit.Enable {
+		limiter, err := ratelimit.New(redisClient, rateLimitRules(cfg))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create rate limiter: %w", err)
+		}
+		rateLimiter := interceptors.NewRateLimiter(cfg, log, limiter)
+
+		streamPack = append(streamPack, rateLimiter.RateLimitInterceptor())
+		unaryPack = append(unaryPack, rateLimiter.UnaryRateLimitInterceptor())
+	}
+
+	grpcServer := grpc.NewServer(
+		// Continues the traces of the callers from the metadata
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainStreamInterceptor(streamPack...),
+		grpc.ChainUnaryInterceptor(unaryPack...),
+	)
 	xcutrpb.RegisterXcutrServer(grpcServer, api)
 
+	checker, err := health.New(log, cfg.Server.Health.Interval, cfg.Server.Health.Timeout, checks, xcutrpb.Xcutr_ServiceDesc.ServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create health checker: %w", err)
+	}
+	checker.Register(grpcServer)
+
 	server := grpcserver.New(cfg, grpcServer)
 
+	var metricsServer *sharedmetrics.Server
+	if cfg.Server.Metrics.Enable {
+		metricsServer = sharedmetrics.NewServer(
+			net.JoinHostPort(cfg.Server.Metrics.Host, cfg.Server.Metrics.Port),
+			metrics.Registry,
+		)
+	}
+
 	return &App{
-		log:    log,
-		server: server,
+		cfg:     cfg,
+		log:     log,
+		server:  server,
+		service: service,
+		docker:  dockerPool,
+		events:  events,
+		health:  checker,
+		redis:   redisClient,
+		metrics: metricsServer,
+		tracing: shutdownTracing,
 	}, nil
 }
 
 func (a *App) Start() error {
+	if a.metrics != nil {
+		go func() {
+			a.log.Info("metrics server is running", slog.String("port", a.cfg.Server.Metrics.Port))
+			if err := a.metrics.Start(); err != nil {
+				a.log.Error("metrics server is stopped", slog.String("error", err.Error()))
+			}
+		}()
+	}
+
+	a.health.Start()
+
 	a.log.Info("server is running")
 	return a.server.Start()
 }
 
 func (a *App) Shutdown() {
 	a.log.Info("server shutdown")
+
+	// Not serving from now on, so no new executions are sent here
+	a.health.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.Service.DrainTimeout)
+	defer cancel()
+
+	// Running executions must be finished (or killed) before the server stops,
+	// otherwise GracefulStop would wait for their streams
+	a.log.Info("draining executions", slog.Duration("timeout", a.cfg.Service.DrainTimeout))
+	a.service.Drain(ctx)
+	a.log.Info("all the executions are finished")
+
 	a.server.GracefulShutdown()
+	a.docker.Close()
+	if a.redis != nil {
+		if err := rediscache.Close(a.redis); err != nil {
+			a.log.Warn("failed to close redis connection", slog.String("error", err.Error()))
+		}
+	}
+
+	// The events of the drained executions are flushed last
+	ctxFlush, cancelFlush := context.WithTimeout(context.Background(), a.cfg.Service.Analytics.FlushTimeout)
+	defer cancelFlush()
+
+	if err := a.events.Close(ctxFlush); err != nil {
+		a.log.Warn("failed to flush analytics events", slog.String("error", err.Error()))
+	}
+
+	if err := a.tracing(ctxFlush); err != nil {
+		a.log.Warn("failed to flush spans", slog.String("error", err.Error()))
+	}
+
+	// The metrics are available till the very end of the shutdown
+	if a.metrics != nil {
+		if err := a.metrics.Shutdown(ctxFlush); err != nil {
+			a.log.Warn("failed to stop metrics server", slog.String("error", err.Error()))
+		}
+	}
+}
+
+func rateLimitRules(cfg *config.Config) []ratelimit.Rule {
+	rules := make([]ratelimit.Rule, 0, len(cfg.RateLimit.Rules))
+	for _, rule := range cfg.RateLimit.Rules {
+		rules = append(rules, ratelimit.Rule{
+			Name:   rule.Method,
+			Key:    rule.Key,
+			Rate:   rule.Rate,
+			Period: rule.Period,
+			Burst:  rule.Burst,
+		})
+	}
+
+	return rules
 }
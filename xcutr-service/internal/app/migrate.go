@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/observability/clickhouse"
+	"github.com/joho/godotenv"
+)
+
+const migrateUsage = "usage: migrate status | up | down [steps]"
+
+// Migrate manages the clickhouse schema without starting the server
+func Migrate(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	if err := godotenv.Load(".env"); err != nil {
+		return fmt.Errorf("failed to load .env: %w", err)
+	}
+
+	cfg, err := config.New(os.Getenv("APP_CONFIG_PATH"))
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	client, err := clickhouse.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create clickhouse client: %w", err)
+	}
+
+	switch args[0] {
+	case "status":
+		return printMigrations(client, out)
+	case "up":
+		if err := client.Up(); err != nil {
+			return err
+		}
+		return printMigrations(client, out)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return errors.New(migrateUsage)
+			}
+		}
+
+		if err := client.Down(steps); err != nil {
+			return err
+		}
+		return printMigrations(client, out)
+	}
+
+	return errors.New(migrateUsage)
+}
+
+func printMigrations(client *clickhouse.ClickhouseClient, out io.Writer) error {
+	statuses, err := client.Status(context.Background())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state := "pending"
+		switch {
+		case status.Missing:
+			state = "missing"
+		case status.Edited:
+			state = "edited"
+		case status.Applied:
+			state = "applied"
+		}
+
+		appliedAt := "-"
+		if status.Applied {
+			appliedAt = status.AppliedAt.Format(time.DateTime)
+		}
+
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+
+	return w.Flush()
+}
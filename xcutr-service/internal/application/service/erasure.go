@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	xcutrpb "github.com/devathh/coderun/xcutr-service/api/xcutr/v1"
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+func (x *xcutrService) EraseUserData(ctx context.Context, req *xcutrpb.EraseUserDataRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	adminID, err := x.getUserID(ctx)
+	if err != nil {
+		return err
+	}
+	if !x.admins[adminID] {
+		return customerrors.ErrNotAdmin
+	}
+
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return customerrors.ErrInvalidUserID
+	}
+
+	x.log.Info("erase data of the user",
+		slog.String("user_id", userID.String()),
+		slog.String("admin_id", adminID.String()),
+	)
+
+	x.erased.Erase(userID.String())
+
+	if err := x.events.EraseUser(ctx, userID.String()); err != nil {
+		return fmt.Errorf("failed to erase analytics: %w", err)
+	}
+
+	if x.history != nil {
+		if err := x.history.DeleteUser(ctx, userID); err != nil {
+			return fmt.Errorf("failed to erase history: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	xcutrpb "github.com/devathh/coderun/xcutr-service/api/xcutr/v1"
+	xcutrcontainer "github.com/devathh/coderun/xcutr-service/internal/domain/container"
+	xcutrexecution "github.com/devathh/coderun/xcutr-service/internal/domain/execution"
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (x *xcutrService) ListExecutions(ctx context.Context, req *xcutrpb.ListExecutionsRequest) (*xcutrpb.ListExecutionsResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if x.history == nil {
+		return nil, customerrors.ErrHistoryDisabled
+	}
+
+	userID, err := x.getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter xcutrexecution.Filter
+	if req.GetLanguage() != "" {
+		if _, ok := x.lang[req.GetLanguage()]; !ok {
+			return nil, customerrors.ErrInvalidLang
+		}
+		filter.Language = req.GetLanguage()
+	}
+	if req.GetStatus() != "" {
+		filter.Status, err = xcutrexecution.NewStatus(req.GetStatus())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	limit := int(req.GetLimit())
+	if limit <= 0 || limit > x.cfg.Service.History.PageLimit {
+		limit = x.cfg.Service.History.PageLimit
+	}
+
+	records, next, err := x.history.List(ctx, userID, filter, req.GetCursor(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	executions := make([]*xcutrpb.ExecutionRecord, 0, len(records))
+	for _, record := range records {
+		executions = append(executions, toRecordSummary(record))
+	}
+
+	return &xcutrpb.ListExecutionsResponse{
+		Executions: executions,
+		NextCursor: next,
+	}, nil
+}
+
+func (x *xcutrService) GetExecution(ctx context.Context, req *xcutrpb.GetExecutionRequest) (*xcutrpb.ExecutionRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if x.history == nil {
+		return nil, customerrors.ErrHistoryDisabled
+	}
+
+	userID, err := x.getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	execID, err := uuid.Parse(req.GetExecutionId())
+	if err != nil {
+		return nil, customerrors.ErrInvalidExecID
+	}
+
+	record, err := x.history.Get(ctx, userID, execID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toRecordSummary(record)
+	resp.Output = record.Output()
+	resp.Files = make([]*xcutrpb.SourceFile, 0, len(record.Files()))
+	for _, file := range record.Files() {
+		resp.Files = append(resp.Files, &xcutrpb.SourceFile{
+			Name:   file.Name,
+			Mime:   file.Mime,
+			Sha256: file.SHA256,
+			Body:   file.Body,
+		})
+	}
+
+	return resp, nil
+}
+
+func (x *xcutrService) saveRecord(ctx context.Context, exec *xcutrexecution.Execution, cont *xcutrcontainer.Container, language string, status xcutrexecution.Status, exitCode int, transcript *xcutrexecution.Transcript) {
+	// The user was erased while the execution was running
+	if x.erased.Erased(exec.UserID().String(), exec.StartedAt()) {
+		return
+	}
+
+	names := make([]string, 0, len(cont.Files()))
+	mimes := make([]string, 0, len(cont.Files()))
+	bodies := make([][]byte, 0, len(cont.Files()))
+	for _, file := range cont.Files() {
+		names = append(names, file.Name())
+		mimes = append(mimes, file.Mime())
+		bodies = append(bodies, file.Bytes())
+	}
+	files, sourceTruncated := xcutrexecution.NewSourceFiles(names, mimes, bodies, x.cfg.Service.History.SourceLimit)
+
+	record := xcutrexecution.NewRecord(
+		exec.ID(),
+		exec.UserID(),
+		language,
+		files,
+		sourceTruncated,
+		status,
+		exitCode,
+		exec.StartedAt(),
+		time.Since(exec.StartedAt()),
+		transcript.String(),
+		transcript.Truncated(),
+	)
+
+	// The execution may be over, only its trace is carried on
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), saveTimeout)
+	defer cancel()
+
+	if err := x.history.Save(ctx, record); err != nil {
+		x.log.Warn("failed to save execution into history",
+			slog.String("execution_id", exec.ID().String()),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// toRecordSummary converts the record without its source n' output
+func toRecordSummary(record *xcutrexecution.Record) *xcutrpb.ExecutionRecord {
+	return &xcutrpb.ExecutionRecord{
+		ExecutionId:     record.ID().String(),
+		Language:        record.Language(),
+		Status:          string(record.Status()),
+		ExitCode:        int32(record.ExitCode()),
+		StartedAt:       timestamppb.New(record.StartedAt()),
+		DurationMs:      record.Duration().Milliseconds(),
+		SourceTruncated: record.SourceTruncated(),
+		OutputTruncated: record.OutputTruncated(),
+	}
+}
@@ -9,31 +9,68 @@ import (
 	xcutrpb "github.com/devathh/coderun/xcutr-service/api/xcutr/v1"
 	"github.com/devathh/coderun/xcutr-service/internal/domain/auth"
 	xcutrcontainer "github.com/devathh/coderun/xcutr-service/internal/domain/container"
+	xcutrexecution "github.com/devathh/coderun/xcutr-service/internal/domain/execution"
 	xcutrlog "github.com/devathh/coderun/xcutr-service/internal/domain/log"
 	"github.com/devathh/coderun/xcutr-service/internal/domain/observability"
 	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/metrics"
 	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// Time to remove a container after its execution is over
+	deleteTimeout = 10 * time.Second
+	// Time to save the record of a finished execution
+	saveTimeout = 10 * time.Second
 )
 
 type xcutrService struct {
-	cfg      *config.Config
-	log      *slog.Logger
-	contRepo xcutrcontainer.ContainerRepository
-	lang     map[string]xcutrcontainer.Lang
-	chClient observability.ClickhouseClient
+	cfg        *config.Config
+	log        *slog.Logger
+	contRepo   xcutrcontainer.ContainerRepository
+	lang       map[string]xcutrcontainer.Lang
+	chClient   observability.ClickhouseClient
+	events     observability.ExecutionEventSink
+	executions *xcutrexecution.Registry
+	// Nil if the history is disabled
+	history xcutrexecution.HistoryRepository
+	// The records of the running executions of the erased users aren't saved
+	erased *observability.ErasedUsers
+	// Images of the languages for the analytics
+	images map[string]string
+	admins map[uuid.UUID]bool
 }
 
 type XcutrService interface {
 	Execute(*xcutrpb.ExecutionRequest, grpc.ServerStreamingServer[xcutrpb.Log]) error
+	CancelExecution(context.Context, *xcutrpb.CancelRequest) error
+	AttachExecution(*xcutrpb.AttachRequest, grpc.ServerStreamingServer[xcutrpb.Log]) error
+	ListExecutions(context.Context, *xcutrpb.ListExecutionsRequest) (*xcutrpb.ListExecutionsResponse, error)
+	GetExecution(context.Context, *xcutrpb.GetExecutionRequest) (*xcutrpb.ExecutionRecord, error)
+	GetUsageStats(context.Context, *xcutrpb.UsageStatsRequest) (*xcutrpb.UsageStats, error)
+	EraseUserData(context.Context, *xcutrpb.EraseUserDataRequest) error
+	// Drain stops accepting new executions and waits for the running ones.
+	// When ctx is done, the remaining executions are killed
+	Drain(ctx context.Context)
 }
 
-func New(cfg *config.Config, log *slog.Logger, contRepo xcutrcontainer.ContainerRepository, chClient observability.ClickhouseClient) (XcutrService, error) {
-	if cfg == nil || log == nil || contRepo == nil {
+func New(cfg *config.Config, log *slog.Logger, contRepo xcutrcontainer.ContainerRepository, chClient observability.ClickhouseClient, events observability.ExecutionEventSink, history xcutrexecution.HistoryRepository) (XcutrService, error) {
+	if cfg == nil || log == nil || contRepo == nil || events == nil {
 		return nil, customerrors.ErrNilArgs
 	}
 
+	admins := make(map[uuid.UUID]bool, len(cfg.Service.Admins))
+	for _, admin := range cfg.Service.Admins {
+		adminID, err := uuid.Parse(admin)
+		if err != nil {
+			return nil, err
+		}
+		admins[adminID] = true
+	}
+
 	return &xcutrService{
 		cfg:      cfg,
 		log:      log,
@@ -42,37 +79,203 @@ func New(cfg *config.Config, log *slog.Logger, contRepo xcutrcontainer.Container
 			"golang": xcutrcontainer.NewLang(xcutrcontainer.GO),
 			"python": xcutrcontainer.NewLang(xcutrcontainer.PYTHON),
 		},
-		chClient: chClient,
+		chClient:   chClient,
+		events:     events,
+		executions: xcutrexecution.NewRegistry(cfg.Service.Log.Retention),
+		history:    history,
+		erased:     observability.NewErasedUsers(),
+		images: map[string]string{
+			"golang": cfg.Secrets.Docker.ImageGo,
+			"python": cfg.Secrets.Docker.ImagePython,
+		},
+		admins: admins,
 	}, nil
 }
 
 func (x *xcutrService) Execute(req *xcutrpb.ExecutionRequest, stream grpc.ServerStreamingServer[xcutrpb.Log]) error {
-	ctx := stream.Context()
+	userID, err := x.getUserID(stream.Context())
+	if err != nil {
+		return err
+	}
 
-	x.log.Debug("start to run the service")
-	if err := x.goService(ctx, req, stream); err != nil {
+	cont, err := x.createCont(req)
+	if err != nil {
 		return err
 	}
 
-	if x.cfg.Features.ClickhouseEnable {
-		userID, err := x.getUserID(stream.Context())
-		if err != nil {
-			x.log.Warn("failed to get user id from context", slog.String("error", err.Error()))
-			return nil
-		}
+	// The execution outlives the stream for the detach timeout,
+	// so the client can re-attach
+	ctx, cancel := context.WithCancelCause(context.WithoutCancel(stream.Context()))
+	defer cancel(nil)
+
+	exec := xcutrexecution.New(userID, cancel, x.cfg.Service.Log.RingSize)
+	if err := x.executions.Add(exec); err != nil {
+		return err
+	}
+	defer x.executions.Remove(exec.ID())
+
+	metrics.ExecutionsRunning.Inc()
+	defer metrics.ExecutionsRunning.Dec()
+
+	// The client needs the id to cancel or re-attach the execution
+	// n' the share token to let others watch it
+	if err := stream.Send(&xcutrpb.Log{
+		ExecutionId: exec.ID().String(),
+		ShareToken:  exec.ShareToken(),
+	}); err != nil {
+		exec.Output().Close()
+		exec.Finish(nil)
+		return nil
+	}
+
+	x.log.Debug("start to run the service", slog.String("execution_id", exec.ID().String()))
+	transcript := xcutrexecution.NewTranscript(x.cfg.Service.History.OutputLimit)
+	status, exitCode, err := x.goService(ctx, exec, cont, transcript, stream)
+	exec.Output().Close()
+	exec.Finish(err)
+	metrics.Executions.WithLabelValues(req.GetLanguage(), string(status)).Inc()
 
-		go x.writeClckhouse(userID.String(), req.GetLanguage())
+	if x.history != nil {
+		go x.saveRecord(ctx, exec, cont, req.GetLanguage(), status, exitCode, transcript)
+	}
+
+	x.events.Write(&observability.ExecutionEvent{
+		Timestamp:   exec.StartedAt(),
+		ExecutionID: exec.ID(),
+		UserID:      userID.String(),
+		Language:    req.GetLanguage(),
+		Duration:    time.Since(exec.StartedAt()),
+		Status:      string(status),
+		ExitCode:    exitCode,
+		TimedOut:    status == xcutrexecution.StatusTimeout,
+		OOMKilled:   status == xcutrexecution.StatusOOM,
+		OutputBytes: uint64(transcript.Size()),
+		Image:       x.images[req.GetLanguage()],
+		Executor:    x.cfg.App.Instance,
+	})
+
+	if err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func (x *xcutrService) goService(ctx context.Context, req *xcutrpb.ExecutionRequest, stream grpc.ServerStreamingServer[xcutrpb.Log]) error {
-	cont, err := x.createCont(req)
+func (x *xcutrService) AttachExecution(req *xcutrpb.AttachRequest, stream grpc.ServerStreamingServer[xcutrpb.Log]) error {
+	userID, err := x.getUserID(stream.Context())
 	if err != nil {
 		return err
 	}
 
+	exec, err := x.getExecution(req.GetExecutionId())
+	if err != nil {
+		return err
+	}
+	if !exec.CanView(userID, req.GetShareToken()) {
+		return customerrors.ErrNotOwner
+	}
+
+	if err := exec.Attach(x.cfg.Service.Log.MaxViewers); err != nil {
+		return err
+	}
+	defer exec.Detach(x.cfg.Service.Log.DetachTimeout)
+
+	x.log.Debug("attach to the execution", slog.String("execution_id", exec.ID().String()))
+	if !x.follow(stream.Context(), exec, req.GetFromSeq(), stream) {
+		return nil
+	}
+
+	select {
+	case <-exec.Done():
+		return exec.Result()
+	case <-stream.Context().Done():
+		return nil
+	}
+}
+
+func (x *xcutrService) CancelExecution(ctx context.Context, req *xcutrpb.CancelRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	exec, err := x.ownExecution(ctx, req.GetExecutionId())
+	if err != nil {
+		return err
+	}
+
+	x.log.Debug("cancel the execution", slog.String("execution_id", exec.ID().String()))
+	exec.Cancel(customerrors.ErrCanceled)
+
+	return nil
+}
+
+// ownExecution returns the execution if it belongs to the user from ctx
+func (x *xcutrService) ownExecution(ctx context.Context, rawID string) (*xcutrexecution.Execution, error) {
+	userID, err := x.getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exec, err := x.getExecution(rawID)
+	if err != nil {
+		return nil, err
+	}
+	if exec.UserID() != userID {
+		return nil, customerrors.ErrNotOwner
+	}
+
+	return exec, nil
+}
+
+func (x *xcutrService) getExecution(rawID string) (*xcutrexecution.Execution, error) {
+	execID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidExecID
+	}
+
+	exec, ok := x.executions.Get(execID)
+	if !ok {
+		return nil, customerrors.ErrNotFoundExec
+	}
+
+	return exec, nil
+}
+
+func (x *xcutrService) Drain(ctx context.Context) {
+	x.executions.Close()
+	if err := x.executions.Wait(ctx); err == nil {
+		return
+	}
+
+	x.log.Warn("drain timeout is exceeded, killing the executions", slog.Int("count", x.executions.Len()))
+	x.executions.CancelAll(customerrors.ErrShuttingDown)
+
+	ctxCleanup, cancel := context.WithTimeout(context.Background(), deleteTimeout)
+	defer cancel()
+
+	if err := x.executions.Wait(ctxCleanup); err == nil {
+		return
+	}
+
+	// The executions didn't manage to clean up after themselves,
+	// so their containers are removed here
+	ctxDelete, cancelDelete := context.WithTimeout(context.Background(), deleteTimeout)
+	defer cancelDelete()
+
+	for _, exec := range x.executions.List() {
+		if exec.ContainerID() == "" {
+			continue
+		}
+
+		if err := x.contRepo.Delete(ctxDelete, exec.ContainerID()); err != nil && !errors.Is(err, customerrors.ErrNotFoundContainer) {
+			x.log.Warn("failed to delete container", slog.String("container_id", exec.ContainerID()), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// goService runs the container n' streams its output.
+// It returns the status of the execution n' the exit code of the program
+func (x *xcutrService) goService(ctx context.Context, exec *xcutrexecution.Execution, cont *xcutrcontainer.Container, transcript *xcutrexecution.Transcript, stream grpc.ServerStreamingServer[xcutrpb.Log]) (xcutrexecution.Status, int, error) {
 	ctxTimeout, cancel := context.WithTimeout(ctx, cont.MaxTimeout())
 	defer cancel()
 
@@ -80,47 +283,166 @@ func (x *xcutrService) goService(ctx context.Context, req *xcutrpb.ExecutionRequ
 	x.log.Debug("start to run container")
 	runningCont, err := x.contRepo.Run(ctxTimeout, cont)
 	if err != nil {
+		if err := stopCause(ctx); err != nil {
+			return stopStatus(err), -1, err
+		}
+		if errors.Is(err, customerrors.ErrNoDockerHosts) {
+			return xcutrexecution.StatusError, -1, err
+		}
+
 		x.log.Error("failed to run container", slog.String("error", err.Error()))
-		return customerrors.ErrInternalServer
+		return xcutrexecution.StatusError, -1, customerrors.ErrInternalServer
 	}
+	exec.SetContainerID(runningCont.ContID())
 
-	// After all, delete the container
+	// After all, delete the container.
+	// The execution's context may be already canceled here:
+	// on cancel or disconnect the container is removed right away
 	defer func() {
+		ctxDelete, cancel := context.WithTimeout(context.Background(), deleteTimeout)
+		defer cancel()
+
 		x.log.Debug("delete the container", slog.String("container_id", runningCont.ContID()))
-		if err := x.contRepo.Delete(ctx, runningCont.ContID()); err != nil {
+		if err := x.contRepo.Delete(ctxDelete, runningCont.ContID()); err != nil {
 			x.log.Warn("failed to delete container", slog.String("error", err.Error()))
 		}
 	}()
 
-	// Create server-stream.
-	// just transferring logs
-	// from the container to the stream
+	// Logs of the container go into the execution's output,
+	// the stream (n' the attached ones) read it from there
 	x.log.Debug("getting logs", slog.String("container_id", runningCont.ContID()))
 	logChan := make(chan *xcutrlog.Log, x.cfg.Service.Log.BufSize)
 	if err := x.contRepo.GetLogs(ctxTimeout, runningCont.ContID(), logChan); err != nil {
 		if errors.Is(err, customerrors.ErrNotFoundContainer) {
-			return err
+			return xcutrexecution.StatusError, -1, err
+		}
+		if err := stopCause(ctx); err != nil {
+			return stopStatus(err), -1, err
 		}
 
 		x.log.Error("failed to get logs from container", slog.String("error", err.Error()))
-		return customerrors.ErrInternalServer
+		return xcutrexecution.StatusError, -1, customerrors.ErrInternalServer
 	}
 
-	x.streamLogs(logChan, stream)
+	// The stream of the execution gets every log at the pace of its client,
+	// the attached ones read the ring n' may skip what dropped out of it
+	ownerLogs := make(chan *xcutrlog.Log)
+	ownerGone := make(chan struct{})
+	pumped := make(chan struct{})
+	go func() {
+		defer close(pumped)
+		for log := range logChan {
+			numbered := exec.Output().Append(log)
+			transcript.WriteLine(log.Msg())
+
+			select {
+			case ownerLogs <- numbered:
+			case <-ownerGone:
+			}
+		}
+		exec.Output().Close()
+		close(ownerLogs)
+	}()
 
-	return nil
+	// The stream of the execution is its first viewer
+	if !x.send(stream.Context(), ownerLogs, stream) {
+		close(ownerGone)
+		x.log.Debug("client is gone", slog.String("execution_id", exec.ID().String()))
+	}
+	exec.Detach(x.cfg.Service.Log.DetachTimeout)
+
+	<-pumped
+
+	if err := stopCause(ctx); err != nil {
+		return stopStatus(err), -1, err
+	}
+	if errors.Is(context.Cause(ctx), customerrors.ErrClientGone) {
+		return xcutrexecution.StatusAbandoned, -1, nil
+	}
+	if errors.Is(ctxTimeout.Err(), context.DeadlineExceeded) {
+		return xcutrexecution.StatusTimeout, -1, nil
+	}
+
+	status, exitCode := x.exitStatus(runningCont.ContID())
+	return status, exitCode, nil
+}
+
+// exitStatus inspects the exited program of the container
+func (x *xcutrService) exitStatus(containerID string) (xcutrexecution.Status, int) {
+	ctx, cancel := context.WithTimeout(context.Background(), deleteTimeout)
+	defer cancel()
+
+	exit, err := x.contRepo.Inspect(ctx, containerID)
+	if err != nil {
+		x.log.Warn("failed to inspect container", slog.String("container_id", containerID), slog.String("error", err.Error()))
+		return xcutrexecution.StatusError, -1
+	}
+
+	switch {
+	case exit.OOMKilled():
+		return xcutrexecution.StatusOOM, exit.Code()
+	case exit.Running():
+		// The output is over, but the program isn't. It's killed on delete
+		return xcutrexecution.StatusFailed, -1
+	case exit.Code() == 0:
+		return xcutrexecution.StatusSucceeded, 0
+	}
+
+	return xcutrexecution.StatusFailed, exit.Code()
 }
 
-func (x *xcutrService) streamLogs(logChan <-chan *xcutrlog.Log, stream grpc.ServerStreamingServer[xcutrpb.Log]) {
-	for log := range logChan {
-		if err := stream.Send(&xcutrpb.Log{
-			Msg: log.Msg(),
-		}); err != nil {
-			break
+// follow sends the output of the execution from the seq until it's complete.
+// It returns false if the client is gone
+func (x *xcutrService) follow(ctx context.Context, exec *xcutrexecution.Execution, from uint64, stream grpc.ServerStreamingServer[xcutrpb.Log]) bool {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	return x.send(ctx, exec.Output().Subscribe(ctx, from), stream) && ctx.Err() == nil
+}
+
+// send streams the logs until the channel is closed.
+// It returns false if the client is gone
+func (x *xcutrService) send(ctx context.Context, logs <-chan *xcutrlog.Log, stream grpc.ServerStreamingServer[xcutrpb.Log]) bool {
+	for {
+		select {
+		case log, ok := <-logs:
+			if !ok {
+				return true
+			}
+
+			if err := stream.Send(&xcutrpb.Log{
+				Msg:       log.Msg(),
+				Seq:       log.Seq(),
+				Timestamp: timestamppb.New(log.Time()),
+			}); err != nil {
+				return false
+			}
+		case <-ctx.Done():
+			return false
 		}
 	}
 }
 
+// stopCause returns the reason if the execution was stopped
+// by the user or by the shutdown. A gone client isn't reported,
+// there is no one to report to
+func stopCause(ctx context.Context) error {
+	cause := context.Cause(ctx)
+	if errors.Is(cause, customerrors.ErrShuttingDown) || errors.Is(cause, customerrors.ErrCanceled) {
+		return cause
+	}
+
+	return nil
+}
+
+func stopStatus(cause error) xcutrexecution.Status {
+	if errors.Is(cause, customerrors.ErrCanceled) {
+		return xcutrexecution.StatusCanceled
+	}
+
+	return xcutrexecution.StatusKilled
+}
+
 func (x *xcutrService) createCont(req *xcutrpb.ExecutionRequest) (*xcutrcontainer.Container, error) {
 	// Convert request's files to domain
 	files := make([]xcutrcontainer.File, 0, len(req.GetFiles()))
@@ -156,10 +478,22 @@ func (x *xcutrService) createCont(req *xcutrpb.ExecutionRequest) (*xcutrcontaine
 		return nil, customerrors.ErrInvalidLang
 	}
 
+	args, err := xcutrcontainer.NewArgs(req.GetArgs())
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := xcutrcontainer.NewEnv(req.GetEnv())
+	if err != nil {
+		return nil, err
+	}
+
 	cont, err := xcutrcontainer.New(
 		x.lang[req.GetLanguage()],
 		files,
 		timeout,
+		args,
+		env,
 	)
 	if err != nil {
 		return nil, err
@@ -180,10 +514,3 @@ func (x *xcutrService) getUserID(ctx context.Context) (uuid.UUID, error) {
 
 	return uuid.Nil, customerrors.ErrInvalidToken
 }
-
-func (x *xcutrService) writeClckhouse(userID, lang string) {
-	if err := x.chClient.WriteSession(context.Background(), userID, lang); err != nil {
-		x.log.Warn("failed to save session into clickhouse", slog.String("error", err.Error()))
-		return
-	}
-}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	xcutrpb "github.com/devathh/coderun/xcutr-service/api/xcutr/v1"
+	"github.com/devathh/coderun/xcutr-service/internal/domain/observability"
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+)
+
+const (
+	defaultStatsPeriod = 7 * 24 * time.Hour
+	defaultTopUsers    = 10
+	maxTopUsers        = 100
+)
+
+func (x *xcutrService) GetUsageStats(ctx context.Context, req *xcutrpb.UsageStatsRequest) (*xcutrpb.UsageStats, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	userID, err := x.getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !x.admins[userID] {
+		return nil, customerrors.ErrNotAdmin
+	}
+
+	if x.chClient == nil {
+		return nil, customerrors.ErrStatsDisabled
+	}
+
+	filter := observability.StatsFilter{
+		To:       time.Now(),
+		Language: req.GetLanguage(),
+		TopUsers: int(req.GetTopUsers()),
+	}
+	if req.GetTo() != nil {
+		filter.To = req.GetTo().AsTime()
+	}
+	filter.From = filter.To.Add(-defaultStatsPeriod)
+	if req.GetFrom() != nil {
+		filter.From = req.GetFrom().AsTime()
+	}
+	if !filter.From.Before(filter.To) {
+		return nil, customerrors.ErrInvalidPeriod
+	}
+
+	if filter.Language != "" {
+		if _, ok := x.lang[filter.Language]; !ok {
+			return nil, customerrors.ErrInvalidLang
+		}
+	}
+	if filter.TopUsers <= 0 {
+		filter.TopUsers = defaultTopUsers
+	}
+	filter.TopUsers = min(filter.TopUsers, maxTopUsers)
+
+	stats, err := x.chClient.UsageStats(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &xcutrpb.UsageStats{
+		Daily:     make([]*xcutrpb.DailyRuns, 0, len(stats.Daily)),
+		Languages: make([]*xcutrpb.LanguageStats, 0, len(stats.Languages)),
+		TopUsers:  make([]*xcutrpb.UserUsage, 0, len(stats.TopUsers)),
+	}
+	for _, daily := range stats.Daily {
+		resp.Daily = append(resp.Daily, &xcutrpb.DailyRuns{
+			Day:      daily.Day.Format(time.DateOnly),
+			Language: daily.Language,
+			Runs:     daily.Runs,
+		})
+	}
+	for _, lang := range stats.Languages {
+		resp.Languages = append(resp.Languages, &xcutrpb.LanguageStats{
+			Language:      lang.Language,
+			Runs:          lang.Runs,
+			Failures:      lang.Failures,
+			FailureRate:   lang.FailureRate,
+			Timeouts:      lang.Timeouts,
+			OomKills:      lang.OOMKills,
+			P50DurationMs: lang.P50Duration.Milliseconds(),
+			P95DurationMs: lang.P95Duration.Milliseconds(),
+		})
+	}
+	for _, user := range stats.TopUsers {
+		resp.TopUsers = append(resp.TopUsers, &xcutrpb.UserUsage{
+			UserId:     user.UserID,
+			Runs:       user.Runs,
+			DurationMs: user.Duration.Milliseconds(),
+		})
+	}
+
+	return resp, nil
+}
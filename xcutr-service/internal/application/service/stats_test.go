@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	xcutrpb "github.com/devathh/coderun/xcutr-service/api/xcutr/v1"
+	"github.com/devathh/coderun/xcutr-service/internal/domain/auth"
+	xcutrcontainer "github.com/devathh/coderun/xcutr-service/internal/domain/container"
+	"github.com/devathh/coderun/xcutr-service/internal/domain/observability"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeStats returns the stats n' keeps the filter it was asked with
+type fakeStats struct {
+	observability.ClickhouseClient
+
+	stats  *observability.UsageStats
+	filter *observability.StatsFilter
+}
+
+func (fs *fakeStats) UsageStats(_ context.Context, filter observability.StatsFilter) (*observability.UsageStats, error) {
+	fs.filter = &filter
+	return fs.stats, nil
+}
+
+type fakeContainers struct {
+	xcutrcontainer.ContainerRepository
+}
+
+type fakeEvents struct {
+	observability.ExecutionEventSink
+}
+
+func newStatsService(t *testing.T, chClient observability.ClickhouseClient, admins ...uuid.UUID) *xcutrService {
+	t.Helper()
+
+	cfg := &config.Config{}
+	for _, admin := range admins {
+		cfg.Service.Admins = append(cfg.Service.Admins, admin.String())
+	}
+
+	service, err := New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), fakeContainers{}, chClient, fakeEvents{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	return service.(*xcutrService)
+}
+
+func TestGetUsageStats(t *testing.T) {
+	admin, user := uuid.New(), uuid.New()
+	to := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		Name string
+		// Nil if the call has no user
+		UserID   *uuid.UUID
+		Disabled bool
+		Req      *xcutrpb.UsageStatsRequest
+		WantErr  error
+		// The filter clickhouse is asked with
+		WantFilter observability.StatsFilter
+	}{
+		{Name: "no_user", Req: &xcutrpb.UsageStatsRequest{}, WantErr: customerrors.ErrInvalidToken},
+		{Name: "not_admin", UserID: &user, Req: &xcutrpb.UsageStatsRequest{}, WantErr: customerrors.ErrNotAdmin},
+		{Name: "disabled", UserID: &admin, Disabled: true, Req: &xcutrpb.UsageStatsRequest{}, WantErr: customerrors.ErrStatsDisabled},
+		{
+			Name:       "default_period",
+			UserID:     &admin,
+			Req:        &xcutrpb.UsageStatsRequest{To: timestamppb.New(to)},
+			WantFilter: observability.StatsFilter{From: to.Add(-defaultStatsPeriod), To: to, TopUsers: defaultTopUsers},
+		},
+		{
+			Name:   "filtered",
+			UserID: &admin,
+			Req: &xcutrpb.UsageStatsRequest{
+				From:     timestamppb.New(to.Add(-time.Hour)),
+				To:       timestamppb.New(to),
+				Language: "python",
+				TopUsers: 5,
+			},
+			WantFilter: observability.StatsFilter{From: to.Add(-time.Hour), To: to, Language: "python", TopUsers: 5},
+		},
+		{
+			Name:       "top_users_capped",
+			UserID:     &admin,
+			Req:        &xcutrpb.UsageStatsRequest{To: timestamppb.New(to), TopUsers: 1000},
+			WantFilter: observability.StatsFilter{From: to.Add(-defaultStatsPeriod), To: to, TopUsers: maxTopUsers},
+		},
+		{
+			Name:    "reversed_period",
+			UserID:  &admin,
+			Req:     &xcutrpb.UsageStatsRequest{From: timestamppb.New(to), To: timestamppb.New(to.Add(-time.Hour))},
+			WantErr: customerrors.ErrInvalidPeriod,
+		},
+		{
+			Name:    "unknown_language",
+			UserID:  &admin,
+			Req:     &xcutrpb.UsageStatsRequest{To: timestamppb.New(to), Language: "cobol"},
+			WantErr: customerrors.ErrInvalidLang,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			stats := &fakeStats{stats: &observability.UsageStats{
+				Daily:     []observability.DailyRuns{{Day: to, Language: "python", Runs: 3}},
+				Languages: []observability.LanguageStats{{Language: "python", Runs: 3, P95Duration: 1500 * time.Millisecond}},
+				TopUsers:  []observability.UserUsage{{UserID: user.String(), Runs: 3, Duration: 2 * time.Second}},
+			}}
+			var chClient observability.ClickhouseClient = stats
+			if tc.Disabled {
+				chClient = nil
+			}
+			service := newStatsService(t, chClient, admin)
+
+			ctx := t.Context()
+			if tc.UserID != nil {
+				ctx = context.WithValue(ctx, auth.CtxKey("user_id"), *tc.UserID)
+			}
+
+			resp, err := service.GetUsageStats(ctx, tc.Req)
+			if !errors.Is(err, tc.WantErr) {
+				t.Fatalf("got %v, want %v", err, tc.WantErr)
+			}
+			if err != nil {
+				if stats.filter != nil {
+					t.Errorf("got clickhouse asked, want the call refused before")
+				}
+				return
+			}
+
+			if *stats.filter != tc.WantFilter {
+				t.Errorf("got filter %+v, want %+v", *stats.filter, tc.WantFilter)
+			}
+			if len(resp.GetDaily()) != 1 || resp.GetDaily()[0].GetDay() != "2026-05-10" {
+				t.Errorf("got daily %v, want the day of the stats", resp.GetDaily())
+			}
+			if len(resp.GetLanguages()) != 1 || resp.GetLanguages()[0].GetP95DurationMs() != 1500 {
+				t.Errorf("got languages %v, want p95 of 1500ms", resp.GetLanguages())
+			}
+			if len(resp.GetTopUsers()) != 1 || resp.GetTopUsers()[0].GetDurationMs() != 2000 {
+				t.Errorf("got top users %v, want 2000ms", resp.GetTopUsers())
+			}
+		})
+	}
+}
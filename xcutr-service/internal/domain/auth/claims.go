@@ -5,10 +5,16 @@ import (
 	"github.com/google/uuid"
 )
 
+// CoderunClaims of the access token issued by sso. It has no jti, the tokens are revoked by their session
 type CoderunClaims struct {
 	jwt.RegisteredClaims
 	UserID uuid.UUID
-	Email  string
+	// The session the token is issued in, it's revoked along with it.
+	// Nil in the tokens issued before the sessions had ids
+	SessionID uuid.UUID
+	Email     string
+	// False if the user hasn't verified the email yet
+	EmailVerified bool
 }
 
 type CtxKey string
@@ -1,5 +1,12 @@
 package auth
 
+import "context"
+
 type JWTManager interface {
 	Validate(tokenString string) (*CoderunClaims, error)
 }
+
+// Denylist of the access tokens revoked by sso before the expiry
+type Denylist interface {
+	IsRevoked(ctx context.Context, claims *CoderunClaims) (bool, error)
+}
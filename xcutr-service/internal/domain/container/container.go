@@ -12,10 +12,12 @@ type Container struct {
 	language    Lang
 	files       []File
 	maxTimeout  time.Duration
+	args        Args
+	env         Env
 	containerID string
 }
 
-func New(lang Lang, files []File, maxTimeout time.Duration) (*Container, error) {
+func New(lang Lang, files []File, maxTimeout time.Duration, args Args, env Env) (*Container, error) {
 	if len(files) < 1 {
 		return nil, customerrors.ErrNoFiles
 	}
@@ -25,6 +27,8 @@ func New(lang Lang, files []File, maxTimeout time.Duration) (*Container, error)
 		language:    lang,
 		files:       files,
 		maxTimeout:  maxTimeout,
+		args:        args,
+		env:         env,
 		containerID: "",
 	}, nil
 }
@@ -34,6 +38,8 @@ func From(
 	lang Lang,
 	files []File,
 	maxTimeout time.Duration,
+	args Args,
+	env Env,
 	containerID string,
 ) *Container {
 	return &Container{
@@ -41,6 +47,8 @@ func From(
 		language:    lang,
 		files:       files,
 		maxTimeout:  maxTimeout,
+		args:        args,
+		env:         env,
 		containerID: containerID,
 	}
 }
@@ -63,6 +71,14 @@ func (c *Container) MaxTimeout() time.Duration {
 	return c.maxTimeout
 }
 
+func (c *Container) Args() Args {
+	return c.args
+}
+
+func (c *Container) Env() Env {
+	return c.env
+}
+
 func (c *Container) ContID() string {
 	return c.containerID
 }
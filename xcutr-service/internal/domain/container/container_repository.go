@@ -10,4 +10,5 @@ type ContainerRepository interface {
 	Run(context.Context, *Container) (*Container, error)
 	Delete(context.Context, string) error
 	GetLogs(context.Context, string, chan<- *xcutrlog.Log) error
+	Inspect(context.Context, string) (Exit, error)
 }
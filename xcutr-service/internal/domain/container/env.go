@@ -0,0 +1,119 @@
+package xcutrcontainer
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+)
+
+const (
+	maxEnvVars    = 64
+	maxEnvSize    = 32 * 1024
+	maxArgs       = 64
+	maxArgsSize   = 32 * 1024
+	maxEnvNameLen = 128
+)
+
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Names that are set by images or the runtime and
+// may break the sandbox or the run command
+var reservedEnv = map[string]bool{
+	"PATH":            true,
+	"HOME":            true,
+	"HOSTNAME":        true,
+	"USER":            true,
+	"SHELL":           true,
+	"PWD":             true,
+	"LD_PRELOAD":      true,
+	"LD_LIBRARY_PATH": true,
+	"GOROOT":          true,
+	"GOPATH":          true,
+	"GOCACHE":         true,
+	"GOFLAGS":         true,
+	"GOTOOLCHAIN":     true,
+	"PYTHONHOME":      true,
+	"PYTHONPATH":      true,
+	"PYTHONSTARTUP":   true,
+}
+
+// Env is a set of user's environment variables
+type Env struct {
+	vars map[string]string
+}
+
+func NewEnv(vars map[string]string) (Env, error) {
+	if len(vars) > maxEnvVars {
+		return Env{}, customerrors.ErrTooManyEnv
+	}
+
+	size := 0
+	env := make(map[string]string, len(vars))
+	for name, value := range vars {
+		if len(name) > maxEnvNameLen || !envNameRegexp.MatchString(name) {
+			return Env{}, fmt.Errorf("%w: %s", customerrors.ErrInvalidEnv, name)
+		}
+		if reservedEnv[strings.ToUpper(name)] {
+			return Env{}, fmt.Errorf("%w: %s", customerrors.ErrReservedEnv, name)
+		}
+		if strings.ContainsRune(value, 0) {
+			return Env{}, fmt.Errorf("%w: %s", customerrors.ErrInvalidEnv, name)
+		}
+
+		size += len(name) + len(value)
+		if size > maxEnvSize {
+			return Env{}, customerrors.ErrTooLargeEnv
+		}
+
+		env[name] = value
+	}
+
+	return Env{
+		vars: env,
+	}, nil
+}
+
+// List returns the variables in NAME=value form, sorted by name
+func (e Env) List() []string {
+	list := make([]string, 0, len(e.vars))
+	for name, value := range e.vars {
+		list = append(list, name+"="+value)
+	}
+	slices.Sort(list)
+
+	return list
+}
+
+// Args are command-line arguments of user's program
+type Args struct {
+	args []string
+}
+
+func NewArgs(args []string) (Args, error) {
+	if len(args) > maxArgs {
+		return Args{}, customerrors.ErrTooManyArgs
+	}
+
+	size := 0
+	for _, arg := range args {
+		if strings.ContainsRune(arg, 0) {
+			return Args{}, customerrors.ErrInvalidArgs
+		}
+
+		size += len(arg)
+		if size > maxArgsSize {
+			return Args{}, customerrors.ErrTooLargeArgs
+		}
+	}
+
+	return Args{
+		args: slices.Clone(args),
+	}, nil
+}
+
+func (a Args) List() []string {
+	return slices.Clone(a.args)
+}
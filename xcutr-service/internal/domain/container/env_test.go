@@ -0,0 +1,116 @@
+package xcutrcontainer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+)
+
+func manyVars(n int) map[string]string {
+	vars := make(map[string]string, n)
+	for i := range n {
+		vars[fmt.Sprintf("VAR_%d", i)] = "value"
+	}
+
+	return vars
+}
+
+func TestNewEnv(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Vars    map[string]string
+		WantErr error
+	}{
+		{Name: "base", Vars: map[string]string{"NAME": "value", "_private": "", "a1": "x=y"}, WantErr: nil},
+		{Name: "empty", Vars: nil, WantErr: nil},
+		{Name: "max_vars", Vars: manyVars(maxEnvVars), WantErr: nil},
+		{Name: "too_many_vars", Vars: manyVars(maxEnvVars + 1), WantErr: customerrors.ErrTooManyEnv},
+		{Name: "empty_name", Vars: map[string]string{"": "value"}, WantErr: customerrors.ErrInvalidEnv},
+		{Name: "leading_digit", Vars: map[string]string{"1NAME": "value"}, WantErr: customerrors.ErrInvalidEnv},
+		{Name: "equals_in_name", Vars: map[string]string{"NAME=X": "value"}, WantErr: customerrors.ErrInvalidEnv},
+		{Name: "dash_in_name", Vars: map[string]string{"MY-NAME": "value"}, WantErr: customerrors.ErrInvalidEnv},
+		{Name: "max_name", Vars: map[string]string{strings.Repeat("N", maxEnvNameLen): ""}, WantErr: nil},
+		{Name: "too_long_name", Vars: map[string]string{strings.Repeat("N", maxEnvNameLen+1): ""}, WantErr: customerrors.ErrInvalidEnv},
+		{Name: "nul_in_value", Vars: map[string]string{"NAME": "a\x00b"}, WantErr: customerrors.ErrInvalidEnv},
+		{Name: "reserved", Vars: map[string]string{"PATH": "/tmp"}, WantErr: customerrors.ErrReservedEnv},
+		{Name: "reserved_lowercase", Vars: map[string]string{"ld_preload": "x.so"}, WantErr: customerrors.ErrReservedEnv},
+		{Name: "max_size", Vars: map[string]string{"N": strings.Repeat("v", maxEnvSize-1)}, WantErr: nil},
+		{Name: "too_large", Vars: map[string]string{"N": strings.Repeat("v", maxEnvSize)}, WantErr: customerrors.ErrTooLargeEnv},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := NewEnv(tc.Vars)
+			if !errors.Is(err, tc.WantErr) {
+				t.Fatalf("got %v, want %v", err, tc.WantErr)
+			}
+			if err == nil && len(env.List()) != len(tc.Vars) {
+				t.Errorf("got %d vars, want %d", len(env.List()), len(tc.Vars))
+			}
+		})
+	}
+}
+
+func TestEnvList(t *testing.T) {
+	env, err := NewEnv(map[string]string{"B": "2", "A": "1=1", "C": ""})
+	if err != nil {
+		t.Fatalf("failed to create env: %v", err)
+	}
+
+	want := []string{"A=1=1", "B=2", "C="}
+	if got := env.List(); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestNewArgs(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Args    []string
+		WantErr error
+	}{
+		{Name: "base", Args: []string{"-v", "--name=x", ""}, WantErr: nil},
+		{Name: "empty", Args: nil, WantErr: nil},
+		{Name: "max_args", Args: make([]string, maxArgs), WantErr: nil},
+		{Name: "too_many_args", Args: make([]string, maxArgs+1), WantErr: customerrors.ErrTooManyArgs},
+		{Name: "nul", Args: []string{"a\x00b"}, WantErr: customerrors.ErrInvalidArgs},
+		{Name: "max_size", Args: []string{strings.Repeat("a", maxArgsSize)}, WantErr: nil},
+		{Name: "too_large", Args: []string{strings.Repeat("a", maxArgsSize), "b"}, WantErr: customerrors.ErrTooLargeArgs},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			args, err := NewArgs(tc.Args)
+			if !errors.Is(err, tc.WantErr) {
+				t.Fatalf("got %v, want %v", err, tc.WantErr)
+			}
+			if err == nil && !slices.Equal(args.List(), tc.Args) && len(tc.Args) != 0 {
+				t.Errorf("got %v, want %v", args.List(), tc.Args)
+			}
+		})
+	}
+}
+
+func TestArgsCopied(t *testing.T) {
+	raw := []string{"a", "b"}
+	args, err := NewArgs(raw)
+	if err != nil {
+		t.Fatalf("failed to create args: %v", err)
+	}
+
+	raw[0] = "changed"
+	list := args.List()
+	list[1] = "changed"
+
+	if got, want := args.List(), []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,29 @@
+package xcutrcontainer
+
+// Exit is the state of a container's program
+type Exit struct {
+	running   bool
+	code      int
+	oomKilled bool
+}
+
+func NewExit(running bool, code int, oomKilled bool) Exit {
+	return Exit{
+		running:   running,
+		code:      code,
+		oomKilled: oomKilled,
+	}
+}
+
+// Running reports that the program hasn't exited yet
+func (e Exit) Running() bool {
+	return e.running
+}
+
+func (e Exit) Code() int {
+	return e.code
+}
+
+func (e Exit) OOMKilled() bool {
+	return e.oomKilled
+}
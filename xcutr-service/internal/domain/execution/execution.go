@@ -0,0 +1,181 @@
+package xcutrexecution
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"sync"
+	"time"
+
+	xcutrlog "github.com/devathh/coderun/xcutr-service/internal/domain/log"
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// Execution is a single run of user's code.
+// It owns the cancel func of the run's context,
+// the id of the container that was started for it
+// and the buffered output, which viewers read.
+// Besides the owner, the output is available to
+// anyone who has the share token
+type Execution struct {
+	id         uuid.UUID
+	userID     uuid.UUID
+	shareToken string
+	startedAt  time.Time
+	cancel     context.CancelCauseFunc
+	output     *xcutrlog.Buffer
+	done       chan struct{}
+	// Starts the grace after the last viewer is gone, returns its stop func
+	afterFunc func(time.Duration, func()) func() bool
+
+	mu          sync.RWMutex
+	containerID string
+	viewers     int
+	stopDetach  func() bool
+	result      error
+}
+
+// New creates an execution with one viewer:
+// the stream that started it
+func New(userID uuid.UUID, cancel context.CancelCauseFunc, outputSize int) *Execution {
+	return &Execution{
+		id:         uuid.New(),
+		userID:     userID,
+		shareToken: rand.Text(),
+		startedAt:  time.Now(),
+		cancel:     cancel,
+		output:     xcutrlog.NewBuffer(outputSize),
+		done:       make(chan struct{}),
+		afterFunc: func(d time.Duration, f func()) func() bool {
+			return time.AfterFunc(d, f).Stop
+		},
+		viewers: 1,
+	}
+}
+
+func (e *Execution) ID() uuid.UUID {
+	return e.id
+}
+
+func (e *Execution) UserID() uuid.UUID {
+	return e.userID
+}
+
+func (e *Execution) ShareToken() string {
+	return e.shareToken
+}
+
+// CanView reports whether the user may watch the output
+func (e *Execution) CanView(userID uuid.UUID, shareToken string) bool {
+	if e.userID == userID {
+		return true
+	}
+
+	return shareToken != "" && subtle.ConstantTimeCompare([]byte(shareToken), []byte(e.shareToken)) == 1
+}
+
+func (e *Execution) StartedAt() time.Time {
+	return e.startedAt
+}
+
+func (e *Execution) Output() *xcutrlog.Buffer {
+	return e.output
+}
+
+func (e *Execution) ContainerID() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.containerID
+}
+
+func (e *Execution) SetContainerID(containerID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.containerID = containerID
+}
+
+// Cancel stops the execution, cause is available
+// through context.Cause of the execution's context
+func (e *Execution) Cancel(cause error) {
+	e.cancel(cause)
+}
+
+// Attach registers a viewer of the output,
+// maxViewers limits the viewers at once
+func (e *Execution) Attach(maxViewers int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.viewers >= maxViewers {
+		return customerrors.ErrTooManyViewers
+	}
+
+	e.viewers++
+	if e.stopDetach != nil {
+		e.stopDetach()
+		e.stopDetach = nil
+	}
+
+	return nil
+}
+
+// Detach unregisters a viewer. When the last one is gone,
+// the execution is canceled after the grace, unless somebody attaches
+func (e *Execution) Detach(grace time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.viewers--
+	if e.viewers > 0 || e.output.Closed() {
+		return
+	}
+
+	if grace <= 0 {
+		e.cancel(customerrors.ErrClientGone)
+		return
+	}
+
+	e.stopDetach = e.afterFunc(grace, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		if e.viewers == 0 {
+			e.cancel(customerrors.ErrClientGone)
+		}
+	})
+}
+
+// Finish records the result of the execution
+func (e *Execution) Finish(result error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return
+	default:
+	}
+
+	e.result = result
+	if e.stopDetach != nil {
+		e.stopDetach()
+		e.stopDetach = nil
+	}
+	close(e.done)
+}
+
+// Done is closed when the execution is finished
+func (e *Execution) Done() <-chan struct{} {
+	return e.done
+}
+
+// Result is the error the execution finished with
+func (e *Execution) Result() error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.result
+}
@@ -0,0 +1,211 @@
+package xcutrexecution
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// fakeTimer holds the grace of the execution until the test fires it
+type fakeTimer struct {
+	mu      sync.Mutex
+	started bool
+	stopped bool
+	grace   time.Duration
+	fire    func()
+}
+
+func (ft *fakeTimer) afterFunc(grace time.Duration, f func()) func() bool {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	ft.started, ft.stopped, ft.grace, ft.fire = true, false, grace, f
+	return func() bool {
+		ft.mu.Lock()
+		defer ft.mu.Unlock()
+
+		stopped := !ft.stopped
+		ft.stopped = true
+		return stopped
+	}
+}
+
+// elapse acts as if the grace passed, the stopped timer doesn't fire
+func (ft *fakeTimer) elapse() {
+	ft.mu.Lock()
+	fire := ft.fire
+	if !ft.started || ft.stopped {
+		fire = nil
+	}
+	ft.mu.Unlock()
+
+	if fire != nil {
+		fire()
+	}
+}
+
+func newTimedExecution(t *testing.T) (*Execution, *fakeTimer, context.Context) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancelCause(t.Context())
+	exec := New(uuid.New(), cancel, 10)
+	timer := &fakeTimer{}
+	exec.afterFunc = timer.afterFunc
+
+	return exec, timer, ctx
+}
+
+func TestExecutionAttach(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		MaxViewers int
+		Attached   int
+		WantErr    error
+	}{
+		// The stream that started the execution is a viewer too
+		{Name: "below_limit", MaxViewers: 3, Attached: 2, WantErr: nil},
+		{Name: "at_limit", MaxViewers: 3, Attached: 3, WantErr: customerrors.ErrTooManyViewers},
+		{Name: "only_owner", MaxViewers: 1, Attached: 1, WantErr: customerrors.ErrTooManyViewers},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			exec, _, _ := newTimedExecution(t)
+
+			var err error
+			for range tc.Attached {
+				if err = exec.Attach(tc.MaxViewers); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tc.WantErr) {
+				t.Errorf("got %v, want %v", err, tc.WantErr)
+			}
+		})
+	}
+}
+
+func TestExecutionDetach(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Grace time.Duration
+		// Viewers attached besides the owner before everybody detaches
+		Viewers int
+		// Somebody attaches within the grace
+		Reattach bool
+		// The output is complete before the detach
+		Closed bool
+		// The grace is started n' the execution is canceled after it
+		WantGrace  bool
+		WantCancel bool
+	}{
+		{Name: "no_grace", Grace: 0, WantCancel: true},
+		{Name: "grace", Grace: time.Minute, WantGrace: true, WantCancel: true},
+		{Name: "reattach", Grace: time.Minute, Reattach: true, WantGrace: true, WantCancel: false},
+		{Name: "viewer_left", Grace: 0, Viewers: 1, WantCancel: false},
+		{Name: "finished", Grace: time.Minute, Closed: true, WantCancel: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			exec, timer, ctx := newTimedExecution(t)
+
+			for range tc.Viewers {
+				if err := exec.Attach(10); err != nil {
+					t.Fatalf("failed to attach: %v", err)
+				}
+			}
+			if tc.Closed {
+				exec.Output().Close()
+			}
+
+			// The owner leaves, the viewers stay
+			exec.Detach(tc.Grace)
+			if timer.started != tc.WantGrace || (timer.started && timer.grace != tc.Grace) {
+				t.Errorf("got grace %v of %v, want %v of %v", timer.started, timer.grace, tc.WantGrace, tc.Grace)
+			}
+
+			// Not canceled until the grace passes
+			if tc.WantGrace && context.Cause(ctx) != nil {
+				t.Errorf("got %v before the grace, want nil", context.Cause(ctx))
+			}
+
+			if tc.Reattach {
+				if err := exec.Attach(10); err != nil {
+					t.Fatalf("failed to attach: %v", err)
+				}
+			}
+			timer.elapse()
+
+			canceled := errors.Is(context.Cause(ctx), customerrors.ErrClientGone)
+			if canceled != tc.WantCancel {
+				t.Errorf("got canceled %v, want %v", canceled, tc.WantCancel)
+			}
+		})
+	}
+}
+
+func TestExecutionFinish(t *testing.T) {
+	exec, timer, ctx := newTimedExecution(t)
+
+	exec.Detach(time.Minute)
+
+	result := errors.New("exit status 1")
+	exec.Finish(result)
+	// Only the first result counts
+	exec.Finish(nil)
+
+	select {
+	case <-exec.Done():
+	default:
+		t.Fatalf("done isn't closed")
+	}
+	if err := exec.Result(); !errors.Is(err, result) {
+		t.Errorf("got %v, want %v", err, result)
+	}
+
+	// The finished execution isn't canceled by the grace
+	timer.elapse()
+	if err := context.Cause(ctx); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+}
+
+func TestExecutionCanView(t *testing.T) {
+	owner := uuid.New()
+	_, cancel := context.WithCancelCause(t.Context())
+	exec := New(owner, cancel, 10)
+
+	testCases := []struct {
+		Name       string
+		UserID     uuid.UUID
+		ShareToken string
+		Want       bool
+	}{
+		{Name: "owner", UserID: owner, ShareToken: "", Want: true},
+		{Name: "owner_wrong_token", UserID: owner, ShareToken: "wrong", Want: true},
+		{Name: "shared", UserID: uuid.New(), ShareToken: exec.ShareToken(), Want: true},
+		{Name: "no_token", UserID: uuid.New(), ShareToken: "", Want: false},
+		{Name: "wrong_token", UserID: uuid.New(), ShareToken: "wrong", Want: false},
+		{Name: "token_prefix", UserID: uuid.New(), ShareToken: exec.ShareToken()[:4], Want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := exec.CanView(tc.UserID, tc.ShareToken); got != tc.Want {
+				t.Errorf("got %v, want %v", got, tc.Want)
+			}
+		})
+	}
+}
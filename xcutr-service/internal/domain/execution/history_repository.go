@@ -0,0 +1,23 @@
+package xcutrexecution
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Filter of the history, empty fields match everything
+type Filter struct {
+	Language string
+	Status   Status
+}
+
+type HistoryRepository interface {
+	Save(context.Context, *Record) error
+	// List returns the records of the user from the newest one,
+	// starting after the cursor. The next cursor is empty on the last page.
+	// The records come without the bodies of the files n' the output, Get has them
+	List(ctx context.Context, userID uuid.UUID, filter Filter, cursor string, limit int) ([]*Record, string, error)
+	Get(ctx context.Context, userID, id uuid.UUID) (*Record, error)
+	DeleteUser(ctx context.Context, userID uuid.UUID) error
+}
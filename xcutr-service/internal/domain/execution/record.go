@@ -0,0 +1,160 @@
+package xcutrexecution
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusTimeout   Status = "timeout"
+	StatusOOM       Status = "oom"
+	StatusCanceled  Status = "canceled"
+	// The client was gone n' nobody re-attached
+	StatusAbandoned Status = "abandoned"
+	// Killed by the shutdown of the executor
+	StatusKilled Status = "killed"
+	// The container couldn't be run
+	StatusError Status = "error"
+)
+
+func NewStatus(status string) (Status, error) {
+	switch s := Status(status); s {
+	case StatusSucceeded, StatusFailed, StatusTimeout, StatusOOM,
+		StatusCanceled, StatusAbandoned, StatusKilled, StatusError:
+		return s, nil
+	}
+
+	return "", customerrors.ErrInvalidStatus
+}
+
+// SourceFile is a snapshot of a file of the execution.
+// Body is empty if the source was too large to keep
+type SourceFile struct {
+	Name   string
+	Mime   string
+	SHA256 string
+	Body   []byte
+}
+
+// NewSourceFiles hashes the files n' keeps their bodies
+// if all of them fit into the limit
+func NewSourceFiles(names, mimes []string, bodies [][]byte, limit int) ([]SourceFile, bool) {
+	total := 0
+	for _, body := range bodies {
+		total += len(body)
+	}
+	truncated := total > limit
+
+	files := make([]SourceFile, 0, len(names))
+	for i := range names {
+		hash := sha256.Sum256(bodies[i])
+		file := SourceFile{
+			Name:   names[i],
+			Mime:   mimes[i],
+			SHA256: hex.EncodeToString(hash[:]),
+		}
+		if !truncated {
+			file.Body = bodies[i]
+		}
+		files = append(files, file)
+	}
+
+	return files, truncated
+}
+
+// Record is what is kept about a finished execution
+type Record struct {
+	id              uuid.UUID
+	userID          uuid.UUID
+	language        string
+	files           []SourceFile
+	sourceTruncated bool
+	status          Status
+	exitCode        int
+	startedAt       time.Time
+	duration        time.Duration
+	output          string
+	outputTruncated bool
+}
+
+func NewRecord(
+	id, userID uuid.UUID,
+	language string,
+	files []SourceFile,
+	sourceTruncated bool,
+	status Status,
+	exitCode int,
+	startedAt time.Time,
+	duration time.Duration,
+	output string,
+	outputTruncated bool,
+) *Record {
+	return &Record{
+		id:              id,
+		userID:          userID,
+		language:        language,
+		files:           files,
+		sourceTruncated: sourceTruncated,
+		status:          status,
+		exitCode:        exitCode,
+		startedAt:       startedAt,
+		duration:        duration,
+		output:          output,
+		outputTruncated: outputTruncated,
+	}
+}
+
+func (r *Record) ID() uuid.UUID {
+	return r.id
+}
+
+func (r *Record) UserID() uuid.UUID {
+	return r.userID
+}
+
+func (r *Record) Language() string {
+	return r.language
+}
+
+func (r *Record) Files() []SourceFile {
+	files := make([]SourceFile, len(r.files))
+	copy(files, r.files)
+	return files
+}
+
+func (r *Record) SourceTruncated() bool {
+	return r.sourceTruncated
+}
+
+func (r *Record) Status() Status {
+	return r.status
+}
+
+// ExitCode is -1 if the program didn't exit by itself
+func (r *Record) ExitCode() int {
+	return r.exitCode
+}
+
+func (r *Record) StartedAt() time.Time {
+	return r.startedAt
+}
+
+func (r *Record) Duration() time.Duration {
+	return r.duration
+}
+
+func (r *Record) Output() string {
+	return r.output
+}
+
+func (r *Record) OutputTruncated() bool {
+	return r.outputTruncated
+}
@@ -0,0 +1,147 @@
+package xcutrexecution
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type finished struct {
+	exec      *Execution
+	expiresAt time.Time
+}
+
+// Registry keeps track of the running executions
+// and of the recently finished ones, for the retention.
+// After Close it doesn't accept new ones
+type Registry struct {
+	mu         sync.Mutex
+	closed     bool
+	retention  time.Duration
+	now        func() time.Time
+	executions map[uuid.UUID]*Execution
+	finished   map[uuid.UUID]finished
+	idle       chan struct{}
+	idleOnce   sync.Once
+}
+
+func NewRegistry(retention time.Duration) *Registry {
+	return &Registry{
+		retention:  retention,
+		now:        time.Now,
+		executions: make(map[uuid.UUID]*Execution),
+		finished:   make(map[uuid.UUID]finished),
+		idle:       make(chan struct{}),
+	}
+}
+
+func (r *Registry) Add(exec *Execution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return customerrors.ErrShuttingDown
+	}
+
+	r.executions[exec.ID()] = exec
+	return nil
+}
+
+// Remove moves the execution to the finished ones
+func (r *Registry) Remove(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for finishedID, f := range r.finished {
+		if now.After(f.expiresAt) {
+			delete(r.finished, finishedID)
+		}
+	}
+
+	if exec, ok := r.executions[id]; ok && r.retention > 0 {
+		r.finished[id] = finished{
+			exec:      exec,
+			expiresAt: now.Add(r.retention),
+		}
+	}
+
+	delete(r.executions, id)
+	r.notifyIdle()
+}
+
+// Get returns a running or a recently finished execution
+func (r *Registry) Get(id uuid.UUID) (*Execution, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if exec, ok := r.executions[id]; ok {
+		return exec, true
+	}
+
+	f, ok := r.finished[id]
+	if !ok || r.now().After(f.expiresAt) {
+		return nil, false
+	}
+
+	return f.exec, true
+}
+
+// List returns the running executions
+func (r *Registry) List() []*Execution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	executions := make([]*Execution, 0, len(r.executions))
+	for _, exec := range r.executions {
+		executions = append(executions, exec)
+	}
+
+	return executions
+}
+
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.executions)
+}
+
+// Close stops accepting new executions
+func (r *Registry) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	r.notifyIdle()
+}
+
+// Wait blocks until the registry is closed and all
+// the executions are removed, or until ctx is done
+func (r *Registry) Wait(ctx context.Context) error {
+	select {
+	case <-r.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CancelAll cancels every running execution with the cause
+func (r *Registry) CancelAll(cause error) {
+	for _, exec := range r.List() {
+		exec.Cancel(cause)
+	}
+}
+
+// must be called under the lock
+func (r *Registry) notifyIdle() {
+	if r.closed && len(r.executions) == 0 {
+		r.idleOnce.Do(func() {
+			close(r.idle)
+		})
+	}
+}
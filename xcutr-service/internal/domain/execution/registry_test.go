@@ -0,0 +1,167 @@
+package xcutrexecution
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+func newExecution(t *testing.T) *Execution {
+	t.Helper()
+
+	_, cancel := context.WithCancelCause(t.Context())
+	return New(uuid.New(), cancel, 10)
+}
+
+func TestRegistryClose(t *testing.T) {
+	testCases := []struct {
+		Name string
+		// Executions running on close n' removed after it
+		Running int
+		Removed int
+		WantErr error
+	}{
+		{Name: "empty", Running: 0, Removed: 0, WantErr: nil},
+		{Name: "all_removed", Running: 2, Removed: 2, WantErr: nil},
+		{Name: "still_running", Running: 2, Removed: 1, WantErr: context.DeadlineExceeded},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			registry := NewRegistry(time.Minute)
+
+			executions := make([]*Execution, 0, tc.Running)
+			for range tc.Running {
+				exec := newExecution(t)
+				if err := registry.Add(exec); err != nil {
+					t.Fatalf("failed to add execution: %v", err)
+				}
+				executions = append(executions, exec)
+			}
+
+			registry.Close()
+			if err := registry.Add(newExecution(t)); !errors.Is(err, customerrors.ErrShuttingDown) {
+				t.Errorf("got %v, want %v", err, customerrors.ErrShuttingDown)
+			}
+
+			for _, exec := range executions[:tc.Removed] {
+				registry.Remove(exec.ID())
+			}
+
+			ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+			defer cancel()
+
+			if err := registry.Wait(ctx); !errors.Is(err, tc.WantErr) {
+				t.Errorf("got %v, want %v", err, tc.WantErr)
+			}
+			if got, want := registry.Len(), tc.Running-tc.Removed; got != want {
+				t.Errorf("got %d running, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestRegistryDrain(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+
+	exec := newExecution(t)
+	if err := registry.Add(exec); err != nil {
+		t.Fatalf("failed to add execution: %v", err)
+	}
+
+	registry.Close()
+
+	waited := make(chan error, 1)
+	go func() {
+		waited <- registry.Wait(t.Context())
+	}()
+
+	select {
+	case err := <-waited:
+		t.Fatalf("wait returned %v while the execution runs", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	registry.Remove(exec.ID())
+
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("wait isn't done after the last execution is removed")
+	}
+}
+
+func TestRegistryRetention(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Retention time.Duration
+		Wait      time.Duration
+		WantFound bool
+	}{
+		{Name: "kept", Retention: time.Minute, Wait: 0, WantFound: true},
+		{Name: "almost_expired", Retention: time.Minute, Wait: time.Minute, WantFound: true},
+		{Name: "expired", Retention: time.Minute, Wait: time.Minute + time.Millisecond, WantFound: false},
+		{Name: "no_retention", Retention: 0, Wait: 0, WantFound: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			now := time.Now()
+			registry := NewRegistry(tc.Retention)
+			registry.now = func() time.Time { return now }
+
+			exec := newExecution(t)
+			if err := registry.Add(exec); err != nil {
+				t.Fatalf("failed to add execution: %v", err)
+			}
+			registry.Remove(exec.ID())
+			now = now.Add(tc.Wait)
+
+			if _, found := registry.Get(exec.ID()); found != tc.WantFound {
+				t.Errorf("got found %v, want %v", found, tc.WantFound)
+			}
+			if got := len(registry.List()); got != 0 {
+				t.Errorf("got %d running, want 0", got)
+			}
+
+			// The expired ones are dropped on the next remove
+			registry.Remove(uuid.New())
+			if _, kept := registry.finished[exec.ID()]; kept != tc.WantFound {
+				t.Errorf("got kept %v, want %v", kept, tc.WantFound)
+			}
+		})
+	}
+}
+
+func TestRegistryCancelAll(t *testing.T) {
+	registry := NewRegistry(time.Minute)
+
+	cause := errors.New("shutdown")
+	contexts := make([]context.Context, 0, 3)
+	for range 3 {
+		ctx, cancel := context.WithCancelCause(t.Context())
+		if err := registry.Add(New(uuid.New(), cancel, 10)); err != nil {
+			t.Fatalf("failed to add execution: %v", err)
+		}
+		contexts = append(contexts, ctx)
+	}
+
+	registry.CancelAll(cause)
+
+	for _, ctx := range contexts {
+		if err := context.Cause(ctx); !errors.Is(err, cause) {
+			t.Errorf("got %v, want %v", err, cause)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package xcutrexecution
+
+import "strings"
+
+// Transcript collects the beginning of the output up to the limit
+type Transcript struct {
+	limit     int
+	builder   strings.Builder
+	truncated bool
+	// Bytes of the whole output, including the dropped ones
+	size int
+}
+
+func NewTranscript(limit int) *Transcript {
+	return &Transcript{
+		limit: limit,
+	}
+}
+
+func (t *Transcript) WriteLine(line string) {
+	t.size += len(line) + 1
+	if t.truncated {
+		return
+	}
+
+	if t.builder.Len()+len(line)+1 > t.limit {
+		// The cut may split a rune, so its rest is dropped
+		t.builder.WriteString(strings.ToValidUTF8(line[:max(t.limit-t.builder.Len(), 0)], ""))
+		t.truncated = true
+		return
+	}
+
+	t.builder.WriteString(line)
+	t.builder.WriteByte('\n')
+}
+
+func (t *Transcript) String() string {
+	return t.builder.String()
+}
+
+func (t *Transcript) Truncated() bool {
+	return t.truncated
+}
+
+func (t *Transcript) Size() int {
+	return t.size
+}
@@ -0,0 +1,40 @@
+package xcutrlog
+
+import "context"
+
+// Subscribe fans the output out to a new subscriber, starting from the seq.
+// Every subscriber reads the ring on its own, so a slow one doesn't hold
+// the others back: it just skips the logs that dropped out of the ring.
+// The stream of the execution itself doesn't read it this way, it gets every log.
+// The channel is closed when the output is complete or ctx is done
+func (b *Buffer) Subscribe(ctx context.Context, from uint64) <-chan *Log {
+	logChan := make(chan *Log)
+
+	go func() {
+		defer close(logChan)
+
+		for {
+			logs, closed, changed := b.Since(from)
+			for _, log := range logs {
+				select {
+				case logChan <- log:
+				case <-ctx.Done():
+					return
+				}
+				from = log.Seq() + 1
+			}
+
+			if closed {
+				return
+			}
+
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return logChan
+}
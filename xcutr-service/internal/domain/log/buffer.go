@@ -0,0 +1,90 @@
+package xcutrlog
+
+import "sync"
+
+// Buffer is a ring of the last logs of an execution.
+// It numbers the logs, so readers can continue from any seq
+// that is still in the ring
+type Buffer struct {
+	mu      sync.Mutex
+	logs    []*Log
+	start   int
+	count   int
+	next    uint64
+	closed  bool
+	changed chan struct{}
+}
+
+func NewBuffer(size int) *Buffer {
+	return &Buffer{
+		logs:    make([]*Log, max(size, 1)),
+		next:    1,
+		changed: make(chan struct{}),
+	}
+}
+
+// Append numbers the log and puts it into the ring,
+// the oldest log is dropped if the ring is full
+func (b *Buffer) Append(log *Log) *Log {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	numbered := From(b.next, log.Msg(), log.Time())
+	b.next++
+
+	if b.count < len(b.logs) {
+		b.logs[(b.start+b.count)%len(b.logs)] = numbered
+		b.count++
+	} else {
+		b.logs[b.start] = numbered
+		b.start = (b.start + 1) % len(b.logs)
+	}
+
+	b.notify()
+	return numbered
+}
+
+// Close marks the output as complete
+func (b *Buffer) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	b.notify()
+}
+
+func (b *Buffer) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.closed
+}
+
+// Since returns the logs with seq >= from. If from is already out of the ring,
+// the logs start from the oldest one. closed reports that there will be no more logs,
+// changed is closed on the next append or close
+func (b *Buffer) Since(from uint64) (logs []*Log, closed bool, changed <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	oldest := b.next - uint64(b.count)
+	from = max(from, oldest)
+
+	if from < b.next {
+		logs = make([]*Log, 0, b.next-from)
+		for i := int(from - oldest); i < b.count; i++ {
+			logs = append(logs, b.logs[(b.start+i)%len(b.logs)])
+		}
+	}
+
+	return logs, b.closed, b.changed
+}
+
+// must be called under the lock
+func (b *Buffer) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
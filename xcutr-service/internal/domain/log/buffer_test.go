@@ -0,0 +1,181 @@
+package xcutrlog
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func seqs(logs []*Log) []uint64 {
+	result := make([]uint64, 0, len(logs))
+	for _, log := range logs {
+		result = append(result, log.Seq())
+	}
+
+	return result
+}
+
+func fill(size, appended int) *Buffer {
+	buffer := NewBuffer(size)
+	for i := range appended {
+		buffer.Append(NewLog(fmt.Sprint(i), time.Now()))
+	}
+
+	return buffer
+}
+
+func TestBufferSince(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Size     int
+		Appended int
+		From     uint64
+		Want     []uint64
+	}{
+		{Name: "empty", Size: 3, Appended: 0, From: 1, Want: []uint64{}},
+		{Name: "from_start", Size: 3, Appended: 2, From: 1, Want: []uint64{1, 2}},
+		{Name: "from_zero", Size: 3, Appended: 2, From: 0, Want: []uint64{1, 2}},
+		{Name: "from_middle", Size: 3, Appended: 3, From: 2, Want: []uint64{2, 3}},
+		{Name: "from_next", Size: 3, Appended: 3, From: 4, Want: []uint64{}},
+		{Name: "from_future", Size: 3, Appended: 3, From: 10, Want: []uint64{}},
+		{Name: "wrapped", Size: 3, Appended: 5, From: 1, Want: []uint64{3, 4, 5}},
+		{Name: "wrapped_from_ring", Size: 3, Appended: 7, From: 6, Want: []uint64{6, 7}},
+		{Name: "zero_size", Size: 0, Appended: 2, From: 1, Want: []uint64{2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			logs, closed, _ := fill(tc.Size, tc.Appended).Since(tc.From)
+			if got := seqs(logs); !slices.Equal(got, tc.Want) {
+				t.Errorf("got %v, want %v", got, tc.Want)
+			}
+			if closed {
+				t.Errorf("got closed buffer, want open")
+			}
+		})
+	}
+}
+
+func TestBufferChanged(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Change func(b *Buffer)
+		Closed bool
+	}{
+		{Name: "append", Change: func(b *Buffer) { b.Append(NewLog("log", time.Now())) }, Closed: false},
+		{Name: "close", Change: func(b *Buffer) { b.Close() }, Closed: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			buffer := NewBuffer(3)
+			_, _, changed := buffer.Since(1)
+
+			tc.Change(buffer)
+
+			select {
+			case <-changed:
+			default:
+				t.Fatalf("changed isn't closed")
+			}
+			if _, closed, _ := buffer.Since(1); closed != tc.Closed {
+				t.Errorf("got closed %v, want %v", closed, tc.Closed)
+			}
+		})
+	}
+}
+
+func TestBufferSubscribe(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Size int
+		// Logs appended before n' after the subscription
+		Before int
+		After  int
+		From   uint64
+		Want   []uint64
+	}{
+		{Name: "all", Size: 10, Before: 2, After: 2, From: 1, Want: []uint64{1, 2, 3, 4}},
+		{Name: "from_middle", Size: 10, Before: 3, After: 1, From: 3, Want: []uint64{3, 4}},
+		{Name: "only_new", Size: 10, Before: 2, After: 2, From: 3, Want: []uint64{3, 4}},
+		// The logs out of the ring are skipped
+		{Name: "overflow", Size: 2, Before: 5, After: 0, From: 1, Want: []uint64{4, 5}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			buffer := fill(tc.Size, tc.Before)
+			logs := buffer.Subscribe(t.Context(), tc.From)
+			for range tc.After {
+				buffer.Append(NewLog("log", time.Now()))
+			}
+			buffer.Close()
+
+			got := []uint64{}
+			for log := range logs {
+				got = append(got, log.Seq())
+			}
+			if !slices.Equal(got, tc.Want) {
+				t.Errorf("got %v, want %v", got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestBufferSubscribeSlow(t *testing.T) {
+	buffer := NewBuffer(2)
+	logs := buffer.Subscribe(t.Context(), 1)
+
+	buffer.Append(NewLog("log", time.Now()))
+	first := <-logs
+
+	// The subscriber doesn't read while the ring overflows
+	for range 5 {
+		buffer.Append(NewLog("log", time.Now()))
+	}
+	buffer.Close()
+
+	got := []uint64{first.Seq()}
+	for log := range logs {
+		got = append(got, log.Seq())
+	}
+
+	// The logs taken before the overflow may still be delivered,
+	// the rest is skipped up to the ring. The order is kept
+	for i := 1; i < len(got); i++ {
+		if got[i] <= got[i-1] {
+			t.Fatalf("got %v, want increasing seqs", got)
+		}
+	}
+	if tail := got[len(got)-2:]; !slices.Equal(tail, []uint64{5, 6}) {
+		t.Errorf("got tail %v, want %v", tail, []uint64{5, 6})
+	}
+	if len(got) == 6 {
+		t.Errorf("got %v, want the overflowed logs skipped", got)
+	}
+}
+
+func TestBufferSubscribeCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+
+	buffer := fill(3, 1)
+	logs := buffer.Subscribe(ctx, 2)
+	cancel()
+
+	select {
+	case _, ok := <-logs:
+		if ok {
+			t.Errorf("got log, want closed channel")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("channel isn't closed after cancel")
+	}
+}
@@ -1,15 +1,39 @@
 package xcutrlog
 
+import "time"
+
 type Log struct {
-	msg string
+	seq  uint64
+	msg  string
+	time time.Time
+}
+
+func NewLog(msg string, time time.Time) *Log {
+	return &Log{
+		msg:  msg,
+		time: time,
+	}
 }
 
-func NewLog(msg string) *Log {
+func From(seq uint64, msg string, time time.Time) *Log {
 	return &Log{
-		msg: msg,
+		seq:  seq,
+		msg:  msg,
+		time: time,
 	}
 }
 
+// Seq is a number of the log in the execution's output, starting from 1.
+// It's zero until the log is put into a buffer
+func (l *Log) Seq() uint64 {
+	return l.seq
+}
+
 func (l *Log) Msg() string {
 	return l.msg
 }
+
+// Time is when the container wrote the log
+func (l *Log) Time() time.Time {
+	return l.time
+}
@@ -4,5 +4,7 @@ import "context"
 
 type ClickhouseClient interface {
 	Up() error
-	WriteSession(ctx context.Context, userID, language string) error
+	WriteEvents(ctx context.Context, events []*ExecutionEvent) error
+	UsageStats(ctx context.Context, filter StatsFilter) (*UsageStats, error)
+	DeleteUser(ctx context.Context, userID string) error
 }
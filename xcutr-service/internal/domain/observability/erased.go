@@ -0,0 +1,48 @@
+package observability
+
+import (
+	"sync"
+	"time"
+)
+
+// How long the data of an erased user is dropped,
+// it covers the data that was on the way during the erasure
+const erasedTTL = time.Hour
+
+// ErasedUsers remembers the recently erased users, so the events
+// n' records of their executions that end after the erasure aren't written back
+type ErasedUsers struct {
+	mu    sync.Mutex
+	users map[string]time.Time
+}
+
+func NewErasedUsers() *ErasedUsers {
+	return &ErasedUsers{
+		users: make(map[string]time.Time),
+	}
+}
+
+// Erase drops the data of the user from now on
+func (e *ErasedUsers) Erase(userID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.users[userID] = time.Now()
+}
+
+// Erased reports whether the data of the user since the time is erased
+func (e *ErasedUsers) Erased(userID string, since time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	erasedAt, ok := e.users[userID]
+	if !ok {
+		return false
+	}
+	if time.Since(erasedAt) > erasedTTL {
+		delete(e.users, userID)
+		return false
+	}
+
+	return !since.After(erasedAt)
+}
@@ -0,0 +1,44 @@
+package observability
+
+import (
+	"testing"
+	"time"
+)
+
+func TestErasedUsers(t *testing.T) {
+	erased := NewErasedUsers()
+	erased.Erase("erased")
+
+	testCases := []struct {
+		Name   string
+		UserID string
+		Since  time.Time
+		Want   bool
+	}{
+		{Name: "before_erasure", UserID: "erased", Since: time.Now().Add(-time.Minute), Want: true},
+		{Name: "after_erasure", UserID: "erased", Since: time.Now().Add(time.Minute), Want: false},
+		{Name: "other_user", UserID: "other", Since: time.Now().Add(-time.Minute), Want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := erased.Erased(tc.UserID, tc.Since); got != tc.Want {
+				t.Errorf("got %v, want %v", got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestErasedUsersExpire(t *testing.T) {
+	erased := NewErasedUsers()
+	erased.users["erased"] = time.Now().Add(-erasedTTL - time.Second)
+
+	if erased.Erased("erased", time.Now().Add(-2*erasedTTL)) {
+		t.Errorf("got erased, want the erasure expired")
+	}
+	if _, ok := erased.users["erased"]; ok {
+		t.Errorf("expired erasure is kept")
+	}
+}
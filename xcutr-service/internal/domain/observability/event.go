@@ -0,0 +1,25 @@
+package observability
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExecutionEvent is one run of the executor
+type ExecutionEvent struct {
+	Timestamp   time.Time
+	ExecutionID uuid.UUID
+	UserID      string
+	Language    string
+	Duration    time.Duration
+	Status      string
+	// -1 if the program hasn't exited by itself
+	ExitCode    int
+	TimedOut    bool
+	OOMKilled   bool
+	OutputBytes uint64
+	Image       string
+	// Instance of the executor that ran the code
+	Executor string
+}
@@ -0,0 +1,15 @@
+package observability
+
+import "context"
+
+// ExecutionEventSink records the events of the executions,
+// the sinks are chosen in the config
+type ExecutionEventSink interface {
+	// Write doesn't hold the execution, failures are only logged
+	Write(event *ExecutionEvent)
+	// EraseUser removes the recorded events of the user,
+	// including the ones waiting to be written
+	EraseUser(ctx context.Context, userID string) error
+	// Close flushes the collected events until ctx is done
+	Close(ctx context.Context) error
+}
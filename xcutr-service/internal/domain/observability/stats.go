@@ -0,0 +1,40 @@
+package observability
+
+import "time"
+
+type StatsFilter struct {
+	From time.Time
+	To   time.Time
+	// Empty matches every language
+	Language string
+	TopUsers int
+}
+
+type DailyRuns struct {
+	Day      time.Time
+	Language string
+	Runs     uint64
+}
+
+type LanguageStats struct {
+	Language    string
+	Runs        uint64
+	Failures    uint64
+	FailureRate float64
+	Timeouts    uint64
+	OOMKills    uint64
+	P50Duration time.Duration
+	P95Duration time.Duration
+}
+
+type UserUsage struct {
+	UserID   string
+	Runs     uint64
+	Duration time.Duration
+}
+
+type UsageStats struct {
+	Daily     []DailyRuns
+	Languages []LanguageStats
+	TopUsers  []UserUsage
+}
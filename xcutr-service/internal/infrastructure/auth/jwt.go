@@ -1,19 +1,34 @@
 package jwt
 
 import (
-	"crypto/rsa"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"time"
 
+	"github.com/devathh/coderun/shared/jwks"
 	"github.com/devathh/coderun/xcutr-service/internal/domain/auth"
 	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
 	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	// maxJWKSSize limits the body of the jwks document
+	maxJWKSSize  = 1 << 20
+	fetchTimeout = 5 * time.Second
+)
+
 type JWTManager struct {
-	cfg    *config.Config
-	public *rsa.PublicKey
+	cfg *config.Config
+	// Nil if the jwks isn't set, the public key checks every token then
+	keys   *jwks.KeySet
+	public crypto.PublicKey
 }
 
 func New(cfg *config.Config) (*JWTManager, error) {
@@ -21,6 +36,16 @@ func New(cfg *config.Config) (*JWTManager, error) {
 		return nil, customerrors.ErrNilArgs
 	}
 
+	if cfg.Secrets.JWT.JWKSURL != "" {
+		client := &http.Client{Timeout: fetchTimeout}
+		return &JWTManager{
+			cfg: cfg,
+			keys: jwks.NewKeySet(func(ctx context.Context) ([]jwks.JWK, error) {
+				return fetchJWKS(ctx, client, cfg.Secrets.JWT.JWKSURL)
+			}, cfg.Secrets.JWT.KeysRefresh),
+		}, nil
+	}
+
 	public, err := loadPublic(cfg)
 	if err != nil {
 		return nil, err
@@ -38,11 +63,27 @@ func (jm *JWTManager) Validate(tokenString string) (*auth.CoderunClaims, error)
 	}
 
 	token, err := jwt.ParseWithClaims(tokenString, &auth.CoderunClaims{}, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+		key := jm.public
+		if jm.keys != nil {
+			// The tokens signed before the kids were set have none
+			kid, _ := t.Header["kid"].(string)
+
+			var err error
+			key, err = jm.keys.Get(kid)
+			if errors.Is(err, jwks.ErrUnknownKey) {
+				return nil, customerrors.ErrInvalidToken
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// The alg of the token must be the one of its key
+		if t.Method.Alg() != jwks.MethodOf(key).Alg() {
 			return nil, customerrors.ErrInvalidToken
 		}
 
-		return jm.public, nil
+		return key, nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -55,13 +96,44 @@ func (jm *JWTManager) Validate(tokenString string) (*auth.CoderunClaims, error)
 	return nil, customerrors.ErrInvalidToken
 }
 
-func loadPublic(cfg *config.Config) (*rsa.PublicKey, error) {
+func fetchJWKS(ctx context.Context, client *http.Client, url string) ([]jwks.JWK, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jwks.JWK `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSSize)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	return set.Keys, nil
+}
+
+// loadPublic reads the rsa or ed25519 key
+func loadPublic(cfg *config.Config) (crypto.PublicKey, error) {
 	bytesKey, err := os.ReadFile(cfg.Secrets.JWT.PublicKeyPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read public key: %w", err)
 	}
 
-	key, err := jwt.ParseRSAPublicKeyFromPEM(bytesKey)
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(bytesKey); err == nil {
+		return key, nil
+	}
+
+	key, err := jwt.ParseEdPublicKeyFromPEM(bytesKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse public key: %w", err)
 	}
@@ -0,0 +1,30 @@
+package denylistredis
+
+import (
+	"context"
+
+	"github.com/devathh/coderun/shared/denylist"
+	"github.com/devathh/coderun/xcutr-service/internal/domain/auth"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// Denylist of the sessions revoked by sso, it's only read here
+type Denylist struct {
+	checker *denylist.Checker
+}
+
+func New(cfg *config.Config, client *redis.Client) (*Denylist, error) {
+	if cfg == nil || client == nil {
+		return nil, customerrors.ErrNilArgs
+	}
+
+	return &Denylist{
+		checker: denylist.NewChecker(client, cfg.Denylist.CacheTTL),
+	}, nil
+}
+
+func (d *Denylist) IsRevoked(ctx context.Context, claims *auth.CoderunClaims) (bool, error) {
+	return d.checker.IsRevoked(ctx, claims.SessionID)
+}
@@ -8,24 +8,36 @@ import (
 	"time"
 
 	"github.com/goccy/go-yaml"
+	"github.com/google/uuid"
 )
 
 type app struct {
 	Env     string `yaml:"env"`
 	Version string `yaml:"version"`
 	Name    string `yaml:"name"`
+	// Name of this executor in the analytics, the hostname by default
+	Instance string `yaml:"instance"`
 }
 
 func (a *app) validate() error {
 	if a.Env == "" {
 		a.Env = "dev"
 	}
+	if a.Instance == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("failed to get hostname: %w", err)
+		}
+		a.Instance = hostname
+	}
 
 	return nil
 }
 
 type features struct {
 	ClickhouseEnable bool `yaml:"clickhouse-enable"`
+	// Execute is refused to the users who haven't verified their emails in sso
+	RequireVerifiedEmail bool `yaml:"require-verified-email"`
 }
 
 type server struct {
@@ -37,6 +49,18 @@ type server struct {
 			Enable bool `yaml:"enable"`
 		} `yaml:"tls"`
 	} `yaml:"grpc"`
+	// grpc.health.v1 checks of the dependencies
+	Health struct {
+		Interval time.Duration `yaml:"interval"`
+		// Time of one check of every dependency
+		Timeout time.Duration `yaml:"timeout"`
+	} `yaml:"health"`
+	// Prometheus metrics on /metrics, disabled by default
+	Metrics struct {
+		Enable bool   `yaml:"enable"`
+		Host   string `yaml:"host"`
+		Port   string `yaml:"port"`
+	} `yaml:"metrics"`
 }
 
 func (s *server) validate() error {
@@ -49,6 +73,182 @@ func (s *server) validate() error {
 	if s.GRPC.Protocol == "" {
 		s.GRPC.Protocol = "tcp"
 	}
+	if s.Health.Interval <= 0 {
+		s.Health.Interval = 10 * time.Second
+	}
+	if s.Health.Timeout <= 0 {
+		s.Health.Timeout = 3 * time.Second
+	}
+	if s.Health.Timeout > s.Health.Interval {
+		return errors.New("health timeout is larger than interval")
+	}
+	if s.Metrics.Host == "" {
+		s.Metrics.Host = "localhost"
+	}
+	if s.Metrics.Port == "" {
+		s.Metrics.Port = "9092"
+	}
+
+	return nil
+}
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+type tracing struct {
+	Enable bool `yaml:"enable"`
+	// otlp (over grpc) or stdout for local use
+	Exporter string `yaml:"exporter"`
+	// host:port of the otlp collector
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"`
+	// Share of the traces started here that are recorded,
+	// the ones continued from the caller follow its decision
+	SampleRatio float64 `yaml:"sample-ratio"`
+}
+
+func (t *tracing) validate() error {
+	if !t.Enable {
+		return nil
+	}
+
+	if t.Exporter == "" {
+		t.Exporter = ExporterOTLP
+	}
+	if t.Exporter != ExporterOTLP && t.Exporter != ExporterStdout {
+		return fmt.Errorf("invalid exporter: %s", t.Exporter)
+	}
+	if t.Exporter == ExporterOTLP && t.Endpoint == "" {
+		t.Endpoint = "localhost:4317"
+	}
+	if t.SampleRatio <= 0 {
+		t.SampleRatio = 1
+	}
+	if t.SampleRatio > 1 {
+		return errors.New("sample ratio is greater than 1")
+	}
+
+	return nil
+}
+
+const (
+	RateLimitByIP   = "ip"
+	RateLimitByUser = "user"
+)
+
+type RateLimitRule struct {
+	// Full name of the grpc method, like /xcutr.v1.Xcutr/Execute
+	Method string `yaml:"method"`
+	// user or ip. The calls without a user are limited by ip
+	Key string `yaml:"key"`
+	// Calls allowed per period on average
+	Rate   int           `yaml:"rate"`
+	Period time.Duration `yaml:"period"`
+	// Calls allowed at once, the rate by default
+	Burst int `yaml:"burst"`
+}
+
+func (r *RateLimitRule) validate() error {
+	if r.Method == "" {
+		return errors.New("invalid method")
+	}
+	if r.Key == "" {
+		r.Key = RateLimitByUser
+	}
+	if r.Key != RateLimitByIP && r.Key != RateLimitByUser {
+		return fmt.Errorf("invalid key: %s", r.Key)
+	}
+	if r.Rate <= 0 {
+		return errors.New("invalid rate")
+	}
+	if r.Period <= 0 {
+		r.Period = time.Minute
+	}
+	if r.Burst <= 0 {
+		r.Burst = r.Rate
+	}
+
+	return nil
+}
+
+// Token buckets in redis, so the limits hold across the replicas.
+// The calls are let through while redis is unavailable
+type rateLimit struct {
+	Enable bool `yaml:"enable"`
+	// The ip of the client is taken from x-forwarded-for set by the gateway.
+	// Only for the services that can't be reached bypassing it
+	TrustForwarded bool            `yaml:"trust-forwarded"`
+	Rules          []RateLimitRule `yaml:"rules"`
+}
+
+func (r *rateLimit) validate() error {
+	if !r.Enable {
+		return nil
+	}
+
+	methods := make(map[string]bool, len(r.Rules))
+	for i := range r.Rules {
+		if err := r.Rules[i].validate(); err != nil {
+			return fmt.Errorf("invalid rule #%d: %w", i, err)
+		}
+		if methods[r.Rules[i].Method] {
+			return fmt.Errorf("duplicate rule: %s", r.Rules[i].Method)
+		}
+		methods[r.Rules[i].Method] = true
+	}
+
+	return nil
+}
+
+const (
+	EngineDocker = "docker"
+	EnginePodman = "podman"
+)
+
+type DockerHost struct {
+	Name string `yaml:"name"`
+	// unix:///var/run/docker.sock or tcp://host:2376.
+	// For podman it may be empty, then the socket is discovered
+	Host string `yaml:"host"`
+	// docker or podman (through its docker-compatible API)
+	Engine   string `yaml:"engine"`
+	Rootless bool   `yaml:"rootless"`
+	TLS      struct {
+		CAPath   string `yaml:"ca-path"`
+		CertPath string `yaml:"cert-path"`
+		KeyPath  string `yaml:"key-path"`
+	} `yaml:"tls"`
+}
+
+func (h *DockerHost) validate() error {
+	if h.Engine == "" {
+		h.Engine = EngineDocker
+	}
+	if h.Engine != EngineDocker && h.Engine != EnginePodman {
+		return fmt.Errorf("invalid engine: %s", h.Engine)
+	}
+	if h.Host == "" && h.Engine != EnginePodman {
+		return errors.New("invalid host")
+	}
+	if h.Name == "" {
+		h.Name = h.Host
+	}
+	if h.Name == "" {
+		h.Name = h.Engine
+	}
+
+	// Certs must be set all together or not at all
+	certs := 0
+	for _, path := range []string{h.TLS.CAPath, h.TLS.CertPath, h.TLS.KeyPath} {
+		if path != "" {
+			certs++
+		}
+	}
+	if certs != 0 && certs != 3 {
+		return errors.New("tls requires ca, cert and key")
+	}
 
 	return nil
 }
@@ -56,6 +256,12 @@ func (s *server) validate() error {
 type docker struct {
 	ImageGo     string `yaml:"image-go"`
 	ImagePython string `yaml:"image-python"`
+	// If hosts are empty, the docker client is created from the environment
+	Hosts          []DockerHost  `yaml:"hosts"`
+	HealthInterval time.Duration `yaml:"health-interval"`
+	// Candidates for podman hosts without host, the first existing socket is used.
+	// If empty, the default rootless or rootful locations are checked
+	PodmanSockets []string `yaml:"podman-sockets"`
 }
 
 func (d *docker) validate() error {
@@ -65,29 +271,254 @@ func (d *docker) validate() error {
 	if d.ImagePython == "" {
 		return errors.New("invalid python image")
 	}
+	if d.HealthInterval <= 0 {
+		d.HealthInterval = 10 * time.Second
+	}
+
+	names := make(map[string]bool, len(d.Hosts))
+	for i := range d.Hosts {
+		if err := d.Hosts[i].validate(); err != nil {
+			return fmt.Errorf("invalid docker host #%d: %w", i, err)
+		}
+		if names[d.Hosts[i].Name] {
+			return fmt.Errorf("duplicate docker host: %s", d.Hosts[i].Name)
+		}
+		names[d.Hosts[i].Name] = true
+	}
 
 	return nil
 }
 
 type log struct {
 	BufSize int `yaml:"buf-size"`
+	// Lines of the output kept for reconnecting clients
+	RingSize int `yaml:"ring-size"`
+	// How long an execution keeps running without viewers,
+	// zero stops it as soon as the client is gone
+	DetachTimeout time.Duration `yaml:"detach-timeout"`
+	// How long the output of a finished execution is available
+	Retention time.Duration `yaml:"retention"`
+	// Viewers of one execution at once, including the owner
+	MaxViewers int `yaml:"max-viewers"`
 }
 
 func (l *log) validate() error {
 	if l.BufSize < 1 {
 		return errors.New("too little buf size")
 	}
+	if l.RingSize < 1 {
+		l.RingSize = 1000
+	}
+	if l.DetachTimeout < 0 {
+		return errors.New("invalid detach timeout")
+	}
+	if l.Retention < 0 {
+		return errors.New("invalid retention")
+	}
+	if l.MaxViewers < 1 {
+		l.MaxViewers = 50
+	}
+
+	return nil
+}
+
+type history struct {
+	// Bytes of the output kept in the history of an execution
+	OutputLimit int `yaml:"output-limit"`
+	// Bytes of the source files kept, larger sources are stored as hashes only
+	SourceLimit int `yaml:"source-limit"`
+	// Max records on one page
+	PageLimit int `yaml:"page-limit"`
+	// How long the records are kept
+	Retention time.Duration `yaml:"retention"`
+}
+
+func (h *history) validate() error {
+	if h.OutputLimit <= 0 {
+		h.OutputLimit = 64 << 10
+	}
+	if h.SourceLimit <= 0 {
+		h.SourceLimit = 1 << 20
+	}
+	if h.PageLimit <= 0 {
+		h.PageLimit = 100
+	}
+	if h.Retention <= 0 {
+		h.Retention = 30 * 24 * time.Hour
+	}
+	if h.Retention < time.Hour {
+		return errors.New("retention is less than an hour")
+	}
+
+	return nil
+}
+
+const (
+	SinkClickhouse = "clickhouse"
+	SinkJSONL      = "jsonl"
+)
+
+type clickhouseSink struct {
+	// Events waiting to be written, the new ones are dropped above it
+	BufferSize    int           `yaml:"buffer-size"`
+	BatchSize     int           `yaml:"batch-size"`
+	FlushInterval time.Duration `yaml:"flush-interval"`
+	MaxRetries    int           `yaml:"max-retries"`
+	// Backoff before the first retry, doubled for every next one
+	RetryBackoff time.Duration `yaml:"retry-backoff"`
+	// Events that failed all the retries are saved there
+	// n' written when clickhouse is back. Empty drops them
+	SpillPath string `yaml:"spill-path"`
+	// Bytes of the spill file, the oldest events are dropped above it
+	SpillMaxSize int64 `yaml:"spill-max-size"`
+}
+
+func (c *clickhouseSink) validate() error {
+	if c.BufferSize <= 0 {
+		c.BufferSize = 10000
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 500
+	}
+	if c.BatchSize > c.BufferSize {
+		return errors.New("batch size is larger than buffer size")
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = 5 * time.Second
+	}
+	if c.MaxRetries < 0 {
+		return errors.New("invalid max retries")
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 500 * time.Millisecond
+	}
+	if c.SpillMaxSize < 0 {
+		return errors.New("invalid spill max size")
+	}
+	if c.SpillMaxSize == 0 {
+		c.SpillMaxSize = 64 << 20
+	}
+
+	return nil
+}
+
+type jsonlSink struct {
+	Path string `yaml:"path"`
+	// Bytes of the file before it's rotated
+	MaxSize int64 `yaml:"max-size"`
+	// Rotated files kept as path.1, path.2...
+	MaxBackups int `yaml:"max-backups"`
+}
+
+func (j *jsonlSink) validate() error {
+	if j.Path == "" {
+		return errors.New("invalid path")
+	}
+	if j.MaxSize <= 0 {
+		j.MaxSize = 100 << 20
+	}
+	if j.MaxBackups < 0 {
+		return errors.New("invalid max backups")
+	}
+
+	return nil
+}
+
+type analytics struct {
+	// Every event is written to all the sinks: clickhouse, jsonl.
+	// Empty means clickhouse if it's enabled, otherwise none
+	Sinks []string `yaml:"sinks"`
+	// How long the shutdown waits for the events to be written
+	FlushTimeout time.Duration `yaml:"flush-timeout"`
+	// How long the events are kept in clickhouse
+	Retention  time.Duration  `yaml:"retention"`
+	Clickhouse clickhouseSink `yaml:"clickhouse"`
+	JSONL      jsonlSink      `yaml:"jsonl"`
+}
+
+func (a *analytics) validate(clickhouseEnable bool) error {
+	if a.Sinks == nil && clickhouseEnable {
+		a.Sinks = []string{SinkClickhouse}
+	}
+	if a.FlushTimeout <= 0 {
+		a.FlushTimeout = 10 * time.Second
+	}
+	if a.Retention <= 0 {
+		a.Retention = 30 * 24 * time.Hour
+	}
+	if a.Retention < time.Hour {
+		return errors.New("retention is less than an hour")
+	}
+
+	seen := make(map[string]bool, len(a.Sinks))
+	for _, sink := range a.Sinks {
+		if seen[sink] {
+			return fmt.Errorf("duplicate sink: %s", sink)
+		}
+		seen[sink] = true
+
+		switch sink {
+		case SinkClickhouse:
+			if !clickhouseEnable {
+				return errors.New("clickhouse sink requires clickhouse to be enabled")
+			}
+			if err := a.Clickhouse.validate(); err != nil {
+				return fmt.Errorf("invalid clickhouse sink: %w", err)
+			}
+		case SinkJSONL:
+			if err := a.JSONL.validate(); err != nil {
+				return fmt.Errorf("invalid jsonl sink: %w", err)
+			}
+		default:
+			return fmt.Errorf("unknown sink: %s", sink)
+		}
+	}
+
+	return nil
+}
+
+type service struct {
+	MaxTimeout   time.Duration `yaml:"max-timeout"`
+	DrainTimeout time.Duration `yaml:"drain-timeout"`
+	Log          log           `yaml:"log"`
+	History      history       `yaml:"history"`
+	Analytics    analytics     `yaml:"analytics"`
+	// Ids of the users allowed to call the admin methods
+	Admins []string `yaml:"admins"`
+}
+
+func (s *service) validate() error {
+	if s.DrainTimeout <= 0 {
+		s.DrainTimeout = 30 * time.Second
+	}
+	for _, admin := range s.Admins {
+		if _, err := uuid.Parse(admin); err != nil {
+			return fmt.Errorf("invalid admin id %q: %w", admin, err)
+		}
+	}
 
 	return nil
 }
 
 type jwt struct {
+	// The keys of sso, like http://localhost:9090/.well-known/jwks.json.
+	// They are refetched every keys-refresh (5m by default) n' when a token
+	// has an unknown kid, so the keys of sso are rotated without restarts
+	JWKSURL     string        `yaml:"jwks-url"`
+	KeysRefresh time.Duration `yaml:"keys-refresh"`
+	// The single key of sso, it's used if the jwks isn't set
 	PublicKeyPath string `yaml:"public-key-path"`
 }
 
 func (j *jwt) validate() error {
-	if j.PublicKeyPath == "" {
-		return errors.New("invalid path to public key")
+	if j.JWKSURL == "" && j.PublicKeyPath == "" {
+		return errors.New("invalid jwks url or path to public key")
+	}
+	if j.KeysRefresh == 0 {
+		j.KeysRefresh = 5 * time.Minute
+	}
+	if j.KeysRefresh < time.Second {
+		return errors.New("too little keys refresh")
 	}
 
 	return nil
@@ -121,19 +552,61 @@ func (ch *clickhouse) validate() error {
 	return nil
 }
 
+type redis struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Password string `yaml:"password"`
+}
+
+func (r *redis) validate() error {
+	if r.Host == "" {
+		r.Host = "localhost"
+	}
+	if r.Port == "" {
+		r.Port = "6379"
+	}
+
+	return nil
+}
+
+// Sessions revoked by sso, their access tokens are refused before the expiry
+type denylist struct {
+	Enable bool `yaml:"enable"`
+	// How long the answers of redis are kept in memory,
+	// a revocation reaches the service with this delay
+	CacheTTL time.Duration `yaml:"cache-ttl"`
+}
+
+func (d *denylist) validate() error {
+	if !d.Enable {
+		return nil
+	}
+
+	if d.CacheTTL < 0 {
+		return errors.New("invalid cache ttl")
+	}
+	if d.CacheTTL == 0 {
+		d.CacheTTL = 5 * time.Second
+	}
+
+	return nil
+}
+
 type Config struct {
-	App      app      `yaml:"app"`
-	Features features `yaml:"features"`
-	Server   server   `yaml:"server"`
-	Secrets  struct {
+	App       app       `yaml:"app"`
+	Features  features  `yaml:"features"`
+	Server    server    `yaml:"server"`
+	Tracing   tracing   `yaml:"tracing"`
+	RateLimit rateLimit `yaml:"rate-limit"`
+	Denylist  denylist  `yaml:"denylist"`
+	Secrets   struct {
 		Docker     docker     `yaml:"docker"`
 		JWT        jwt        `yaml:"jwt"`
 		Clickhouse clickhouse `yaml:"clickhouse"`
+		// Used by the rate limits n' the denylist only
+		Redis redis `yaml:"redis"`
 	} `yaml:"secrets"`
-	Service struct {
-		MaxTimeout time.Duration `yaml:"max-timeout"`
-		Log        log           `yaml:"log"`
-	} `yaml:"service"`
+	Service service `yaml:"service"`
 }
 
 func New(path string) (*Config, error) {
@@ -172,18 +645,39 @@ func (c *Config) Validate() error {
 	if err := c.Server.validate(); err != nil {
 		return fmt.Errorf("invalid server: %w", err)
 	}
+	if err := c.Tracing.validate(); err != nil {
+		return fmt.Errorf("invalid tracing: %w", err)
+	}
+	if err := c.RateLimit.validate(); err != nil {
+		return fmt.Errorf("invalid rate limit: %w", err)
+	}
+	if err := c.Denylist.validate(); err != nil {
+		return fmt.Errorf("invalid denylist: %w", err)
+	}
 	if err := c.Secrets.Docker.validate(); err != nil {
 		return fmt.Errorf("invalid docker: %w", err)
 	}
+	if err := c.Service.validate(); err != nil {
+		return fmt.Errorf("invalid service: %w", err)
+	}
 	if err := c.Service.Log.validate(); err != nil {
 		return fmt.Errorf("invalid log: %w", err)
 	}
+	if err := c.Service.History.validate(); err != nil {
+		return fmt.Errorf("invalid history: %w", err)
+	}
+	if err := c.Service.Analytics.validate(c.Features.ClickhouseEnable); err != nil {
+		return fmt.Errorf("invalid analytics: %w", err)
+	}
 	if err := c.Secrets.JWT.validate(); err != nil {
 		return fmt.Errorf("invalid jwt: %w", err)
 	}
 	if err := c.Secrets.Clickhouse.validate(); err != nil {
 		return fmt.Errorf("invalid clickhouse: %w", err)
 	}
+	if err := c.Secrets.Redis.validate(); err != nil {
+		return fmt.Errorf("invalid redis: %w", err)
+	}
 
 	return nil
 }
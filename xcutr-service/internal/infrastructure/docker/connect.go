@@ -3,10 +3,13 @@ package docker
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
 	"github.com/docker/docker/client"
 )
 
+// Connect creates a client from the environment
 func Connect() (*client.Client, error) {
 	cli, err := client.NewClientWithOpts(client.FromEnv)
 	if err != nil {
@@ -19,3 +22,38 @@ func Connect() (*client.Client, error) {
 
 	return cli, nil
 }
+
+// ConnectHost creates a client for the configured endpoint.
+// It doesn't ping the daemon, the pool does it
+func ConnectHost(host config.DockerHost, podmanSockets []string) (*client.Client, error) {
+	addr := host.Host
+	if addr == "" && host.Engine == config.EnginePodman {
+		socket, err := podmanSocket(host.Rootless, podmanSockets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover podman socket for %s: %w", host.Name, err)
+		}
+		addr = socket
+	}
+	if host.Rootless && !strings.HasPrefix(addr, "unix://") {
+		return nil, fmt.Errorf("invalid host %s: %w", host.Name, errRootlessOverTCP)
+	}
+
+	opts := []client.Opt{
+		client.WithHost(addr),
+		client.WithAPIVersionNegotiation(),
+	}
+	if host.TLS.CAPath != "" {
+		opts = append(opts, client.WithTLSClientConfig(
+			host.TLS.CAPath,
+			host.TLS.CertPath,
+			host.TLS.KeyPath,
+		))
+	}
+
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker client for %s: %w", host.Name, err)
+	}
+
+	return cli, nil
+}
@@ -10,18 +10,28 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/containerd/errdefs"
+	"github.com/devathh/coderun/shared/tracing"
 	xcutrcontainer "github.com/devathh/coderun/xcutr-service/internal/domain/container"
 	xcutrlog "github.com/devathh/coderun/xcutr-service/internal/domain/log"
 	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/docker"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/metrics"
 	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/devathh/coderun/xcutr-service/internal/infrastructure/docker/container")
+
 type option struct {
 	image string
 	cmd   []string
@@ -29,17 +39,19 @@ type option struct {
 
 type ContainerRepository struct {
 	cfg     *config.Config
-	cli     *client.Client
+	pool    *docker.Pool
 	options map[int]option
+	// container id -> host the container was placed on
+	placed sync.Map
 }
 
-func New(cfg *config.Config, cli *client.Client) (*ContainerRepository, error) {
-	if cli == nil || cfg == nil {
+func New(cfg *config.Config, pool *docker.Pool) (*ContainerRepository, error) {
+	if pool == nil || cfg == nil {
 		return nil, customerrors.ErrNilArgs
 	}
 	return &ContainerRepository{
-		cfg: cfg,
-		cli: cli,
+		cfg:  cfg,
+		pool: pool,
 		options: map[int]option{
 			int(xcutrcontainer.GO): {
 				image: cfg.Secrets.Docker.ImageGo,
@@ -53,50 +65,110 @@ func New(cfg *config.Config, cli *client.Client) (*ContainerRepository, error) {
 	}, nil
 }
 
-func (cr *ContainerRepository) Run(ctx context.Context, domainContainer *xcutrcontainer.Container) (*xcutrcontainer.Container, error) {
+func (cr *ContainerRepository) Run(ctx context.Context, domainContainer *xcutrcontainer.Container) (_ *xcutrcontainer.Container, err error) {
+	ctx, span := tracer.Start(ctx, "containerdocker.Run", trace.WithAttributes(
+		attribute.String("language", domainContainer.Lang().String()),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	if err := cr.pullImage(ctx, cr.cfg.Secrets.Docker.ImageGo); err != nil {
+	host, err := cr.pool.Acquire()
+	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(attribute.String("docker.host", host.Name()))
+
+	containerID, err := cr.run(ctx, host, domainContainer)
+	if err != nil {
+		cr.pool.Release(host)
+		if client.IsErrConnectionFailed(err) {
+			cr.pool.Fail(host, err)
+		}
+
+		return nil, fmt.Errorf("failed to run container on %s: %w", host.Name(), err)
+	}
+	cr.placed.Store(containerID, host)
+	span.SetAttributes(attribute.String("container.id", containerID))
+
+	return xcutrcontainer.From(
+		domainContainer.ID(),
+		domainContainer.Lang(),
+		domainContainer.Files(),
+		domainContainer.MaxTimeout(),
+		domainContainer.Args(),
+		domainContainer.Env(),
+		containerID,
+	), nil
+}
+
+func (cr *ContainerRepository) run(ctx context.Context, host *docker.Host, domainContainer *xcutrcontainer.Container) (string, error) {
+	cli := host.Client()
+	option := cr.options[domainContainer.Lang().Value()]
+
+	if err := cr.pullImage(ctx, host, option.image); err != nil {
+		return "", err
+	}
 
 	containerName := fmt.Sprintf("%s-%s", domainContainer.ID().String(), domainContainer.Lang().String())
 
-	resp, err := cr.cli.ContainerCreate(ctx, &container.Config{
+	// User's args go after the run command of the language
+	cmd := append(slices.Clone(option.cmd), domainContainer.Args().List()...)
+
+	ctxCreate, span := tracer.Start(ctx, "containerdocker.create")
+	start := time.Now()
+	resp, err := cli.ContainerCreate(ctxCreate, &container.Config{
 		WorkingDir: "/",
-		Image:      cr.options[domainContainer.Lang().Value()].image,
-		Cmd:        cr.options[domainContainer.Lang().Value()].cmd,
+		Image:      option.image,
+		Cmd:        cmd,
+		Env:        domainContainer.Env().List(),
 		Tty:        false,
 	}, nil, nil, nil, containerName)
+	tracing.End(span, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create container: %w", err)
+		return "", fmt.Errorf("failed to create container: %w", err)
 	}
+	observeOperation("create", host, start)
 
-	if err := cr.copyFiles(ctx, domainContainer.Files(), resp.ID, "/"); err != nil {
-		return nil, err
+	if err := cr.copyFiles(ctx, host, domainContainer.Files(), resp.ID, "/"); err != nil {
+		removeCreated(host, resp.ID)
+		return "", err
 	}
 
-	if err := cr.cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
-		return nil, fmt.Errorf("failed to start container: %w", err)
+	ctxStart, span := tracer.Start(ctx, "containerdocker.start")
+	start = time.Now()
+	err = cli.ContainerStart(ctxStart, resp.ID, container.StartOptions{})
+	tracing.End(span, err)
+	if err != nil {
+		removeCreated(host, resp.ID)
+		return "", fmt.Errorf("failed to start container: %w", err)
 	}
+	observeOperation("start", host, start)
 
-	return xcutrcontainer.From(
-		domainContainer.ID(),
-		domainContainer.Lang(),
-		domainContainer.Files(),
-		domainContainer.MaxTimeout(),
-		resp.ID,
-	), nil
+	return resp.ID, nil
 }
 
-func (cr *ContainerRepository) Delete(ctx context.Context, containerID string) error {
+func (cr *ContainerRepository) Delete(ctx context.Context, containerID string) (err error) {
+	ctx, span := tracer.Start(ctx, "containerdocker.Delete", trace.WithAttributes(
+		attribute.String("container.id", containerID),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	_, err := cr.cli.ContainerInspect(ctx, containerID)
+	host, err := cr.hostOf(containerID)
+	if err != nil {
+		return err
+	}
+	// The execution is over even if the removal fails,
+	// so the container doesn't count in the load of the host anymore
+	defer cr.forget(containerID, host)
+
+	_, err = host.Client().ContainerInspect(ctx, containerID)
 	if err != nil {
 		if errors.Is(err, errdefs.ErrNotFound) {
 			return customerrors.ErrNotFoundContainer
@@ -105,23 +177,88 @@ func (cr *ContainerRepository) Delete(ctx context.Context, containerID string) e
 		return fmt.Errorf("failed to inspect container: %v", err)
 	}
 
-	if err := cr.cli.ContainerRemove(ctx, containerID, container.RemoveOptions{
-		Force: true,
-	}); err != nil {
+	start := time.Now()
+	if err := remove(ctx, host, containerID); err != nil {
 		return fmt.Errorf("failed to delete container: %w", err)
 	}
+	observeOperation("delete", host, start)
+
+	return nil
+}
+
+// remove deletes the container with its anonymous volumes.
+// Podman refuses to force-remove a container that is being stopped
+// and doesn't drop volumes by default, so the container is killed first.
+// A container that is already gone counts as removed
+func remove(ctx context.Context, host *docker.Host, containerID string) error {
+	if host.Podman() {
+		if err := host.Client().ContainerKill(ctx, containerID, "KILL"); err != nil &&
+			!errdefs.IsNotFound(err) && !errdefs.IsConflict(err) {
+			return fmt.Errorf("failed to kill container: %w", err)
+		}
+	}
+
+	err := host.Client().ContainerRemove(ctx, containerID, container.RemoveOptions{
+		Force:         true,
+		RemoveVolumes: host.Podman(),
+	})
+	if err != nil && !errdefs.IsNotFound(err) {
+		return err
+	}
 
 	return nil
 }
 
+func (cr *ContainerRepository) Inspect(ctx context.Context, containerID string) (xcutrcontainer.Exit, error) {
+	if err := ctx.Err(); err != nil {
+		return xcutrcontainer.Exit{}, err
+	}
+
+	host, err := cr.hostOf(containerID)
+	if err != nil {
+		return xcutrcontainer.Exit{}, err
+	}
+
+	info, err := host.Client().ContainerInspect(ctx, containerID)
+	if err != nil {
+		if errors.Is(err, errdefs.ErrNotFound) {
+			return xcutrcontainer.Exit{}, customerrors.ErrNotFoundContainer
+		}
+
+		return xcutrcontainer.Exit{}, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if info.State == nil {
+		return xcutrcontainer.Exit{}, errors.New("failed to inspect container: no state")
+	}
+
+	return xcutrcontainer.NewExit(
+		info.State.Running,
+		info.State.ExitCode,
+		info.State.OOMKilled,
+	), nil
+}
+
+// GetLogs follows the output of the container, its span lasts until the output is over
 func (cr *ContainerRepository) GetLogs(ctx context.Context, containerID string, logChan chan<- *xcutrlog.Log) error {
-	reader, err := cr.cli.ContainerLogs(ctx, containerID, container.LogsOptions{
+	ctx, span := tracer.Start(ctx, "containerdocker.GetLogs", trace.WithAttributes(
+		attribute.String("container.id", containerID),
+	))
+
+	host, err := cr.hostOf(containerID)
+	if err != nil {
+		tracing.End(span, err)
+		return err
+	}
+
+	reader, err := host.Client().ContainerLogs(ctx, containerID, container.LogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
 		Follow:     true,
 		Tail:       "all",
+		Timestamps: true,
 	})
 	if err != nil {
+		tracing.End(span, err)
 		return fmt.Errorf("failed to get container logs: %w", err)
 	}
 
@@ -129,85 +266,181 @@ func (cr *ContainerRepository) GetLogs(ctx context.Context, containerID string,
 		defer func() {
 			_ = reader.Close()
 			close(logChan)
+			span.End()
 		}()
 
 		bufReader := bufio.NewReader(reader)
 
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-			}
-
-			header := make([]byte, 8)
-			_, err := io.ReadFull(bufReader, header)
-			if err != nil {
-				if err == io.EOF {
-					return
-				}
-				return
-			}
-
-			size := binary.BigEndian.Uint32(header[4:8])
-			if size == 0 {
-				continue
-			}
-
-			data := make([]byte, size)
-			_, err = io.ReadFull(bufReader, data)
-			if err != nil {
-
-				return
-			}
-
-			data = bytes.TrimRight(data, "\n")
-
-			if len(data) == 0 {
-				continue
-			}
-
-			lines := bytes.SplitSeq(data, []byte{'\n'})
-			for l := range lines {
-				if len(l) == 0 {
-					continue
-				}
-				select {
-				case logChan <- xcutrlog.NewLog(string(l)):
-				case <-ctx.Done():
-					return
-				}
-			}
+		// Docker always multiplexes stdout n' stderr of containers without tty,
+		// podman's compatible API may send the raw output instead
+		if multiplexed(bufReader) {
+			readMultiplexed(ctx, bufReader, logChan)
+			return
 		}
+		readRaw(ctx, bufReader, logChan)
 	}()
 
 	return nil
 }
 
-func (cr *ContainerRepository) pullImage(ctx context.Context, imageStr string) error {
-	reader, err := cr.cli.ImagePull(ctx, imageStr, image.PullOptions{
+// multiplexed checks whether the stream starts with the header of docker's frame:
+// [stream type (0, 1 or 2), 0, 0, 0, size (4 bytes)]
+func multiplexed(reader *bufio.Reader) bool {
+	header, err := reader.Peek(8)
+	if err != nil {
+		return false
+	}
+
+	return header[0] <= 2 && header[1] == 0 && header[2] == 0 && header[3] == 0
+}
+
+func readMultiplexed(ctx context.Context, reader *bufio.Reader, logChan chan<- *xcutrlog.Log) {
+	header := make([]byte, 8)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return
+		}
+
+		size := binary.BigEndian.Uint32(header[4:8])
+		if size == 0 {
+			continue
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return
+		}
+
+		if !sendLines(ctx, data, logChan) {
+			return
+		}
+	}
+}
+
+func readRaw(ctx context.Context, reader *bufio.Reader, logChan chan<- *xcutrlog.Log) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		data, err := reader.ReadBytes('\n')
+		if len(data) != 0 && !sendLines(ctx, data, logChan) {
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// sendLines returns false if ctx is done
+func sendLines(ctx context.Context, data []byte, logChan chan<- *xcutrlog.Log) bool {
+	data = bytes.TrimRight(data, "\n")
+	if len(data) == 0 {
+		return true
+	}
+
+	lines := bytes.SplitSeq(data, []byte{'\n'})
+	for l := range lines {
+		if len(l) == 0 {
+			continue
+		}
+		select {
+		case logChan <- parseLine(l):
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseLine splits the timestamp that docker puts before every line.
+// A line without it (a continuation of a long write) gets the current time
+func parseLine(line []byte) *xcutrlog.Log {
+	rawTime, msg, ok := bytes.Cut(line, []byte{' '})
+	if ok {
+		if t, err := time.Parse(time.RFC3339Nano, string(rawTime)); err == nil {
+			return xcutrlog.NewLog(string(msg), t)
+		}
+	}
+
+	return xcutrlog.NewLog(string(line), time.Now())
+}
+
+func (cr *ContainerRepository) hostOf(containerID string) (*docker.Host, error) {
+	host, ok := cr.placed.Load(containerID)
+	if !ok {
+		return nil, customerrors.ErrNotFoundContainer
+	}
+
+	return host.(*docker.Host), nil
+}
+
+// forget releases the host only once, even if the container
+// is deleted concurrently
+func (cr *ContainerRepository) forget(containerID string, host *docker.Host) {
+	if _, loaded := cr.placed.LoadAndDelete(containerID); loaded {
+		cr.pool.Release(host)
+	}
+}
+
+// removeCreated removes the container that failed to start.
+// ctx of the run may be already canceled, so it uses its own
+func removeCreated(host *docker.Host, containerID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_ = remove(ctx, host, containerID)
+}
+
+func (cr *ContainerRepository) pullImage(ctx context.Context, host *docker.Host, imageStr string) (err error) {
+	ctx, span := tracer.Start(ctx, "containerdocker.pullImage", trace.WithAttributes(
+		attribute.String("image", imageStr),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	start := time.Now()
+	reader, err := host.Client().ImagePull(ctx, imageStr, image.PullOptions{
 		All: false,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to pull go image: %w", err)
+		return fmt.Errorf("failed to pull image: %w", err)
 	}
 	defer reader.Close()
 
+	// The pull is over when its progress is read to the end
 	_, _ = io.Copy(io.Discard, reader)
+	metrics.ImagePull.WithLabelValues(imageStr, host.Name()).Observe(time.Since(start).Seconds())
 
 	return nil
 }
 
-func (cr *ContainerRepository) copyFiles(ctx context.Context, files []xcutrcontainer.File, containerID, path string) error {
+// observeOperation records the duration of the succeeded operation
+func observeOperation(operation string, host *docker.Host, start time.Time) {
+	metrics.ContainerOperation.WithLabelValues(operation, host.Name()).Observe(time.Since(start).Seconds())
+}
+
+// copyFiles puts the files into the path of the container.
+// Names in the archive are relative to the path: podman
+// doesn't accept absolute ones, docker resolves both the same way
+func (cr *ContainerRepository) copyFiles(ctx context.Context, host *docker.Host, files []xcutrcontainer.File, containerID, path string) error {
 	buf := new(bytes.Buffer)
 	tw := tar.NewWriter(buf)
 
 	for _, file := range files {
-		containerPath := filepath.Join(path, fmt.Sprintf("%s.%s", file.Name(), file.Mime()))
-		containerPath = filepath.ToSlash(containerPath)
+		name := filepath.ToSlash(fmt.Sprintf("%s.%s", file.Name(), file.Mime()))
 
 		header := &tar.Header{
-			Name:    containerPath,
+			Name:    name,
 			Mode:    0644,
 			Size:    int64(len(file.Bytes())),
 			ModTime: time.Now(),
@@ -227,8 +460,8 @@ func (cr *ContainerRepository) copyFiles(ctx context.Context, files []xcutrconta
 		return fmt.Errorf("failed to close tar writer: %w", err)
 	}
 
-	err := cr.cli.CopyToContainer(ctx, containerID, "/", buf, container.CopyToContainerOptions{
-		AllowOverwriteDirWithFile: true,
+	err := host.Client().CopyToContainer(ctx, containerID, path, buf, container.CopyToContainerOptions{
+		AllowOverwriteDirWithFile: !host.Podman(),
 		CopyUIDGID:                false,
 	})
 	if err != nil {
@@ -0,0 +1,52 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+var errRootlessOverTCP = errors.New("rootless podman is available only through unix socket")
+
+// podmanSocket returns the first existing unix socket of the candidates.
+// Without candidates the default podman locations are checked:
+// rootless ones are in the runtime dir of the user, rootful one is in /run
+func podmanSocket(rootless bool, candidates []string) (string, error) {
+	if len(candidates) == 0 {
+		candidates = defaultPodmanSockets(rootless)
+	}
+
+	for _, path := range candidates {
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.Mode()&os.ModeSocket == 0 {
+			continue
+		}
+
+		return "unix://" + path, nil
+	}
+
+	return "", fmt.Errorf("podman socket isn't found in %v", candidates)
+}
+
+func defaultPodmanSockets(rootless bool) []string {
+	if !rootless {
+		return []string{"/run/podman/podman.sock"}
+	}
+
+	candidates := make([]string, 0, 2)
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		candidates = append(candidates, filepath.Join(runtimeDir, "podman", "podman.sock"))
+	}
+	candidates = append(candidates, filepath.Join("/run/user", strconv.Itoa(os.Getuid()), "podman", "podman.sock"))
+
+	return candidates
+}
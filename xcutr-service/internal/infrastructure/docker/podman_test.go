@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// listen creates a unix socket in dir. The dir is short, the path of a socket is limited
+func listen(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	return path
+}
+
+func TestPodmanSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "podman")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := listen(t, dir, "podman.sock")
+	other := listen(t, dir, "other.sock")
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	missing := filepath.Join(dir, "missing.sock")
+
+	testCases := []struct {
+		Name       string
+		Candidates []string
+		Want       string
+		WantErr    bool
+	}{
+		{Name: "found", Candidates: []string{socket}, Want: "unix://" + socket},
+		{Name: "first_existing", Candidates: []string{missing, socket, other}, Want: "unix://" + socket},
+		{Name: "order", Candidates: []string{other, socket}, Want: "unix://" + other},
+		{Name: "empty_path", Candidates: []string{"", socket}, Want: "unix://" + socket},
+		{Name: "not_socket", Candidates: []string{file, dir, socket}, Want: "unix://" + socket},
+		{Name: "none", Candidates: []string{missing, file}, WantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := podmanSocket(false, tc.Candidates)
+			if (err != nil) != tc.WantErr {
+				t.Fatalf("got %v, want error %v", err, tc.WantErr)
+			}
+			if got != tc.Want {
+				t.Errorf("got %q, want %q", got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestDefaultPodmanSockets(t *testing.T) {
+	userSocket := filepath.Join("/run/user", strconv.Itoa(os.Getuid()), "podman", "podman.sock")
+
+	testCases := []struct {
+		Name       string
+		Rootless   bool
+		RuntimeDir string
+		Want       []string
+	}{
+		{Name: "rootful", Rootless: false, RuntimeDir: "/tmp/runtime", Want: []string{"/run/podman/podman.sock"}},
+		{Name: "rootless", Rootless: true, RuntimeDir: "/tmp/runtime", Want: []string{"/tmp/runtime/podman/podman.sock", userSocket}},
+		{Name: "rootless_no_runtime_dir", Rootless: true, RuntimeDir: "", Want: []string{userSocket}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Setenv("XDG_RUNTIME_DIR", tc.RuntimeDir)
+
+			if got := defaultPodmanSockets(tc.Rootless); !slices.Equal(got, tc.Want) {
+				t.Errorf("got %v, want %v", got, tc.Want)
+			}
+		})
+	}
+}
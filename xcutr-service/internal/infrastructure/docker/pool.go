@@ -0,0 +1,220 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"github.com/docker/docker/client"
+)
+
+const pingTimeout = 5 * time.Second
+
+// Host is a docker daemon that executions can be placed on
+type Host struct {
+	name   string
+	engine string
+	cli    *client.Client
+
+	healthy atomic.Bool
+	// Running containers that weren't placed by this pool
+	external atomic.Int64
+	// Executions placed on the host by this pool
+	active atomic.Int64
+}
+
+func (h *Host) Name() string {
+	return h.name
+}
+
+func (h *Host) Client() *client.Client {
+	return h.cli
+}
+
+// Podman reports whether the host is podman's docker-compatible API
+func (h *Host) Podman() bool {
+	return h.engine == config.EnginePodman
+}
+
+func (h *Host) load() int64 {
+	return h.external.Load() + h.active.Load()
+}
+
+// Pool places executions on the least loaded healthy host.
+// Hosts are pinged periodically, failed ones are taken out of rotation
+// until they answer again
+type Pool struct {
+	log      *slog.Logger
+	hosts    []*Host
+	interval time.Duration
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewPool(cfg *config.Config, log *slog.Logger) (*Pool, error) {
+	if cfg == nil || log == nil {
+		return nil, customerrors.ErrNilArgs
+	}
+
+	hosts := make([]*Host, 0, len(cfg.Secrets.Docker.Hosts))
+	if len(cfg.Secrets.Docker.Hosts) == 0 {
+		cli, err := Connect()
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, &Host{name: "env", engine: config.EngineDocker, cli: cli})
+	}
+
+	for _, hostCfg := range cfg.Secrets.Docker.Hosts {
+		cli, err := ConnectHost(hostCfg, cfg.Secrets.Docker.PodmanSockets)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, &Host{name: hostCfg.Name, engine: hostCfg.Engine, cli: cli})
+	}
+
+	pool := &Pool{
+		log:      log,
+		hosts:    hosts,
+		interval: cfg.Secrets.Docker.HealthInterval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	pool.check()
+	if !pool.anyHealthy() {
+		return nil, fmt.Errorf("failed to ping docker: %w", customerrors.ErrNoDockerHosts)
+	}
+
+	go pool.watch()
+
+	return pool, nil
+}
+
+// Acquire picks the least loaded healthy host.
+// Every acquired host must be released
+func (p *Pool) Acquire() (*Host, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best *Host
+	for _, host := range p.hosts {
+		if !host.healthy.Load() {
+			continue
+		}
+		if best == nil || host.load() < best.load() {
+			best = host
+		}
+	}
+
+	if best == nil {
+		return nil, customerrors.ErrNoDockerHosts
+	}
+
+	best.active.Add(1)
+	return best, nil
+}
+
+// Check reports whether any host is in rotation.
+// The hosts are pinged by the pool itself, so ctx isn't used
+func (p *Pool) Check(_ context.Context) error {
+	if !p.anyHealthy() {
+		return customerrors.ErrNoDockerHosts
+	}
+
+	return nil
+}
+
+func (p *Pool) Release(host *Host) {
+	host.active.Add(-1)
+}
+
+// Fail takes the host out of rotation until the next successful ping
+func (p *Pool) Fail(host *Host, err error) {
+	if host.healthy.Swap(false) {
+		p.log.Warn("docker host is out of rotation",
+			slog.String("host", host.name),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (p *Pool) Close() {
+	close(p.stop)
+	<-p.done
+
+	for _, host := range p.hosts {
+		if err := host.cli.Close(); err != nil {
+			p.log.Warn("failed to close docker client", slog.String("host", host.name), slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (p *Pool) watch() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.check()
+		}
+	}
+}
+
+func (p *Pool) check() {
+	var wg sync.WaitGroup
+	for _, host := range p.hosts {
+		wg.Go(func() {
+			p.checkHost(host)
+		})
+	}
+	wg.Wait()
+}
+
+func (p *Pool) checkHost(host *Host) {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	if _, err := host.cli.Ping(ctx); err != nil {
+		p.Fail(host, err)
+		return
+	}
+
+	info, err := host.cli.Info(ctx)
+	if err != nil {
+		p.Fail(host, err)
+		return
+	}
+
+	external := int64(info.ContainersRunning) - host.active.Load()
+	host.external.Store(max(external, 0))
+
+	if !host.healthy.Swap(true) {
+		p.log.Info("docker host is in rotation",
+			slog.String("host", host.name),
+			slog.Int("running", info.ContainersRunning),
+		)
+	}
+}
+
+func (p *Pool) anyHealthy() bool {
+	for _, host := range p.hosts {
+		if host.healthy.Load() {
+			return true
+		}
+	}
+
+	return false
+}
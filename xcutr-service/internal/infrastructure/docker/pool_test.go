@@ -0,0 +1,166 @@
+package docker
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+)
+
+type hostState struct {
+	Name     string
+	Healthy  bool
+	External int64
+	Active   int64
+}
+
+// newTestPool builds the pool on the hosts without pinging them
+func newTestPool(states []hostState) *Pool {
+	pool := &Pool{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	for _, state := range states {
+		host := &Host{name: state.Name}
+		host.healthy.Store(state.Healthy)
+		host.external.Store(state.External)
+		host.active.Store(state.Active)
+		pool.hosts = append(pool.hosts, host)
+	}
+
+	return pool
+}
+
+func TestPoolAcquire(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Hosts    []hostState
+		WantHost string
+		WantErr  error
+	}{
+		{Name: "single", Hosts: []hostState{{Name: "a", Healthy: true}}, WantHost: "a"},
+		{Name: "least_active", Hosts: []hostState{
+			{Name: "a", Healthy: true, Active: 3},
+			{Name: "b", Healthy: true, Active: 1},
+			{Name: "c", Healthy: true, Active: 2},
+		}, WantHost: "b"},
+		// The containers of others count in the load too
+		{Name: "external_load", Hosts: []hostState{
+			{Name: "a", Healthy: true, Active: 1, External: 5},
+			{Name: "b", Healthy: true, Active: 3},
+		}, WantHost: "b"},
+		{Name: "tie_first", Hosts: []hostState{
+			{Name: "a", Healthy: true, Active: 1},
+			{Name: "b", Healthy: true, Active: 1},
+		}, WantHost: "a"},
+		{Name: "unhealthy_skipped", Hosts: []hostState{
+			{Name: "a", Healthy: false},
+			{Name: "b", Healthy: true, Active: 10},
+		}, WantHost: "b"},
+		{Name: "all_unhealthy", Hosts: []hostState{
+			{Name: "a", Healthy: false},
+			{Name: "b", Healthy: false},
+		}, WantErr: customerrors.ErrNoDockerHosts},
+		{Name: "no_hosts", WantErr: customerrors.ErrNoDockerHosts},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			pool := newTestPool(tc.Hosts)
+			host, err := pool.Acquire()
+			if !errors.Is(err, tc.WantErr) {
+				t.Fatalf("got %v, want %v", err, tc.WantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if host.Name() != tc.WantHost {
+				t.Errorf("got %s, want %s", host.Name(), tc.WantHost)
+			}
+		})
+	}
+}
+
+func TestPoolSpread(t *testing.T) {
+	pool := newTestPool([]hostState{
+		{Name: "a", Healthy: true},
+		{Name: "b", Healthy: true, External: 1},
+	})
+
+	// The acquired executions count in the load till they are released
+	var got []string
+	var hosts []*Host
+	for range 4 {
+		host, err := pool.Acquire()
+		if err != nil {
+			t.Fatalf("failed to acquire: %v", err)
+		}
+		got = append(got, host.Name())
+		hosts = append(hosts, host)
+	}
+	if want := []string{"a", "a", "b", "a"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, host := range hosts {
+		pool.Release(host)
+	}
+	for _, host := range pool.hosts {
+		if active := host.active.Load(); active != 0 {
+			t.Errorf("got %d active on %s after release, want 0", active, host.Name())
+		}
+	}
+}
+
+func TestPoolFail(t *testing.T) {
+	pool := newTestPool([]hostState{
+		{Name: "a", Healthy: true},
+		{Name: "b", Healthy: true, Active: 5},
+	})
+	errDown := errors.New("daemon is down")
+
+	steps := []struct {
+		Name string
+		Fail string
+		// Empty if no host is left
+		WantHost  string
+		WantCheck error
+	}{
+		{Name: "healthy", WantHost: "a"},
+		// The failed host is out of rotation, even if it's the least loaded
+		{Name: "fail_a", Fail: "a", WantHost: "b"},
+		// A second failure changes nothing
+		{Name: "fail_a_again", Fail: "a", WantHost: "b"},
+		{Name: "fail_b", Fail: "b", WantCheck: customerrors.ErrNoDockerHosts},
+	}
+
+	for _, step := range steps {
+		for _, host := range pool.hosts {
+			if host.Name() == step.Fail {
+				pool.Fail(host, errDown)
+			}
+		}
+
+		if err := pool.Check(t.Context()); !errors.Is(err, step.WantCheck) {
+			t.Errorf("%s: got check %v, want %v", step.Name, err, step.WantCheck)
+		}
+
+		host, err := pool.Acquire()
+		if step.WantHost == "" {
+			if !errors.Is(err, customerrors.ErrNoDockerHosts) {
+				t.Errorf("%s: got %v, want %v", step.Name, err, customerrors.ErrNoDockerHosts)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: failed to acquire: %v", step.Name, err)
+		}
+		if host.Name() != step.WantHost {
+			t.Errorf("%s: got %s, want %s", step.Name, host.Name(), step.WantHost)
+		}
+		pool.Release(host)
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 
 	xcutrpb "github.com/devathh/coderun/xcutr-service/api/xcutr/v1"
@@ -46,9 +47,194 @@ func (sapi *ServerAPI) Execute(req *xcutrpb.ExecutionRequest, stream grpc.Server
 		if errors.Is(err, customerrors.ErrTooLargeFile) {
 			return status.Error(codes.InvalidArgument, err.Error())
 		}
+		if errors.Is(err, customerrors.ErrInvalidEnv) ||
+			errors.Is(err, customerrors.ErrReservedEnv) ||
+			errors.Is(err, customerrors.ErrTooManyEnv) ||
+			errors.Is(err, customerrors.ErrTooLargeEnv) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrInvalidArgs) ||
+			errors.Is(err, customerrors.ErrTooManyArgs) ||
+			errors.Is(err, customerrors.ErrTooLargeArgs) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrInvalidLang) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrInvalidToken) {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrCanceled) {
+			return status.Error(codes.Canceled, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrShuttingDown) {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrNoDockerHosts) {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
+
+func (sapi *ServerAPI) CancelExecution(ctx context.Context, req *xcutrpb.CancelRequest) (*xcutrpb.Empty, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be empty")
+	}
+
+	if err := sapi.service.CancelExecution(ctx, req); err != nil {
+		if errors.Is(err, customerrors.ErrInvalidExecID) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrNotFoundExec) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrNotOwner) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrInvalidToken) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &xcutrpb.Empty{}, nil
+}
+
+func (sapi *ServerAPI) AttachExecution(req *xcutrpb.AttachRequest, stream grpc.ServerStreamingServer[xcutrpb.Log]) error {
+	if req == nil {
+		return status.Error(codes.InvalidArgument, "request cannot be empty")
+	}
+
+	if err := sapi.service.AttachExecution(req, stream); err != nil {
+		if errors.Is(err, customerrors.ErrInvalidExecID) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrNotFoundExec) {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrNotOwner) {
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrInvalidToken) {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrTooManyViewers) {
+			return status.Error(codes.ResourceExhausted, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrCanceled) {
+			return status.Error(codes.Canceled, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrShuttingDown) {
+			return status.Error(codes.Unavailable, err.Error())
+		}
 
 		return status.Error(codes.Internal, err.Error())
 	}
 
 	return nil
 }
+
+func (sapi *ServerAPI) ListExecutions(ctx context.Context, req *xcutrpb.ListExecutionsRequest) (*xcutrpb.ListExecutionsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be empty")
+	}
+
+	resp, err := sapi.service.ListExecutions(ctx, req)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidLang) ||
+			errors.Is(err, customerrors.ErrInvalidStatus) ||
+			errors.Is(err, customerrors.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrInvalidToken) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrHistoryDisabled) {
+			return nil, status.Error(codes.Unimplemented, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return resp, nil
+}
+
+func (sapi *ServerAPI) GetExecution(ctx context.Context, req *xcutrpb.GetExecutionRequest) (*xcutrpb.ExecutionRecord, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be empty")
+	}
+
+	resp, err := sapi.service.GetExecution(ctx, req)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidExecID) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrNotFoundExec) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrInvalidToken) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrHistoryDisabled) {
+			return nil, status.Error(codes.Unimplemented, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return resp, nil
+}
+
+func (sapi *ServerAPI) GetUsageStats(ctx context.Context, req *xcutrpb.UsageStatsRequest) (*xcutrpb.UsageStats, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be empty")
+	}
+
+	resp, err := sapi.service.GetUsageStats(ctx, req)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidLang) ||
+			errors.Is(err, customerrors.ErrInvalidPeriod) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrInvalidToken) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrNotAdmin) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrStatsDisabled) {
+			return nil, status.Error(codes.Unimplemented, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return resp, nil
+}
+
+func (sapi *ServerAPI) EraseUserData(ctx context.Context, req *xcutrpb.EraseUserDataRequest) (*xcutrpb.Empty, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be empty")
+	}
+
+	if err := sapi.service.EraseUserData(ctx, req); err != nil {
+		if errors.Is(err, customerrors.ErrInvalidUserID) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrInvalidToken) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if errors.Is(err, customerrors.ErrNotAdmin) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &xcutrpb.Empty{}, nil
+}
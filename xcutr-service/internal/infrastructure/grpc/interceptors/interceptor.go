@@ -21,16 +21,22 @@ func (ws *WrapperStream) Context() context.Context {
 }
 
 type PackInterceptors struct {
-	log         *slog.Logger
-	jwtManager  auth.JWTManager
+	log        *slog.Logger
+	jwtManager auth.JWTManager
+	// Nil if the denylist is disabled
+	denylist    auth.Denylist
 	authRequire map[string]bool
+	// The methods that need the verified email besides the token
+	verifiedRequire map[string]bool
 }
 
-func New(log *slog.Logger, jwtManager auth.JWTManager, authRequire map[string]bool) *PackInterceptors {
+func New(log *slog.Logger, jwtManager auth.JWTManager, denylist auth.Denylist, authRequire, verifiedRequire map[string]bool) *PackInterceptors {
 	return &PackInterceptors{
-		log:         log,
-		jwtManager:  jwtManager,
-		authRequire: authRequire,
+		log:             log,
+		jwtManager:      jwtManager,
+		denylist:        denylist,
+		authRequire:     authRequire,
+		verifiedRequire: verifiedRequire,
 	}
 }
 
@@ -40,29 +46,68 @@ func (p *PackInterceptors) AuthInterceptor() grpc.StreamServerInterceptor {
 			return handler(srv, ss)
 		}
 
-		md, ok := metadata.FromIncomingContext(ss.Context())
-		if !ok {
-			return status.Error(codes.Unauthenticated, "failed to get metadata")
+		ctx, err := p.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
 		}
 
-		access := md.Get("session")
-		if len(access) < 1 {
-			return status.Error(codes.Unauthenticated, "token is empty")
+		wrappedStream := &WrapperStream{
+			ServerStream: ss,
+			ctx:          ctx,
+		}
+
+		return handler(srv, wrappedStream)
+	}
+
+}
+
+func (p *PackInterceptors) UnaryAuthInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if !p.authRequire[info.FullMethod] {
+			return handler(ctx, req)
 		}
 
-		claims, err := p.jwtManager.Validate(access[0])
+		ctx, err = p.authorize(ctx, info.FullMethod)
 		if err != nil {
-			return status.Error(codes.Unauthenticated, "invalid token")
+			return nil, err
 		}
 
-		ctx := context.WithValue(ss.Context(), auth.CtxKey("user_id"), claims.UserID)
+		return handler(ctx, req)
+	}
+}
 
-		wrappedStream := &WrapperStream{
-			ServerStream: ss,
-			ctx:          ctx,
+func (p *PackInterceptors) authorize(ctx context.Context, method string) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "failed to get metadata")
+	}
+
+	access := md.Get("session")
+	if len(access) < 1 {
+		return nil, status.Error(codes.Unauthenticated, "token is empty")
+	}
+
+	claims, err := p.jwtManager.Validate(access[0])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	if p.denylist != nil {
+		revoked, err := p.denylist.IsRevoked(ctx, claims)
+		if err != nil {
+			// A revoked token mustn't run code while redis is unavailable
+			p.log.Error("failed to check revocation of token", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Unavailable, "failed to check token")
 		}
+		if revoked {
+			return nil, status.Error(codes.Unauthenticated, "token is revoked")
+		}
+	}
 
-		return handler(srv, wrappedStream)
+	// The tokens issued before the verification have it false till the refresh
+	if p.verifiedRequire[method] && !claims.EmailVerified {
+		return nil, status.Error(codes.PermissionDenied, "email isn't verified")
 	}
 
+	return context.WithValue(ctx, auth.CtxKey("user_id"), claims.UserID), nil
 }
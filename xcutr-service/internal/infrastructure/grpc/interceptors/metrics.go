@@ -0,0 +1,37 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsInterceptor counts the streams n' measures them until they're closed.
+// It goes first, so the rejected by auth ones are counted too
+func MetricsInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observe(info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func UnaryMetricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observe(info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+func observe(method string, start time.Time, err error) {
+	metrics.GRPCHandled.WithLabelValues(method, status.Code(err).String()).Inc()
+	metrics.GRPCHandling.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
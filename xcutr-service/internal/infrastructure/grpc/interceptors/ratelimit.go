@@ -0,0 +1,114 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/devathh/coderun/shared/ratelimit"
+	"github.com/devathh/coderun/xcutr-service/internal/domain/auth"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimiter limits the calls by the rules of their methods.
+// It goes after auth, so the calls can be limited by user
+type RateLimiter struct {
+	log            *slog.Logger
+	limiter        *ratelimit.Limiter
+	trustForwarded bool
+}
+
+func NewRateLimiter(cfg *config.Config, log *slog.Logger, limiter *ratelimit.Limiter) *RateLimiter {
+	return &RateLimiter{
+		log:            log,
+		limiter:        limiter,
+		trustForwarded: cfg.RateLimit.TrustForwarded,
+	}
+}
+
+func (rl *RateLimiter) RateLimitInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := rl.allow(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func (rl *RateLimiter) UnaryRateLimitInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := rl.allow(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (rl *RateLimiter) allow(ctx context.Context, method string) error {
+	rule, ok := rl.limiter.Rule(method)
+	if !ok {
+		return nil
+	}
+
+	result, err := rl.limiter.Allow(ctx, rule, rl.client(ctx, rule))
+	if err != nil {
+		// A limit isn't worth failing the call
+		rl.log.Warn("rate limit is skipped", slog.String("method", method), slog.String("error", err.Error()))
+		return nil
+	}
+
+	if err := grpc.SetHeader(ctx, metadata.New(result.Headers())); err != nil {
+		rl.log.Debug("failed to set rate limit headers", slog.String("error", err.Error()))
+	}
+
+	if !result.Allowed {
+		// The time to retry is in the retry-after header
+		return status.Error(codes.ResourceExhausted, "rate limit is exceeded")
+	}
+
+	return nil
+}
+
+// client is the user from the token or the ip of the caller
+func (rl *RateLimiter) client(ctx context.Context, rule ratelimit.Rule) string {
+	if rule.Key == config.RateLimitByUser {
+		if userID, ok := ctx.Value(auth.CtxKey("user_id")).(uuid.UUID); ok {
+			return "user:" + userID.String()
+		}
+	}
+
+	return "ip:" + rl.clientIP(ctx)
+}
+
+func (rl *RateLimiter) clientIP(ctx context.Context) string {
+	if rl.trustForwarded {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if forwarded := md.Get("x-forwarded-for"); len(forwarded) > 0 {
+			ip, _, _ := strings.Cut(forwarded[0], ",")
+			if ip = strings.TrimSpace(ip); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
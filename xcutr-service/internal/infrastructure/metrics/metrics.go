@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Registry holds all the metrics of the service,
+// the default one of prometheus isn't used
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var (
+	// grpc server's
+	GRPCHandled = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Handled gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+	GRPCHandling = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Duration of gRPC calls by method. Streams are measured until they're closed.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"method"})
+
+	// executor's
+	Executions = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "xcutr_executions_total",
+		Help: "Finished executions by language and status.",
+	}, []string{"language", "status"})
+	ExecutionsRunning = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "xcutr_executions_running",
+		Help: "Executions that are running or waiting for a container.",
+	})
+	ContainerOperation = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "xcutr_container_operation_seconds",
+		Help:    "Duration of container operations by operation (create, start, delete) and host.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"operation", "host"})
+	ImagePull = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "xcutr_image_pull_seconds",
+		Help:    "Duration of image pulls by image and host.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 14),
+	}, []string{"image", "host"})
+
+	// analytics'
+	AnalyticsQueue = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "xcutr_analytics_queue_depth",
+		Help: "Analytics events waiting to be written to clickhouse.",
+	})
+	AnalyticsDropped = factory.NewCounter(prometheus.CounterOpts{
+		Name: "xcutr_analytics_dropped_total",
+		Help: "Analytics events dropped because the queue was full.",
+	})
+	AnalyticsSpillDropped = factory.NewCounter(prometheus.CounterOpts{
+		Name: "xcutr_analytics_spill_dropped_total",
+		Help: "Spilled analytics events dropped because the spill file was full.",
+	})
+)
@@ -2,21 +2,23 @@ package clickhouse
 
 import (
 	"context"
-	"embed"
 	"fmt"
+	"math"
 	"net"
-	"path/filepath"
-	"sort"
 	"strings"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/devathh/coderun/shared/tracing"
+	"github.com/devathh/coderun/xcutr-service/internal/domain/observability"
 	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
-	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-//go:embed migrations/*.sql
-var migrationsFS embed.FS
+var tracer = otel.Tracer("github.com/devathh/coderun/xcutr-service/internal/infrastructure/observability/clickhouse")
 
 type ClickhouseClient struct {
 	conn driver.Conn
@@ -47,50 +49,211 @@ func New(cfg *config.Config) (*ClickhouseClient, error) {
 	}, nil
 }
 
-func (ch *ClickhouseClient) Up() error {
-	migrations, err := migrationsFS.ReadDir("migrations")
-	if err != nil {
-		return fmt.Errorf("failed to read migrations: %w", err)
+func (ch *ClickhouseClient) Ping(ctx context.Context) error {
+	return ch.conn.Ping(ctx)
+}
+
+func (ch *ClickhouseClient) WriteEvents(ctx context.Context, events []*observability.ExecutionEvent) (err error) {
+	ctx, span := tracer.Start(ctx, "clickhouse.WriteEvents", trace.WithAttributes(
+		attribute.Int("events", len(events)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return nil
 	}
 
-	sort.Slice(migrations, func(i, j int) bool {
-		return migrations[i].Name() < migrations[j].Name()
-	})
+	batch, err := ch.conn.PrepareBatch(ctx, `INSERT INTO user_services (
+			timestamp,
+			user_id,
+			language,
+			execution_id,
+			duration_ms,
+			status,
+			exit_code,
+			timed_out,
+			oom_killed,
+			output_bytes,
+			image,
+			executor
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer batch.Abort()
 
-	for _, migration := range migrations {
-		if filepath.Ext(migration.Name()) != ".sql" {
+	for _, event := range events {
+		language := strings.TrimSpace(event.Language)
+		if language == "" {
 			continue
 		}
 
-		content, err := migrationsFS.ReadFile("migrations/" + migration.Name())
-		if err != nil {
-			return fmt.Errorf("failed to read migration file: %w", err)
+		if err := batch.Append(
+			event.Timestamp,
+			event.UserID,
+			language,
+			event.ExecutionID,
+			uint64(event.Duration.Milliseconds()),
+			event.Status,
+			int32(event.ExitCode),
+			event.TimedOut,
+			event.OOMKilled,
+			event.OutputBytes,
+			event.Image,
+			event.Executor,
+		); err != nil {
+			return fmt.Errorf("failed to append event: %w", err)
 		}
+	}
 
-		if err := ch.conn.Exec(context.Background(), string(content)); err != nil {
-			return fmt.Errorf("failed to apply migration: %w", err)
-		}
+	if err := batch.Send(); err != nil {
+		return fmt.Errorf("failed to save events: %w", err)
 	}
 
 	return nil
 }
 
-func (ch *ClickhouseClient) WriteSession(ctx context.Context, userID, language string) error {
+// Statuses of the runs that are counted as failures
+const failedStatuses = "('failed', 'timeout', 'oom', 'error')"
+
+type dailyRunsRow struct {
+	Day      time.Time `ch:"day"`
+	Language string    `ch:"language"`
+	Runs     uint64    `ch:"runs"`
+}
+
+type languageStatsRow struct {
+	Language string  `ch:"language"`
+	Runs     uint64  `ch:"runs"`
+	Failures uint64  `ch:"failures"`
+	Timeouts uint64  `ch:"timeouts"`
+	OOMKills uint64  `ch:"oom_kills"`
+	P50      float64 `ch:"p50"`
+	P95      float64 `ch:"p95"`
+}
+
+type userUsageRow struct {
+	UserID     string `ch:"user_id"`
+	Runs       uint64 `ch:"runs"`
+	DurationMS uint64 `ch:"duration_ms"`
+}
+
+func (ch *ClickhouseClient) UsageStats(ctx context.Context, filter observability.StatsFilter) (*observability.UsageStats, error) {
 	if err := ctx.Err(); err != nil {
-		return err
+		return nil, err
 	}
 
-	language = strings.TrimSpace(language)
-	if language == "" {
-		return customerrors.ErrNilArgs
+	where := "timestamp >= ? AND timestamp < ?"
+	args := []any{filter.From, filter.To}
+	if filter.Language != "" {
+		where += " AND language = ?"
+		args = append(args, filter.Language)
 	}
 
-	if err := ch.conn.Exec(ctx, `INSERT INTO user_services (
-			user_id,
+	var daily []dailyRunsRow
+	if err := ch.conn.Select(ctx, &daily, `SELECT
+			toDate(timestamp) AS day,
+			language,
+			count() AS runs
+		FROM user_services
+		WHERE `+where+`
+		GROUP BY day, language
+		ORDER BY day, language`, args...); err != nil {
+		return nil, fmt.Errorf("failed to get daily runs: %w", err)
+	}
+
+	// Runs written before the details were added have no status,
+	// they are left out of the durations
+	var languages []languageStatsRow
+	if err := ch.conn.Select(ctx, &languages, `SELECT
 			language,
-		) VALUES (?, ?)`, userID, language); err != nil {
-		return fmt.Errorf("failed to save session: %w", err)
+			count() AS runs,
+			countIf(status IN `+failedStatuses+`) AS failures,
+			countIf(timed_out) AS timeouts,
+			countIf(oom_killed) AS oom_kills,
+			quantileIf(0.5)(duration_ms, status != '') AS p50,
+			quantileIf(0.95)(duration_ms, status != '') AS p95
+		FROM user_services
+		WHERE `+where+`
+		GROUP BY language
+		ORDER BY runs DESC`, args...); err != nil {
+		return nil, fmt.Errorf("failed to get language stats: %w", err)
+	}
+
+	var users []userUsageRow
+	if err := ch.conn.Select(ctx, &users, `SELECT
+			user_id,
+			count() AS runs,
+			sum(duration_ms) AS duration_ms
+		FROM user_services
+		WHERE `+where+`
+		GROUP BY user_id
+		ORDER BY runs DESC, user_id
+		LIMIT ?`, append(args, filter.TopUsers)...); err != nil {
+		return nil, fmt.Errorf("failed to get top users: %w", err)
+	}
+
+	stats := &observability.UsageStats{
+		Daily:     make([]observability.DailyRuns, 0, len(daily)),
+		Languages: make([]observability.LanguageStats, 0, len(languages)),
+		TopUsers:  make([]observability.UserUsage, 0, len(users)),
+	}
+	for _, row := range daily {
+		stats.Daily = append(stats.Daily, observability.DailyRuns{
+			Day:      row.Day,
+			Language: row.Language,
+			Runs:     row.Runs,
+		})
+	}
+	for _, row := range languages {
+		stats.Languages = append(stats.Languages, observability.LanguageStats{
+			Language:    row.Language,
+			Runs:        row.Runs,
+			Failures:    row.Failures,
+			FailureRate: float64(row.Failures) / float64(max(row.Runs, 1)),
+			Timeouts:    row.Timeouts,
+			OOMKills:    row.OOMKills,
+			P50Duration: millis(row.P50),
+			P95Duration: millis(row.P95),
+		})
+	}
+	for _, row := range users {
+		stats.TopUsers = append(stats.TopUsers, observability.UserUsage{
+			UserID:   row.UserID,
+			Runs:     row.Runs,
+			Duration: time.Duration(row.DurationMS) * time.Millisecond,
+		})
+	}
+
+	return stats, nil
+}
+
+// millis converts a quantile of milliseconds, which is nan without rows
+func millis(ms float64) time.Duration {
+	if math.IsNaN(ms) {
+		return 0
+	}
+
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// DeleteUser removes the events of the user n' waits until they are gone
+func (ch *ClickhouseClient) DeleteUser(ctx context.Context, userID string) error {
+	ctx = clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{
+		"mutations_sync": 1,
+	}))
+
+	if err := ch.conn.Exec(ctx, "ALTER TABLE user_services DELETE WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete events of user: %w", err)
 	}
 
 	return nil
 }
+
+// Conn shares the connection with the other repositories on clickhouse
+func (ch *ClickhouseClient) Conn() driver.Conn {
+	return ch.conn
+}
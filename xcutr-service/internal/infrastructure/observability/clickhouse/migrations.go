@@ -0,0 +1,342 @@
+package clickhouse
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+)
+
+// Migrations are named NNN_name.up.sql n' NNN_name.down.sql,
+// the number is the version of the schema
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// Applied migrations are appended, never updated: rolling back adds
+// a row with applied = false. The last row of a version is its state
+const createSchemaMigrations = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version UInt32,
+	name String,
+	checksum String,
+	applied Bool,
+	created_at DateTime64(3) DEFAULT now64()
+) ENGINE = MergeTree()
+ORDER BY (version, created_at)`
+
+type migration struct {
+	version  uint32
+	name     string
+	up       string
+	down     string
+	checksum string
+}
+
+type appliedMigration struct {
+	Version   uint32    `ch:"version"`
+	Name      string    `ch:"name"`
+	Checksum  string    `ch:"checksum"`
+	Applied   bool      `ch:"applied"`
+	AppliedAt time.Time `ch:"applied_at"`
+}
+
+type MigrationStatus struct {
+	Version   uint32
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// The file is changed since it was applied
+	Edited bool
+	// Applied, but the file doesn't exist anymore
+	Missing bool
+}
+
+// Up applies the new migrations in order
+func (ch *ClickhouseClient) Up() error {
+	ctx := context.Background()
+
+	migrations, applied, err := ch.migrationState(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := verify(migrations, applied); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.version].Applied {
+			continue
+		}
+
+		if err := ch.execScript(ctx, m.up); err != nil {
+			return fmt.Errorf("failed to apply migration %03d_%s: %w", m.version, m.name, err)
+		}
+		if err := ch.record(ctx, m, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Down rolls back the last steps applied migrations
+func (ch *ClickhouseClient) Down(steps int) error {
+	ctx := context.Background()
+
+	migrations, applied, err := ch.migrationState(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := verify(migrations, applied); err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		m := migrations[i]
+		if !applied[m.version].Applied {
+			continue
+		}
+
+		if strings.TrimSpace(m.down) == "" {
+			return fmt.Errorf("migration %03d_%s has no down migration", m.version, m.name)
+		}
+		if err := ch.execScript(ctx, m.down); err != nil {
+			return fmt.Errorf("failed to roll back migration %03d_%s: %w", m.version, m.name, err)
+		}
+		if err := ch.record(ctx, m, false); err != nil {
+			return err
+		}
+		steps--
+	}
+
+	return nil
+}
+
+// Status reports every known migration, both embedded n' applied ones
+func (ch *ClickhouseClient) Status(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, applied, err := ch.migrationState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	known := make(map[uint32]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.version] = true
+
+		state := applied[m.version]
+		statuses = append(statuses, MigrationStatus{
+			Version:   m.version,
+			Name:      m.name,
+			Applied:   state.Applied,
+			AppliedAt: state.AppliedAt,
+			Edited:    state.Applied && state.Checksum != m.checksum,
+		})
+	}
+
+	for version, state := range applied {
+		if known[version] || !state.Applied {
+			continue
+		}
+
+		statuses = append(statuses, MigrationStatus{
+			Version:   version,
+			Name:      state.Name,
+			Applied:   true,
+			AppliedAt: state.AppliedAt,
+			Missing:   true,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+func (ch *ClickhouseClient) migrationState(ctx context.Context) ([]migration, map[uint32]appliedMigration, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := ch.conn.Exec(ctx, createSchemaMigrations); err != nil {
+		return nil, nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var rows []appliedMigration
+	if err := ch.conn.Select(ctx, &rows, `SELECT
+			version,
+			argMax(name, created_at) AS name,
+			argMax(checksum, created_at) AS checksum,
+			argMax(applied, created_at) AS applied,
+			max(created_at) AS applied_at
+		FROM schema_migrations
+		GROUP BY version`); err != nil {
+		return nil, nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	applied := make(map[uint32]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return migrations, applied, nil
+}
+
+func (ch *ClickhouseClient) record(ctx context.Context, m migration, applied bool) error {
+	if err := ch.conn.Exec(ctx, `INSERT INTO schema_migrations (
+			version,
+			name,
+			checksum,
+			applied
+		) VALUES (?, ?, ?, ?)`, m.version, m.name, m.checksum, applied); err != nil {
+		return fmt.Errorf("failed to record migration %03d_%s: %w", m.version, m.name, err)
+	}
+
+	return nil
+}
+
+// execScript runs the statements of the file one by one,
+// clickhouse doesn't accept several of them at once
+func (ch *ClickhouseClient) execScript(ctx context.Context, script string) error {
+	for _, statement := range splitStatements(script) {
+		if err := ch.conn.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verify fails if an applied migration was edited or removed
+func verify(migrations []migration, applied map[uint32]appliedMigration) error {
+	known := make(map[uint32]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.version] = true
+
+		state := applied[m.version]
+		if state.Applied && state.Checksum != m.checksum {
+			return fmt.Errorf("%w: %03d_%s", customerrors.ErrEditedMigration, m.version, m.name)
+		}
+	}
+
+	for version, state := range applied {
+		if state.Applied && !known[version] {
+			return fmt.Errorf("%w: %03d_%s", customerrors.ErrMissingMigration, version, state.Name)
+		}
+	}
+
+	return nil
+}
+
+func loadMigrations() ([]migration, error) {
+	files, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[uint32]*migration)
+	for _, file := range files {
+		base, direction, ok := cutDirection(file.Name())
+		if !ok {
+			return nil, fmt.Errorf("invalid migration name: %s", file.Name())
+		}
+
+		rawVersion, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration name: %s", file.Name())
+		}
+		version, err := strconv.ParseUint(rawVersion, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version: %s", file.Name())
+		}
+
+		content, err := migrationsFS.ReadFile("migrations/" + file.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file: %w", err)
+		}
+
+		m, ok := byVersion[uint32(version)]
+		if !ok {
+			m = &migration{
+				version: uint32(version),
+				name:    name,
+			}
+			byVersion[m.version] = m
+		}
+		if m.name != name {
+			return nil, fmt.Errorf("duplicate migration version: %03d", version)
+		}
+
+		if direction == "up" {
+			hash := sha256.Sum256(content)
+			m.up = string(content)
+			m.checksum = hex.EncodeToString(hash[:])
+		} else {
+			m.down = string(content)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.checksum == "" {
+			return nil, fmt.Errorf("migration %03d_%s has no up migration", m.version, m.name)
+		}
+
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+func cutDirection(filename string) (string, string, bool) {
+	if base, ok := strings.CutSuffix(filename, ".up.sql"); ok {
+		return base, "up", true
+	}
+	if base, ok := strings.CutSuffix(filename, ".down.sql"); ok {
+		return base, "down", true
+	}
+
+	return "", "", false
+}
+
+// splitStatements splits the script by the lines ending with ';'
+func splitStatements(script string) []string {
+	var statements []string
+
+	var statement strings.Builder
+	for line := range strings.Lines(script) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		statement.WriteString(line)
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(statement.String()), ";"))
+			statement.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(statement.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
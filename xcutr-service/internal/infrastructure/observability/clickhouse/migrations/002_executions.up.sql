@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS executions (
+    id UUID,
+    user_id String,
+    language LowCardinality(String),
+    status LowCardinality(String),
+    exit_code Int32,
+    started_at DateTime64(3),
+    duration_ms UInt64,
+    file_names Array(String),
+    file_mimes Array(String),
+    file_hashes Array(String),
+    file_bodies Array(String),
+    source_truncated Bool,
+    output String,
+    output_truncated Bool
+) ENGINE = MergeTree()
+PARTITION BY toYYYYMM(started_at)
+ORDER BY (user_id, started_at, id)
+TTL toDateTime(started_at) + INTERVAL 30 DAY;
@@ -0,0 +1,10 @@
+ALTER TABLE user_services
+    DROP COLUMN IF EXISTS execution_id,
+    DROP COLUMN IF EXISTS duration_ms,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS exit_code,
+    DROP COLUMN IF EXISTS timed_out,
+    DROP COLUMN IF EXISTS oom_killed,
+    DROP COLUMN IF EXISTS output_bytes,
+    DROP COLUMN IF EXISTS image,
+    DROP COLUMN IF EXISTS executor;
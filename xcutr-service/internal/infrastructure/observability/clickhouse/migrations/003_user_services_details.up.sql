@@ -0,0 +1,10 @@
+ALTER TABLE user_services
+    ADD COLUMN IF NOT EXISTS execution_id UUID,
+    ADD COLUMN IF NOT EXISTS duration_ms UInt64,
+    ADD COLUMN IF NOT EXISTS status LowCardinality(String),
+    ADD COLUMN IF NOT EXISTS exit_code Int32 DEFAULT -1,
+    ADD COLUMN IF NOT EXISTS timed_out Bool,
+    ADD COLUMN IF NOT EXISTS oom_killed Bool,
+    ADD COLUMN IF NOT EXISTS output_bytes UInt64,
+    ADD COLUMN IF NOT EXISTS image String,
+    ADD COLUMN IF NOT EXISTS executor LowCardinality(String);
@@ -0,0 +1,141 @@
+package clickhouse
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"testing"
+
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+)
+
+func TestSplitStatements(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Script string
+		Want   []string
+	}{
+		{Name: "empty", Script: "", Want: nil},
+		{Name: "single", Script: "SELECT 1;\n", Want: []string{"SELECT 1"}},
+		{Name: "no_semicolon", Script: "SELECT 1\n", Want: []string{"SELECT 1"}},
+		{Name: "several", Script: "SELECT 1;\nSELECT 2;\n", Want: []string{"SELECT 1", "SELECT 2"}},
+		{
+			Name:   "multiline",
+			Script: "CREATE TABLE t (\n\tid UInt32\n) ENGINE = Memory;\n\nDROP TABLE t;",
+			Want:   []string{"CREATE TABLE t (\n\tid UInt32\n) ENGINE = Memory", "DROP TABLE t"},
+		},
+		{
+			Name:   "comments",
+			Script: "-- the table\nSELECT 1;\n  -- indented\nSELECT 2;\n",
+			Want:   []string{"SELECT 1", "SELECT 2"},
+		},
+		// Only the lines ending with ';' end the statement
+		{Name: "semicolon_inside", Script: "SELECT ';' AS s,\n1;\n", Want: []string{"SELECT ';' AS s,\n1"}},
+		{Name: "only_comments", Script: "-- nothing\n\n", Want: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := splitStatements(tc.Script); !slices.Equal(got, tc.Want) {
+				t.Errorf("got %q, want %q", got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestCutDirection(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Filename      string
+		WantBase      string
+		WantDirection string
+		WantOK        bool
+	}{
+		{Name: "up", Filename: "001_logs.up.sql", WantBase: "001_logs", WantDirection: "up", WantOK: true},
+		{Name: "down", Filename: "001_logs.down.sql", WantBase: "001_logs", WantDirection: "down", WantOK: true},
+		{Name: "no_direction", Filename: "001_logs.sql", WantOK: false},
+		{Name: "other_file", Filename: "README.md", WantOK: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			base, direction, ok := cutDirection(tc.Filename)
+			if ok != tc.WantOK || base != tc.WantBase || direction != tc.WantDirection {
+				t.Errorf("got (%q, %q, %v), want (%q, %q, %v)", base, direction, ok, tc.WantBase, tc.WantDirection, tc.WantOK)
+			}
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatalf("got no migrations")
+	}
+
+	for i, m := range migrations {
+		if i > 0 && m.version <= migrations[i-1].version {
+			t.Errorf("got version %d after %d, want ascending", m.version, migrations[i-1].version)
+		}
+		if m.name == "" || m.up == "" || m.down == "" {
+			t.Errorf("migration %03d_%s is incomplete", m.version, m.name)
+		}
+
+		hash := sha256.Sum256([]byte(m.up))
+		if want := hex.EncodeToString(hash[:]); m.checksum != want {
+			t.Errorf("got checksum %s, want %s", m.checksum, want)
+		}
+		if len(splitStatements(m.up)) == 0 {
+			t.Errorf("migration %03d_%s has no statements", m.version, m.name)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	migrations := []migration{
+		{version: 1, name: "logs", checksum: "aaa"},
+		{version: 2, name: "executions", checksum: "bbb"},
+	}
+
+	testCases := []struct {
+		Name    string
+		Applied map[uint32]appliedMigration
+		WantErr error
+	}{
+		{Name: "none_applied", Applied: map[uint32]appliedMigration{}, WantErr: nil},
+		{Name: "applied", Applied: map[uint32]appliedMigration{
+			1: {Version: 1, Name: "logs", Checksum: "aaa", Applied: true},
+		}, WantErr: nil},
+		{Name: "edited", Applied: map[uint32]appliedMigration{
+			1: {Version: 1, Name: "logs", Checksum: "changed", Applied: true},
+		}, WantErr: customerrors.ErrEditedMigration},
+		// The rolled back one may be changed before it's applied again
+		{Name: "edited_rolled_back", Applied: map[uint32]appliedMigration{
+			2: {Version: 2, Name: "executions", Checksum: "changed", Applied: false},
+		}, WantErr: nil},
+		{Name: "missing", Applied: map[uint32]appliedMigration{
+			3: {Version: 3, Name: "removed", Checksum: "ccc", Applied: true},
+		}, WantErr: customerrors.ErrMissingMigration},
+		{Name: "missing_rolled_back", Applied: map[uint32]appliedMigration{
+			3: {Version: 3, Name: "removed", Checksum: "ccc", Applied: false},
+		}, WantErr: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := verify(migrations, tc.Applied); !errors.Is(err, tc.WantErr) {
+				t.Errorf("got %v, want %v", err, tc.WantErr)
+			}
+		})
+	}
+}
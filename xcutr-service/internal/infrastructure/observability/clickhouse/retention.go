@@ -0,0 +1,39 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SetRetention replaces the TTL of the table written by the migrations.
+// The TTL is changed only if it differs, since clickhouse rewrites
+// the parts of the table on every change
+func (ch *ClickhouseClient) SetRetention(ctx context.Context, table, column string, retention time.Duration) error {
+	seconds := int64(retention / time.Second)
+	if seconds <= 0 {
+		return fmt.Errorf("invalid retention of %s: %s", table, retention)
+	}
+
+	var engine string
+	if err := ch.conn.QueryRow(ctx, `SELECT engine_full
+		FROM system.tables
+		WHERE database = currentDatabase() AND name = ?`, table).Scan(&engine); err != nil {
+		return fmt.Errorf("failed to get engine of %s: %w", table, err)
+	}
+
+	if strings.Contains(engine, fmt.Sprintf("toIntervalSecond(%d)", seconds)) {
+		return nil
+	}
+
+	// The names can't be bound, they come from the code only
+	if err := ch.conn.Exec(ctx, fmt.Sprintf(
+		"ALTER TABLE %s MODIFY TTL toDateTime(%s) + toIntervalSecond(%d)",
+		table, column, seconds,
+	)); err != nil {
+		return fmt.Errorf("failed to set retention of %s: %w", table, err)
+	}
+
+	return nil
+}
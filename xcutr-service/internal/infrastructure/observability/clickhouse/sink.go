@@ -0,0 +1,519 @@
+package clickhouse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devathh/coderun/xcutr-service/internal/domain/observability"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/metrics"
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+)
+
+const (
+	// Time of one insert into clickhouse
+	writeTimeout = 10 * time.Second
+	maxBackoff   = 30 * time.Second
+)
+
+// EventSink collects the events n' writes them to clickhouse in batches
+type EventSink struct {
+	cfg    *config.Config
+	log    *slog.Logger
+	client observability.ClickhouseClient
+
+	mu      sync.RWMutex
+	closed  bool
+	events  chan *observability.ExecutionEvent
+	dropped atomic.Uint64
+
+	// Guards the spill file, which is rewritten on erasure
+	spillMu sync.Mutex
+	erased  *observability.ErasedUsers
+	// The spilled events are replayed after a batch is written,
+	// or at that time while no events come. Used by run only
+	nextReplay    time.Time
+	replayBackoff time.Duration
+
+	// Canceled when the shutdown can't wait anymore,
+	// then the unwritten events go to the spill file
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewEventSink(cfg *config.Config, log *slog.Logger, client observability.ClickhouseClient) (*EventSink, error) {
+	if cfg == nil || log == nil || client == nil {
+		return nil, customerrors.ErrNilArgs
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	es := &EventSink{
+		cfg:    cfg,
+		log:    log,
+		client: client,
+		events: make(chan *observability.ExecutionEvent, cfg.Service.Analytics.Clickhouse.BufferSize),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		erased: observability.NewErasedUsers(),
+	}
+	go es.run()
+
+	return es, nil
+}
+
+func (es *EventSink) Write(event *observability.ExecutionEvent) {
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+
+	if es.closed {
+		es.dropped.Add(1)
+		metrics.AnalyticsDropped.Inc()
+		return
+	}
+
+	select {
+	case es.events <- event:
+		metrics.AnalyticsQueue.Inc()
+	default:
+		es.dropped.Add(1)
+		metrics.AnalyticsDropped.Inc()
+	}
+}
+
+func (es *EventSink) Close(ctx context.Context) error {
+	es.mu.Lock()
+	if !es.closed {
+		es.closed = true
+		close(es.events)
+	}
+	es.mu.Unlock()
+
+	select {
+	case <-es.done:
+		es.cancel()
+		return nil
+	case <-ctx.Done():
+		es.cancel()
+		<-es.done
+		return ctx.Err()
+	}
+}
+
+func (es *EventSink) EraseUser(ctx context.Context, userID string) error {
+	es.erased.Erase(userID)
+
+	if path := es.cfg.Service.Analytics.Clickhouse.SpillPath; path != "" {
+		es.spillMu.Lock()
+		err := filterEvents(path, userID)
+		es.spillMu.Unlock()
+		if err != nil {
+			return fmt.Errorf("failed to erase user from spill file: %w", err)
+		}
+	}
+
+	return es.client.DeleteUser(ctx, userID)
+}
+
+func (es *EventSink) run() {
+	defer close(es.done)
+
+	ticker := time.NewTicker(es.cfg.Service.Analytics.Clickhouse.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*observability.ExecutionEvent, 0, es.cfg.Service.Analytics.Clickhouse.BatchSize)
+	for {
+		select {
+		case event, ok := <-es.events:
+			if !ok {
+				es.flush(batch)
+				return
+			}
+			metrics.AnalyticsQueue.Dec()
+
+			batch = append(batch, event)
+			if len(batch) >= es.cfg.Service.Analytics.Clickhouse.BatchSize {
+				es.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			es.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush writes the batch n' then the spilled events, if clickhouse is fine
+func (es *EventSink) flush(batch []*observability.ExecutionEvent) {
+	if dropped := es.dropped.Swap(0); dropped > 0 {
+		es.log.Warn("analytics buffer is full, events are dropped", slog.Uint64("count", dropped))
+	}
+
+	batch = es.withoutErased(batch)
+	if len(batch) > 0 {
+		if err := es.insert(batch); err != nil {
+			es.log.Warn("failed to write events into clickhouse", slog.Int("count", len(batch)), slog.String("error", err.Error()))
+			es.spill(batch)
+			return
+		}
+	} else if time.Now().Before(es.nextReplay) {
+		return
+	}
+
+	if err := es.replay(); err != nil {
+		es.log.Warn("failed to write spilled events into clickhouse", slog.String("error", err.Error()))
+
+		es.replayBackoff = min(max(es.replayBackoff*2, es.cfg.Service.Analytics.Clickhouse.RetryBackoff), maxBackoff)
+		es.nextReplay = time.Now().Add(es.replayBackoff)
+		return
+	}
+	es.replayBackoff = 0
+}
+
+func (es *EventSink) withoutErased(batch []*observability.ExecutionEvent) []*observability.ExecutionEvent {
+	kept := batch[:0]
+	for _, event := range batch {
+		if es.erased.Erased(event.UserID, event.Timestamp) {
+			continue
+		}
+		kept = append(kept, event)
+	}
+
+	return kept
+}
+
+// insert writes the batch, retrying with backoff
+func (es *EventSink) insert(batch []*observability.ExecutionEvent) error {
+	backoff := es.cfg.Service.Analytics.Clickhouse.RetryBackoff
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(es.ctx, writeTimeout)
+		err = es.client.WriteEvents(ctx, batch)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= es.cfg.Service.Analytics.Clickhouse.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-es.ctx.Done():
+			return err
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// spill appends the events to the spill file, one json per line.
+// The oldest events are dropped to keep the file under its max size
+func (es *EventSink) spill(batch []*observability.ExecutionEvent) {
+	path := es.cfg.Service.Analytics.Clickhouse.SpillPath
+	if path == "" {
+		es.log.Error("events are lost, spill file isn't set", slog.Int("count", len(batch)))
+		return
+	}
+
+	es.spillMu.Lock()
+	defer es.spillMu.Unlock()
+
+	dropped, err := appendEvents(path, batch, es.cfg.Service.Analytics.Clickhouse.SpillMaxSize)
+	if dropped > 0 {
+		metrics.AnalyticsSpillDropped.Add(float64(dropped))
+		es.log.Warn("spill file is full, the oldest events are dropped", slog.Int("count", dropped))
+	}
+	if err != nil {
+		es.log.Error("events are lost, failed to spill them",
+			slog.Int("count", len(batch)),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	es.log.Info("events are spilled", slog.Int("count", len(batch)), slog.String("path", path))
+}
+
+// replay writes the spilled events batch by batch, reading the file as it goes.
+// The ones that weren't written stay in the file
+func (es *EventSink) replay() error {
+	path := es.cfg.Service.Analytics.Clickhouse.SpillPath
+	if path == "" {
+		return nil
+	}
+
+	es.spillMu.Lock()
+	defer es.spillMu.Unlock()
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(file)
+	var written, offset int64
+	for {
+		batch, size, err := readBatch(reader, es.cfg.Service.Analytics.Clickhouse.BatchSize)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		if size == 0 {
+			break
+		}
+
+		if len(batch) > 0 {
+			ctx, cancel := context.WithTimeout(es.ctx, writeTimeout)
+			err = es.client.WriteEvents(ctx, batch)
+			cancel()
+			if err != nil {
+				file.Close()
+
+				// Only the written part is cut off, nothing is rewritten if it's empty
+				if offset > 0 {
+					if err := rewriteFrom(path, offset); err != nil {
+						return fmt.Errorf("failed to cut spill file: %w", err)
+					}
+					es.log.Info("spilled events are written partly", slog.Int64("count", written))
+				}
+
+				return err
+			}
+		}
+
+		written += int64(len(batch))
+		offset += size
+	}
+	file.Close()
+
+	if written > 0 {
+		es.log.Info("spilled events are written", slog.Int64("count", written))
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove spill file: %w", err)
+	}
+
+	return nil
+}
+
+// readBatch reads up to size events n' returns them with the bytes read.
+// The broken lines are skipped, the tail may be cut by a crash in the middle of spilling
+func readBatch(reader *bufio.Reader, size int) ([]*observability.ExecutionEvent, int64, error) {
+	var (
+		batch []*observability.ExecutionEvent
+		read  int64
+	)
+	for len(batch) < size {
+		line, err := reader.ReadBytes('\n')
+		read += int64(len(line))
+		if len(line) > 0 {
+			var event observability.ExecutionEvent
+			if json.Unmarshal(line, &event) == nil {
+				batch = append(batch, &event)
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return batch, read, nil
+}
+
+// filterEvents removes the events of the user from the file
+func filterEvents(path, userID string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	tmp, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	writer := bufio.NewWriter(tmp)
+
+	reader := bufio.NewReader(file)
+	var filtered, kept int
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var event observability.ExecutionEvent
+			if json.Unmarshal(line, &event) == nil && event.UserID == userID {
+				filtered++
+			} else if _, err := writer.Write(line); err != nil {
+				tmp.Close()
+				return err
+			} else {
+				kept++
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if filtered == 0 {
+		return nil
+	}
+	if kept == 0 {
+		return os.Remove(path)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// appendEvents appends the events n' drops the oldest ones above maxSize.
+// Returns the number of the dropped events
+func appendEvents(path string, events []*observability.ExecutionEvent, maxSize int64) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+
+	lines := make([][]byte, 0, len(events))
+	var size int64
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return 0, err
+		}
+		lines = append(lines, append(line, '\n'))
+		size += int64(len(line)) + 1
+	}
+
+	// The batch alone is over the max, its oldest events go too
+	dropped := 0
+	for len(lines) > 0 && size > maxSize {
+		size -= int64(len(lines[0]))
+		lines = lines[1:]
+		dropped++
+	}
+
+	info, err := os.Stat(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return dropped, err
+	}
+	if info != nil && info.Size()+size > maxSize {
+		cut, err := trimEvents(path, maxSize-size)
+		dropped += cut
+		if err != nil {
+			return dropped, err
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return dropped, err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, line := range lines {
+		if _, err := writer.Write(line); err != nil {
+			return dropped, err
+		}
+	}
+
+	return dropped, writer.Flush()
+}
+
+// trimEvents drops the oldest lines of the file until at most keep bytes are left.
+// Returns the number of the dropped lines
+func trimEvents(path string, keep int64) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return 0, err
+	}
+
+	reader := bufio.NewReader(file)
+	left := info.Size()
+	var offset int64
+	dropped := 0
+	for left > keep {
+		line, err := reader.ReadBytes('\n')
+		offset += int64(len(line))
+		left -= int64(len(line))
+		if len(line) > 0 {
+			dropped++
+		}
+		if err != nil {
+			break
+		}
+	}
+	file.Close()
+
+	return dropped, rewriteFrom(path, offset)
+}
+
+// rewriteFrom replaces the file with its part from the offset, without loading it
+func rewriteFrom(path string, offset int64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, file); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
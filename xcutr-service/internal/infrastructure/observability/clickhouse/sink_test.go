@@ -0,0 +1,370 @@
+package clickhouse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devathh/coderun/xcutr-service/internal/domain/observability"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
+	"github.com/google/uuid"
+)
+
+var errUnavailable = errors.New("clickhouse is unavailable")
+
+// fakeClient accepts the given number of writes, then fails. Negative accepts all
+type fakeClient struct {
+	observability.ClickhouseClient
+
+	mu      sync.Mutex
+	accepts int
+	calls   int
+	written []*observability.ExecutionEvent
+	deleted []string
+}
+
+func (fc *fakeClient) WriteEvents(_ context.Context, events []*observability.ExecutionEvent) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.calls++
+	if fc.accepts == 0 {
+		return errUnavailable
+	}
+	fc.accepts--
+
+	fc.written = append(fc.written, events...)
+	return nil
+}
+
+func (fc *fakeClient) DeleteUser(_ context.Context, userID string) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.deleted = append(fc.deleted, userID)
+	return nil
+}
+
+func (fc *fakeClient) users() []string {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	users := make([]string, 0, len(fc.written))
+	for _, event := range fc.written {
+		users = append(users, event.UserID)
+	}
+
+	return users
+}
+
+func newSinkConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Service.Analytics.Clickhouse.BufferSize = 100
+	cfg.Service.Analytics.Clickhouse.BatchSize = 2
+	// The events are flushed by the close only
+	cfg.Service.Analytics.Clickhouse.FlushInterval = time.Hour
+	cfg.Service.Analytics.Clickhouse.MaxRetries = 0
+	cfg.Service.Analytics.Clickhouse.RetryBackoff = time.Millisecond
+	cfg.Service.Analytics.Clickhouse.SpillPath = filepath.Join(t.TempDir(), "spill", "events.jsonl")
+	cfg.Service.Analytics.Clickhouse.SpillMaxSize = 1 << 20
+
+	return cfg
+}
+
+// runSink writes the events through a new sink n' closes it
+func runSink(t *testing.T, cfg *config.Config, client *fakeClient, users ...string) {
+	t.Helper()
+
+	sink, err := NewEventSink(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), client)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+
+	for _, user := range users {
+		sink.Write(&observability.ExecutionEvent{
+			Timestamp:   time.Now(),
+			ExecutionID: uuid.New(),
+			UserID:      user,
+		})
+	}
+
+	if err := sink.Close(t.Context()); err != nil {
+		t.Fatalf("failed to close sink: %v", err)
+	}
+}
+
+func spilledUsers(t *testing.T, path string) []string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}
+	}
+	if err != nil {
+		t.Fatalf("failed to open spill file: %v", err)
+	}
+	defer file.Close()
+
+	events, _, err := readBatch(bufio.NewReader(file), math.MaxInt32)
+	if err != nil {
+		t.Fatalf("failed to read spill file: %v", err)
+	}
+
+	users := []string{}
+	for _, event := range events {
+		users = append(users, event.UserID)
+	}
+
+	return users
+}
+
+func TestEventSinkSpill(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Accepts     int
+		Users       []string
+		WantWritten []string
+		WantSpilled []string
+	}{
+		{Name: "written", Accepts: -1, Users: []string{"a", "b", "c"}, WantWritten: []string{"a", "b", "c"}, WantSpilled: []string{}},
+		{Name: "spilled", Accepts: 0, Users: []string{"a", "b", "c"}, WantWritten: []string{}, WantSpilled: []string{"a", "b", "c"}},
+		// The first batch is written, the last one is spilled
+		{Name: "partly_spilled", Accepts: 1, Users: []string{"a", "b", "c"}, WantWritten: []string{"a", "b"}, WantSpilled: []string{"c"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newSinkConfig(t)
+			client := &fakeClient{accepts: tc.Accepts}
+			runSink(t, cfg, client, tc.Users...)
+
+			if got := client.users(); !slices.Equal(got, tc.WantWritten) {
+				t.Errorf("got written %v, want %v", got, tc.WantWritten)
+			}
+			if got := spilledUsers(t, cfg.Service.Analytics.Clickhouse.SpillPath); !slices.Equal(got, tc.WantSpilled) {
+				t.Errorf("got spilled %v, want %v", got, tc.WantSpilled)
+			}
+		})
+	}
+}
+
+func TestEventSinkReplay(t *testing.T) {
+	testCases := []struct {
+		Name string
+		// Writes accepted after the restart, by batch of 2
+		Accepts     int
+		Spilled     []string
+		WantWritten []string
+		WantSpilled []string
+	}{
+		{Name: "all", Accepts: -1, Spilled: []string{"a", "b", "c"}, WantWritten: []string{"a", "b", "c"}, WantSpilled: []string{}},
+		{Name: "partly", Accepts: 1, Spilled: []string{"a", "b", "c"}, WantWritten: []string{"a", "b"}, WantSpilled: []string{"c"}},
+		{Name: "none", Accepts: 0, Spilled: []string{"a", "b", "c"}, WantWritten: []string{}, WantSpilled: []string{"a", "b", "c"}},
+		{Name: "nothing_spilled", Accepts: -1, Spilled: nil, WantWritten: []string{}, WantSpilled: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := newSinkConfig(t)
+			runSink(t, cfg, &fakeClient{accepts: 0}, tc.Spilled...)
+
+			// Clickhouse is back after the restart
+			client := &fakeClient{accepts: tc.Accepts}
+			runSink(t, cfg, client)
+
+			if got := client.users(); !slices.Equal(got, tc.WantWritten) {
+				t.Errorf("got written %v, want %v", got, tc.WantWritten)
+			}
+			if got := spilledUsers(t, cfg.Service.Analytics.Clickhouse.SpillPath); !slices.Equal(got, tc.WantSpilled) {
+				t.Errorf("got spilled %v, want %v", got, tc.WantSpilled)
+			}
+		})
+	}
+}
+
+func TestEventSinkEraseUser(t *testing.T) {
+	cfg := newSinkConfig(t)
+	runSink(t, cfg, &fakeClient{accepts: 0}, "erased", "other", "erased")
+
+	client := &fakeClient{accepts: -1}
+	sink, err := NewEventSink(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), client)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+
+	// The event of the execution that ended before the erasure is on the way
+	late := &observability.ExecutionEvent{Timestamp: time.Now(), ExecutionID: uuid.New(), UserID: "erased"}
+
+	if err := sink.EraseUser(t.Context(), "erased"); err != nil {
+		t.Fatalf("failed to erase user: %v", err)
+	}
+	sink.Write(late)
+	sink.Write(&observability.ExecutionEvent{Timestamp: time.Now(), ExecutionID: uuid.New(), UserID: "other"})
+
+	if got := spilledUsers(t, cfg.Service.Analytics.Clickhouse.SpillPath); !slices.Equal(got, []string{"other"}) {
+		t.Errorf("got spilled %v, want %v", got, []string{"other"})
+	}
+
+	if err := sink.Close(t.Context()); err != nil {
+		t.Fatalf("failed to close sink: %v", err)
+	}
+
+	if !slices.Equal(client.deleted, []string{"erased"}) {
+		t.Errorf("got deleted %v, want %v", client.deleted, []string{"erased"})
+	}
+	if got := client.users(); !slices.Equal(got, []string{"other", "other"}) {
+		t.Errorf("got written %v, want %v", got, []string{"other", "other"})
+	}
+}
+
+// newIdleSink is a sink without its run loop, the test calls flush itself
+func newIdleSink(cfg *config.Config, client *fakeClient) *EventSink {
+	return &EventSink{
+		cfg:    cfg,
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		client: client,
+		ctx:    context.Background(),
+		erased: observability.NewErasedUsers(),
+	}
+}
+
+func events(users ...string) []*observability.ExecutionEvent {
+	result := make([]*observability.ExecutionEvent, 0, len(users))
+	for _, user := range users {
+		result = append(result, &observability.ExecutionEvent{Timestamp: time.Now(), ExecutionID: uuid.New(), UserID: user})
+	}
+
+	return result
+}
+
+func TestEventSinkReplayBackoff(t *testing.T) {
+	cfg := newSinkConfig(t)
+	cfg.Service.Analytics.Clickhouse.RetryBackoff = time.Hour
+	client := &fakeClient{accepts: 0}
+	sink := newIdleSink(cfg, client)
+
+	sink.flush(events("a", "b"))
+	// The failed batch is spilled without replaying the file
+	if client.calls != 1 {
+		t.Fatalf("got %d writes, want 1", client.calls)
+	}
+
+	// The first empty tick tries the file, the next ones wait for the backoff
+	for range 5 {
+		sink.flush(nil)
+	}
+	if client.calls != 2 {
+		t.Errorf("got %d writes, want 2", client.calls)
+	}
+
+	// A written batch shows clickhouse is back, the file is replayed right away
+	client.accepts = -1
+	sink.flush(events("c"))
+	if got := client.users(); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Errorf("got written %v, want %v", got, []string{"c", "a", "b"})
+	}
+	if got := spilledUsers(t, cfg.Service.Analytics.Clickhouse.SpillPath); len(got) != 0 {
+		t.Errorf("got spilled %v, want none", got)
+	}
+}
+
+func TestEventSinkReplayCut(t *testing.T) {
+	cfg := newSinkConfig(t)
+	path := cfg.Service.Analytics.Clickhouse.SpillPath
+	if _, err := appendEvents(path, events("a", "b", "c", "d", "e"), 1<<20); err != nil {
+		t.Fatalf("failed to spill events: %v", err)
+	}
+	// The tail cut by a crash is skipped
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("failed to open spill file: %v", err)
+	}
+	file.WriteString(`{"user_id":"f"`)
+	file.Close()
+
+	// The second batch fails, only the first one is cut off the file
+	client := &fakeClient{accepts: 1}
+	if err := newIdleSink(cfg, client).replay(); !errors.Is(err, errUnavailable) {
+		t.Fatalf("got %v, want %v", err, errUnavailable)
+	}
+	if got := spilledUsers(t, path); !slices.Equal(got, []string{"c", "d", "e"}) {
+		t.Errorf("got spilled %v, want %v", got, []string{"c", "d", "e"})
+	}
+
+	client.accepts = -1
+	if err := newIdleSink(cfg, client).replay(); err != nil {
+		t.Fatalf("failed to replay: %v", err)
+	}
+	if got := client.users(); !slices.Equal(got, []string{"a", "b", "c", "d", "e"}) {
+		t.Errorf("got written %v, want %v", got, []string{"a", "b", "c", "d", "e"})
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want no spill file", err)
+	}
+}
+
+func TestAppendEventsMaxSize(t *testing.T) {
+	line, err := json.Marshal(events("a")[0])
+	if err != nil {
+		t.Fatalf("failed to encode event: %v", err)
+	}
+	// The lines differ by a few bytes of the timestamp
+	size := int64(len(line)) + 1
+
+	testCases := []struct {
+		Name        string
+		Spilled     []string
+		Batch       []string
+		MaxEvents   int64
+		WantDropped int
+		WantSpilled []string
+	}{
+		{Name: "under_max", Spilled: []string{"a"}, Batch: []string{"b"}, MaxEvents: 3, WantDropped: 0, WantSpilled: []string{"a", "b"}},
+		{Name: "oldest_dropped", Spilled: []string{"a", "b", "c"}, Batch: []string{"d", "e"}, MaxEvents: 3, WantDropped: 2, WantSpilled: []string{"c", "d", "e"}},
+		{Name: "file_dropped", Spilled: []string{"a", "b"}, Batch: []string{"c", "d", "e"}, MaxEvents: 3, WantDropped: 2, WantSpilled: []string{"c", "d", "e"}},
+		// The batch alone is over the max
+		{Name: "batch_cut", Spilled: []string{"a"}, Batch: []string{"b", "c", "d", "e"}, MaxEvents: 3, WantDropped: 2, WantSpilled: []string{"c", "d", "e"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "events.jsonl")
+			maxSize := size*tc.MaxEvents + size/2
+			if _, err := appendEvents(path, events(tc.Spilled...), maxSize); err != nil {
+				t.Fatalf("failed to spill events: %v", err)
+			}
+
+			dropped, err := appendEvents(path, events(tc.Batch...), maxSize)
+			if err != nil {
+				t.Fatalf("failed to spill events: %v", err)
+			}
+			if dropped != tc.WantDropped {
+				t.Errorf("got %d dropped, want %d", dropped, tc.WantDropped)
+			}
+			if got := spilledUsers(t, path); !slices.Equal(got, tc.WantSpilled) {
+				t.Errorf("got spilled %v, want %v", got, tc.WantSpilled)
+			}
+			if info, err := os.Stat(path); err != nil || info.Size() > maxSize {
+				t.Errorf("got spill file of %v, want at most %d bytes", info, maxSize)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/devathh/coderun/xcutr-service/internal/domain/observability"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+)
+
+type eventModel struct {
+	Timestamp   time.Time `json:"timestamp"`
+	ExecutionID string    `json:"execution_id"`
+	UserID      string    `json:"user_id"`
+	Language    string    `json:"language"`
+	DurationMS  int64     `json:"duration_ms"`
+	Status      string    `json:"status"`
+	ExitCode    int       `json:"exit_code"`
+	TimedOut    bool      `json:"timed_out"`
+	OOMKilled   bool      `json:"oom_killed"`
+	OutputBytes uint64    `json:"output_bytes"`
+	Image       string    `json:"image"`
+	Executor    string    `json:"executor"`
+}
+
+// JSONL appends the events to a file, one json per line.
+// The file is rotated when it exceeds the max size
+type JSONL struct {
+	cfg *config.Config
+	log *slog.Logger
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+	erased *observability.ErasedUsers
+}
+
+func NewJSONL(cfg *config.Config, log *slog.Logger) (*JSONL, error) {
+	if cfg == nil || log == nil {
+		return nil, customerrors.ErrNilArgs
+	}
+
+	j := &JSONL{
+		cfg:    cfg,
+		log:    log,
+		erased: observability.NewErasedUsers(),
+	}
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+func (j *JSONL) Write(event *observability.ExecutionEvent) {
+	if j.erased.Erased(event.UserID, event.Timestamp) {
+		return
+	}
+
+	line, err := json.Marshal(eventModel{
+		Timestamp:   event.Timestamp,
+		ExecutionID: event.ExecutionID.String(),
+		UserID:      event.UserID,
+		Language:    event.Language,
+		DurationMS:  event.Duration.Milliseconds(),
+		Status:      event.Status,
+		ExitCode:    event.ExitCode,
+		TimedOut:    event.TimedOut,
+		OOMKilled:   event.OOMKilled,
+		OutputBytes: event.OutputBytes,
+		Image:       event.Image,
+		Executor:    event.Executor,
+	})
+	if err != nil {
+		j.log.Warn("failed to encode event", slog.String("error", err.Error()))
+		return
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return
+	}
+
+	if j.size > 0 && j.size+int64(len(line)) > j.cfg.Service.Analytics.JSONL.MaxSize {
+		if err := j.rotate(); err != nil {
+			j.log.Warn("failed to rotate events file", slog.String("error", err.Error()))
+		}
+	}
+
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+	if err != nil {
+		j.log.Warn("failed to write event into file", slog.String("error", err.Error()))
+	}
+}
+
+// EraseUser rewrites the file n' its backups without the events of the user.
+// The events of the user that come later are dropped for a while
+func (j *JSONL) EraseUser(_ context.Context, userID string) error {
+	j.erased.Erase(userID)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	path := j.cfg.Service.Analytics.JSONL.Path
+	for i := 1; i <= j.cfg.Service.Analytics.JSONL.MaxBackups; i++ {
+		if err := eraseLines(backupName(path, i), userID); err != nil {
+			return fmt.Errorf("failed to erase user from events file: %w", err)
+		}
+	}
+
+	// The file is replaced by a new one, the open one is untouched on failure
+	if err := eraseLines(path, userID); err != nil {
+		return fmt.Errorf("failed to erase user from events file: %w", err)
+	}
+	if j.closed {
+		return nil
+	}
+
+	return j.reopen()
+}
+
+func (j *JSONL) Close(context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return nil
+	}
+	j.closed = true
+
+	if err := j.file.Close(); err != nil {
+		return fmt.Errorf("failed to close events file: %w", err)
+	}
+
+	return nil
+}
+
+func (j *JSONL) open() error {
+	path := j.cfg.Service.Analytics.JSONL.Path
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create events dir: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open events file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat events file: %w", err)
+	}
+
+	j.file = file
+	j.size = info.Size()
+
+	return nil
+}
+
+// reopen switches the writes to the file at the path. The old file
+// is kept on failure, so the writes go on
+func (j *JSONL) reopen() error {
+	old := j.file
+	if err := j.open(); err != nil {
+		return err
+	}
+
+	return old.Close()
+}
+
+// rotate shifts path.N to path.N+1, dropping the ones over max backups,
+// n' starts a new file. Without backups the file is just truncated
+func (j *JSONL) rotate() error {
+	path := j.cfg.Service.Analytics.JSONL.Path
+	backups := j.cfg.Service.Analytics.JSONL.MaxBackups
+
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+
+	if backups == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else {
+		if err := os.Remove(backupName(path, backups)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for i := backups - 1; i >= 1; i-- {
+			if err := os.Rename(backupName(path, i), backupName(path, i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(path, backupName(path, 1)); err != nil {
+			return err
+		}
+	}
+
+	return j.open()
+}
+
+func backupName(path string, i int) string {
+	return path + "." + strconv.Itoa(i)
+}
+
+// eraseLines replaces the file with its lines of the other users
+func eraseLines(path, userID string) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	kept := make([]byte, 0, len(content))
+	for line := range bytes.Lines(content) {
+		var event struct {
+			UserID string `json:"user_id"`
+		}
+		// Broken lines are kept, they can't be attributed to anyone
+		if err := json.Unmarshal(line, &event); err == nil && event.UserID == userID {
+			continue
+		}
+		kept = append(kept, line...)
+	}
+	if len(kept) == len(content) {
+		return nil
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, kept, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,244 @@
+package sinks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devathh/coderun/xcutr-service/internal/domain/observability"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
+	"github.com/google/uuid"
+)
+
+func newJSONL(t *testing.T, maxSize int64, maxBackups int) (*JSONL, string) {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Service.Analytics.JSONL.Path = filepath.Join(t.TempDir(), "events", "events.jsonl")
+	cfg.Service.Analytics.JSONL.MaxSize = maxSize
+	cfg.Service.Analytics.JSONL.MaxBackups = maxBackups
+
+	sink, err := NewJSONL(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	t.Cleanup(func() { sink.Close(t.Context()) })
+
+	return sink, cfg.Service.Analytics.JSONL.Path
+}
+
+func newEvent(userID string) *observability.ExecutionEvent {
+	return &observability.ExecutionEvent{
+		Timestamp:   time.Now(),
+		ExecutionID: uuid.New(),
+		UserID:      userID,
+		Language:    "go",
+		Status:      "ok",
+	}
+}
+
+// readUsers returns the users of the events in the file, nil if there is no file
+func readUsers(t *testing.T, path string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	users := []string{}
+	for line := range bytes.Lines(content) {
+		var event eventModel
+		if err := json.Unmarshal(line, &event); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		users = append(users, event.UserID)
+	}
+
+	return users
+}
+
+func lineSize(t *testing.T) int64 {
+	t.Helper()
+
+	line, err := json.Marshal(eventModel{
+		Timestamp:   newEvent("u").Timestamp,
+		ExecutionID: uuid.NewString(),
+		UserID:      "u",
+		Language:    "go",
+		Status:      "ok",
+	})
+	if err != nil {
+		t.Fatalf("failed to encode event: %v", err)
+	}
+
+	return int64(len(line)) + 1
+}
+
+func TestJSONLRotate(t *testing.T) {
+	testCases := []struct {
+		Name string
+		// Events per file before it's rotated
+		PerFile    int
+		MaxBackups int
+		Written    int
+		// Events in the file n' in the backups, from path.1
+		WantFile    int
+		WantBackups []int
+	}{
+		{Name: "no_rotation", PerFile: 3, MaxBackups: 2, Written: 3, WantFile: 3, WantBackups: []int{0, 0}},
+		{Name: "one_rotation", PerFile: 3, MaxBackups: 2, Written: 4, WantFile: 1, WantBackups: []int{3, 0}},
+		{Name: "shifted", PerFile: 2, MaxBackups: 2, Written: 5, WantFile: 1, WantBackups: []int{2, 2}},
+		// The oldest backup is dropped
+		{Name: "over_backups", PerFile: 2, MaxBackups: 2, Written: 7, WantFile: 1, WantBackups: []int{2, 2, 0}},
+		// Without backups the file starts over
+		{Name: "no_backups", PerFile: 2, MaxBackups: 0, Written: 5, WantFile: 1, WantBackups: []int{0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			// The lines differ by a few bytes of the timestamp
+			size := lineSize(t)
+			sink, path := newJSONL(t, size*int64(tc.PerFile)+size/2, tc.MaxBackups)
+
+			for range tc.Written {
+				sink.Write(newEvent("u"))
+			}
+
+			if got := len(readUsers(t, path)); got != tc.WantFile {
+				t.Errorf("got %d events in file, want %d", got, tc.WantFile)
+			}
+			for i, want := range tc.WantBackups {
+				if got := len(readUsers(t, backupName(path, i+1))); got != want {
+					t.Errorf("got %d events in backup %d, want %d", got, i+1, want)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONLReopen(t *testing.T) {
+	sink, path := newJSONL(t, 1<<20, 1)
+	sink.Write(newEvent("u"))
+	if err := sink.Close(t.Context()); err != nil {
+		t.Fatalf("failed to close sink: %v", err)
+	}
+
+	// The events of the last run are kept
+	reopened, err := NewJSONL(sink.cfg, sink.log)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	reopened.Write(newEvent("u"))
+	if err := reopened.Close(t.Context()); err != nil {
+		t.Fatalf("failed to close sink: %v", err)
+	}
+
+	// Nothing is written after close
+	reopened.Write(newEvent("u"))
+
+	if got := len(readUsers(t, path)); got != 2 {
+		t.Errorf("got %d events, want 2", got)
+	}
+}
+
+func TestJSONLEraseUser(t *testing.T) {
+	size := lineSize(t)
+	// Two events per file
+	sink, path := newJSONL(t, size*2+size/2, 2)
+
+	for _, user := range []string{"erased", "other", "erased", "other", "erased"} {
+		sink.Write(newEvent(user))
+	}
+	// A broken line can't be attributed, it's kept
+	if _, err := sink.file.WriteString("broken\n"); err != nil {
+		t.Fatalf("failed to write line: %v", err)
+	}
+
+	if err := sink.EraseUser(t.Context(), "erased"); err != nil {
+		t.Fatalf("failed to erase user: %v", err)
+	}
+
+	for _, name := range []string{path, backupName(path, 1), backupName(path, 2)} {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		if bytes.Contains(content, []byte(`"user_id":"erased"`)) {
+			t.Errorf("events of the erased user are left in %s", name)
+		}
+	}
+	if content, _ := os.ReadFile(path); !bytes.Contains(content, []byte("broken\n")) {
+		t.Errorf("broken line is erased")
+	}
+
+	// The late event of the erased user is dropped,
+	// the writes go on into the new file
+	late := newEvent("erased")
+	late.Timestamp = time.Now().Add(-time.Second)
+	sink.Write(late)
+	sink.Write(newEvent("other"))
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if bytes.Contains(content, []byte(`"user_id":"erased"`)) {
+		t.Errorf("late event of the erased user is written")
+	}
+	if got := bytes.Count(content, []byte(`"user_id":"other"`)); got != 1 {
+		t.Errorf("got %d events of the other user in file, want 1", got)
+	}
+
+	// The user may come back n' run code again
+	sink.Write(newEvent("erased"))
+	if got := readUsersLoose(t, path); got["erased"] != 1 {
+		t.Errorf("got %d new events of the erased user, want 1", got["erased"])
+	}
+}
+
+func TestJSONLEraseClosed(t *testing.T) {
+	sink, path := newJSONL(t, 1<<20, 0)
+	sink.Write(newEvent("erased"))
+	if err := sink.Close(t.Context()); err != nil {
+		t.Fatalf("failed to close sink: %v", err)
+	}
+
+	if err := sink.EraseUser(t.Context(), "erased"); err != nil {
+		t.Fatalf("failed to erase user: %v", err)
+	}
+	if got := readUsers(t, path); len(got) != 0 {
+		t.Errorf("got %v, want no events", got)
+	}
+}
+
+// readUsersLoose counts the events by user, skipping the broken lines
+func readUsersLoose(t *testing.T, path string) map[string]int {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	users := make(map[string]int)
+	for line := range bytes.Lines(content) {
+		var event eventModel
+		if err := json.Unmarshal(line, &event); err == nil {
+			users[event.UserID]++
+		}
+	}
+
+	return users
+}
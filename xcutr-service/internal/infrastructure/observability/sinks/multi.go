@@ -0,0 +1,59 @@
+package sinks
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/devathh/coderun/xcutr-service/internal/domain/observability"
+)
+
+// Multi writes every event to all of its sinks
+type Multi struct {
+	sinks  []observability.ExecutionEventSink
+	erased *observability.ErasedUsers
+}
+
+func NewMulti(sinks ...observability.ExecutionEventSink) *Multi {
+	return &Multi{
+		sinks:  sinks,
+		erased: observability.NewErasedUsers(),
+	}
+}
+
+func (m *Multi) Write(event *observability.ExecutionEvent) {
+	if m.erased.Erased(event.UserID, event.Timestamp) {
+		return
+	}
+
+	for _, sink := range m.sinks {
+		sink.Write(event)
+	}
+}
+
+func (m *Multi) EraseUser(ctx context.Context, userID string) error {
+	m.erased.Erase(userID)
+
+	errs := make([]error, 0, len(m.sinks))
+	for _, sink := range m.sinks {
+		errs = append(errs, sink.EraseUser(ctx, userID))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close flushes the sinks at once, so a slow one doesn't eat
+// the time of the others
+func (m *Multi) Close(ctx context.Context) error {
+	errs := make([]error, len(m.sinks))
+
+	var wg sync.WaitGroup
+	for i, sink := range m.sinks {
+		wg.Go(func() {
+			errs[i] = sink.Close(ctx)
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,20 @@
+package sinks
+
+import (
+	"context"
+
+	"github.com/devathh/coderun/xcutr-service/internal/domain/observability"
+)
+
+// None drops the events, it's used when no sink is configured
+type None struct{}
+
+func (None) Write(*observability.ExecutionEvent) {}
+
+func (None) EraseUser(context.Context, string) error {
+	return nil
+}
+
+func (None) Close(context.Context) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package sinks
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/devathh/coderun/xcutr-service/internal/domain/observability"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/config"
+	"github.com/devathh/coderun/xcutr-service/internal/infrastructure/observability/clickhouse"
+)
+
+// New builds the sinks from the config. chClient may be nil
+// if clickhouse isn't among them
+func New(cfg *config.Config, log *slog.Logger, chClient observability.ClickhouseClient) (observability.ExecutionEventSink, error) {
+	sinks := make([]observability.ExecutionEventSink, 0, len(cfg.Service.Analytics.Sinks))
+	for _, name := range cfg.Service.Analytics.Sinks {
+		var sink observability.ExecutionEventSink
+		var err error
+
+		switch name {
+		case config.SinkClickhouse:
+			sink, err = clickhouse.NewEventSink(cfg, log, chClient)
+		case config.SinkJSONL:
+			sink, err = NewJSONL(cfg, log)
+		default:
+			err = fmt.Errorf("unknown sink: %s", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s sink: %w", name, err)
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	switch len(sinks) {
+	case 0:
+		return None{}, nil
+	case 1:
+		return sinks[0], nil
+	}
+
+	return NewMulti(sinks...), nil
+}
@@ -0,0 +1,81 @@
+package executionch
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		StartedAt time.Time
+		ID        uuid.UUID
+	}{
+		{Name: "base", StartedAt: time.Date(2026, 1, 2, 3, 4, 5, 6_000_000, time.UTC), ID: uuid.New()},
+		{Name: "epoch", StartedAt: time.UnixMilli(0), ID: uuid.New()},
+		{Name: "nil_id", StartedAt: time.UnixMilli(1), ID: uuid.Nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			startedAt, id, err := decodeCursor(encodeCursor(tc.StartedAt, tc.ID))
+			if err != nil {
+				t.Fatalf("failed to decode cursor: %v", err)
+			}
+			if !startedAt.Equal(tc.StartedAt) {
+				t.Errorf("got %v, want %v", startedAt, tc.StartedAt)
+			}
+			if id != tc.ID {
+				t.Errorf("got %v, want %v", id, tc.ID)
+			}
+		})
+	}
+}
+
+func TestCursorMillis(t *testing.T) {
+	// The cursor keeps milliseconds, like the column
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 6_789_123, time.UTC)
+
+	got, _, err := decodeCursor(encodeCursor(startedAt, uuid.New()))
+	if err != nil {
+		t.Fatalf("failed to decode cursor: %v", err)
+	}
+	if want := startedAt.Truncate(time.Millisecond); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	testCases := []struct {
+		Name   string
+		Cursor string
+	}{
+		{Name: "not_base64", Cursor: "***"},
+		{Name: "padded", Cursor: base64.URLEncoding.EncodeToString([]byte("1:" + uuid.NewString()))},
+		{Name: "no_separator", Cursor: encode("1" + uuid.NewString())},
+		{Name: "invalid_time", Cursor: encode("now:" + uuid.NewString())},
+		{Name: "invalid_id", Cursor: encode("1:not-a-uuid")},
+		{Name: "empty_parts", Cursor: encode(":")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, _, err := decodeCursor(tc.Cursor); !errors.Is(err, customerrors.ErrInvalidCursor) {
+				t.Errorf("got %v, want %v", err, customerrors.ErrInvalidCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package executionch
+
+import (
+	"time"
+
+	xcutrexecution "github.com/devathh/coderun/xcutr-service/internal/domain/execution"
+	"github.com/google/uuid"
+)
+
+func toModel(record *xcutrexecution.Record) ExecutionModel {
+	files := record.Files()
+	model := ExecutionModel{
+		ID:              record.ID(),
+		UserID:          record.UserID().String(),
+		Language:        record.Language(),
+		Status:          string(record.Status()),
+		ExitCode:        int32(record.ExitCode()),
+		StartedAt:       record.StartedAt(),
+		DurationMS:      uint64(record.Duration().Milliseconds()),
+		FileNames:       make([]string, 0, len(files)),
+		FileMimes:       make([]string, 0, len(files)),
+		FileHashes:      make([]string, 0, len(files)),
+		FileBodies:      make([]string, 0, len(files)),
+		SourceTruncated: record.SourceTruncated(),
+		Output:          record.Output(),
+		OutputTruncated: record.OutputTruncated(),
+	}
+
+	for _, file := range files {
+		model.FileNames = append(model.FileNames, file.Name)
+		model.FileMimes = append(model.FileMimes, file.Mime)
+		model.FileHashes = append(model.FileHashes, file.SHA256)
+		model.FileBodies = append(model.FileBodies, string(file.Body))
+	}
+
+	return model
+}
+
+func toDomain(model *ExecutionModel) (*xcutrexecution.Record, error) {
+	userID, err := uuid.Parse(model.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := xcutrexecution.NewStatus(model.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]xcutrexecution.SourceFile, 0, len(model.FileNames))
+	for i := range model.FileNames {
+		file := xcutrexecution.SourceFile{
+			Name: model.FileNames[i],
+		}
+		if i < len(model.FileMimes) {
+			file.Mime = model.FileMimes[i]
+		}
+		if i < len(model.FileHashes) {
+			file.SHA256 = model.FileHashes[i]
+		}
+		if i < len(model.FileBodies) && model.FileBodies[i] != "" {
+			file.Body = []byte(model.FileBodies[i])
+		}
+		files = append(files, file)
+	}
+
+	return xcutrexecution.NewRecord(
+		model.ID,
+		userID,
+		model.Language,
+		files,
+		model.SourceTruncated,
+		status,
+		int(model.ExitCode),
+		model.StartedAt,
+		time.Duration(model.DurationMS)*time.Millisecond,
+		model.Output,
+		model.OutputTruncated,
+	), nil
+}
@@ -0,0 +1,24 @@
+package executionch
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ExecutionModel struct {
+	ID              uuid.UUID `ch:"id"`
+	UserID          string    `ch:"user_id"`
+	Language        string    `ch:"language"`
+	Status          string    `ch:"status"`
+	ExitCode        int32     `ch:"exit_code"`
+	StartedAt       time.Time `ch:"started_at"`
+	DurationMS      uint64    `ch:"duration_ms"`
+	FileNames       []string  `ch:"file_names"`
+	FileMimes       []string  `ch:"file_mimes"`
+	FileHashes      []string  `ch:"file_hashes"`
+	FileBodies      []string  `ch:"file_bodies"`
+	SourceTruncated bool      `ch:"source_truncated"`
+	Output          string    `ch:"output"`
+	OutputTruncated bool      `ch:"output_truncated"`
+}
@@ -0,0 +1,59 @@
+package executionch
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func splitColumns(columns string) []string {
+	var result []string
+	for column := range strings.SplitSeq(columns, ",") {
+		result = append(result, strings.TrimSpace(column))
+	}
+
+	return result
+}
+
+func TestColumns(t *testing.T) {
+	var tags []string
+	model := reflect.TypeFor[ExecutionModel]()
+	for i := range model.NumField() {
+		tags = append(tags, model.Field(i).Tag.Get("ch"))
+	}
+
+	testCases := []struct {
+		Name    string
+		Columns string
+		// The columns a page of the history must not pull
+		WantWithout []string
+	}{
+		{Name: "columns", Columns: columns},
+		{Name: "summary", Columns: summaryColumns, WantWithout: []string{"file_bodies", "output"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			got := splitColumns(tc.Columns)
+			// Every selected column is scanned into the model
+			for _, column := range got {
+				if !slices.Contains(tags, column) {
+					t.Errorf("column %s isn't in the model", column)
+				}
+			}
+			for _, column := range tc.WantWithout {
+				if slices.Contains(got, column) {
+					t.Errorf("got column %s, want it left out", column)
+				}
+			}
+		})
+	}
+
+	// The full columns are inserted, so they are all of the model
+	if got := splitColumns(columns); !slices.Equal(got, tags) {
+		t.Errorf("got %v, want %v", got, tags)
+	}
+}
@@ -0,0 +1,190 @@
+package executionch
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/devathh/coderun/shared/tracing"
+	xcutrexecution "github.com/devathh/coderun/xcutr-service/internal/domain/execution"
+	customerrors "github.com/devathh/coderun/xcutr-service/pkg/errors"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/devathh/coderun/xcutr-service/internal/infrastructure/persistence/clickhouse/execution")
+
+const columns = `id, user_id, language, status, exit_code, started_at, duration_ms,
+	file_names, file_mimes, file_hashes, file_bodies, source_truncated,
+	output, output_truncated`
+
+// summaryColumns are the columns without the sources n' the output,
+// a page of the history doesn't need them
+const summaryColumns = `id, user_id, language, status, exit_code, started_at, duration_ms,
+	file_names, file_mimes, file_hashes, source_truncated, output_truncated`
+
+type ExecutionRepository struct {
+	conn driver.Conn
+}
+
+func New(conn driver.Conn) (*ExecutionRepository, error) {
+	if conn == nil {
+		return nil, customerrors.ErrNilArgs
+	}
+
+	return &ExecutionRepository{
+		conn: conn,
+	}, nil
+}
+
+func (er *ExecutionRepository) Save(ctx context.Context, record *xcutrexecution.Record) (err error) {
+	ctx, span := tracer.Start(ctx, "executionch.Save")
+	defer func() { tracing.End(span, err) }()
+
+	if record == nil {
+		return customerrors.ErrNilArgs
+	}
+
+	batch, err := er.conn.PrepareBatch(ctx, "INSERT INTO executions ("+columns+")")
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+	defer batch.Abort()
+
+	model := toModel(record)
+	if err := batch.AppendStruct(&model); err != nil {
+		return fmt.Errorf("failed to append execution: %w", err)
+	}
+
+	if err := batch.Send(); err != nil {
+		return fmt.Errorf("failed to save execution: %w", err)
+	}
+
+	return nil
+}
+
+func (er *ExecutionRepository) List(ctx context.Context, userID uuid.UUID, filter xcutrexecution.Filter, cursor string, limit int) ([]*xcutrexecution.Record, string, error) {
+	if limit <= 0 {
+		return nil, "", customerrors.ErrNilArgs
+	}
+
+	query := strings.Builder{}
+	query.WriteString("SELECT " + summaryColumns + " FROM executions WHERE user_id = ?")
+	args := []any{userID.String()}
+
+	if filter.Language != "" {
+		query.WriteString(" AND language = ?")
+		args = append(args, filter.Language)
+	}
+	if filter.Status != "" {
+		query.WriteString(" AND status = ?")
+		args = append(args, string(filter.Status))
+	}
+
+	if cursor != "" {
+		startedAt, id, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+
+		query.WriteString(" AND (started_at, id) < (fromUnixTimestamp64Milli(?), toUUID(?))")
+		args = append(args, startedAt.UnixMilli(), id.String())
+	}
+
+	// One more row tells if there is the next page
+	query.WriteString(" ORDER BY started_at DESC, id DESC LIMIT ?")
+	args = append(args, limit+1)
+
+	var models []ExecutionModel
+	if err := er.conn.Select(ctx, &models, query.String(), args...); err != nil {
+		return nil, "", fmt.Errorf("failed to list executions: %w", err)
+	}
+
+	var next string
+	if len(models) > limit {
+		models = models[:limit]
+		last := models[limit-1]
+		next = encodeCursor(last.StartedAt, last.ID)
+	}
+
+	records := make([]*xcutrexecution.Record, 0, len(models))
+	for i := range models {
+		record, err := toDomain(&models[i])
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to map execution: %w", err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, next, nil
+}
+
+func (er *ExecutionRepository) Get(ctx context.Context, userID, id uuid.UUID) (*xcutrexecution.Record, error) {
+	var models []ExecutionModel
+	if err := er.conn.Select(ctx, &models,
+		"SELECT "+columns+" FROM executions WHERE user_id = ? AND id = ? LIMIT 1",
+		userID.String(), id,
+	); err != nil {
+		return nil, fmt.Errorf("failed to get execution: %w", err)
+	}
+
+	if len(models) == 0 {
+		return nil, customerrors.ErrNotFoundExec
+	}
+
+	record, err := toDomain(&models[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map execution: %w", err)
+	}
+
+	return record, nil
+}
+
+// DeleteUser removes the records of the user n' waits until they are gone
+func (er *ExecutionRepository) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	ctx = clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{
+		"mutations_sync": 1,
+	}))
+
+	if err := er.conn.Exec(ctx, "ALTER TABLE executions DELETE WHERE user_id = ?", userID.String()); err != nil {
+		return fmt.Errorf("failed to delete executions of user: %w", err)
+	}
+
+	return nil
+}
+
+// The cursor is the position of the last record on the page
+func encodeCursor(startedAt time.Time, id uuid.UUID) string {
+	raw := strconv.FormatInt(startedAt.UnixMilli(), 10) + ":" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, customerrors.ErrInvalidCursor
+	}
+
+	rawTime, rawID, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, uuid.Nil, customerrors.ErrInvalidCursor
+	}
+
+	millis, err := strconv.ParseInt(rawTime, 10, 64)
+	if err != nil {
+		return time.Time{}, uuid.Nil, customerrors.ErrInvalidCursor
+	}
+
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return time.Time{}, uuid.Nil, customerrors.ErrInvalidCursor
+	}
+
+	return time.UnixMilli(millis), id, nil
+}
@@ -8,6 +8,13 @@ var (
 	ErrInvalidFilename = errors.New("invalid filename")
 	ErrEmptyFile       = errors.New("can't create an empty file")
 	ErrTooLargeFile    = errors.New("file is too large")
+	ErrInvalidEnv      = errors.New("invalid environment variable")
+	ErrReservedEnv     = errors.New("environment variable is reserved")
+	ErrTooManyEnv      = errors.New("too many environment variables")
+	ErrTooLargeEnv     = errors.New("environment is too large")
+	ErrInvalidArgs     = errors.New("invalid arguments")
+	ErrTooManyArgs     = errors.New("too many arguments")
+	ErrTooLargeArgs    = errors.New("arguments are too large")
 
 	// general's
 	ErrNilArgs      = errors.New("some args are nil")
@@ -15,10 +22,27 @@ var (
 
 	// repository
 	ErrNotFoundContainer = errors.New("container not found")
+	ErrNoDockerHosts     = errors.New("no healthy docker hosts")
+	ErrEditedMigration   = errors.New("applied migration is edited")
+	ErrMissingMigration  = errors.New("applied migration is missing")
 
 	// service's
 	ErrTooLargeTimeout = errors.New("timeout is too large")
 	ErrNoMain          = errors.New("main file doesn't exist")
 	ErrInvalidLang     = errors.New("this language doesn't exist")
 	ErrInternalServer  = errors.New("internal server error")
+	ErrShuttingDown    = errors.New("executor is shutting down")
+	ErrCanceled        = errors.New("execution is canceled")
+	ErrClientGone      = errors.New("client closed the stream")
+	ErrNotFoundExec    = errors.New("execution not found")
+	ErrNotOwner        = errors.New("execution belongs to another user")
+	ErrInvalidExecID   = errors.New("invalid execution id")
+	ErrTooManyViewers  = errors.New("too many viewers of the execution")
+	ErrInvalidStatus   = errors.New("invalid status of execution")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrHistoryDisabled = errors.New("history of executions is disabled")
+	ErrNotAdmin        = errors.New("method is allowed to admins only")
+	ErrInvalidPeriod   = errors.New("invalid period of stats")
+	ErrStatsDisabled   = errors.New("usage stats are disabled")
+	ErrInvalidUserID   = errors.New("invalid user id")
 )